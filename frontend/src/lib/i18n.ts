@@ -17,6 +17,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Input Mode",
     hold: "Hold",
     toggle: "Toggle",
+    tap: "Tap",
     hotkey: "Hotkey",
     language: "Language",
     langByKBLayout: "Language follows keyboard layout",
@@ -51,7 +52,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Whisper model for speech recognition. Larger models are more accurate but slower",
     tip_openModels: "Download or remove Whisper models",
     tip_keepModelLoaded: "Keep the model in memory between recordings for faster response. Uses more RAM",
-    tip_inputMode: "Hold: record while key is held. Toggle: press to start, press again to stop",
+    tip_inputMode: "Hold: record while key is held. Toggle: press to start, press again to stop. Tap: press to start, stops by itself after a pause in speech",
     tip_hotkey: "Global keyboard shortcut to start/stop recording with this preset",
     tip_langByKBLayout: "Automatically set transcription language based on your current keyboard layout. Switch layout to switch language",
     tip_language: "Language for speech recognition. Ignored when keyboard layout detection is on",
@@ -105,6 +106,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDA backend: not downloaded",
     cuda_step_1: "Step 1/2: CUDA Runtime",
     cuda_step_2: "Step 2/2: CUDA backend DLL",
+    install_stage_downloading_runtime: "Downloading runtime",
+    install_stage_installing_runtime: "Installing runtime",
+    install_stage_downloading: "Downloading GPU backend",
+    install_stage_done: "Installed",
+    install_stage_failed: "Install failed",
+    install_stage_cancelled: "Install cancelled",
     cuda_uac_warning: "Admin rights required — a UAC prompt will appear",
     vulkan_runtime_installed: "Vulkan: available",
     vulkan_dll_ready: "Vulkan backend: ready",
@@ -229,6 +236,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Режим ввода",
     hold: "Удержание",
     toggle: "Переключение",
+    tap: "Касание",
     hotkey: "Горячая клавиша",
     language: "Язык",
     langByKBLayout: "Язык по раскладке клавиатуры",
@@ -263,7 +271,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Модель Whisper для распознавания речи. Чем больше модель — тем точнее, но медленнее",
     tip_openModels: "Скачать или удалить модели Whisper",
     tip_keepModelLoaded: "Держать модель в памяти между записями для быстрого отклика. Расходует больше RAM",
-    tip_inputMode: "Удержание: запись пока клавиша нажата. Переключение: нажать — начать, нажать снова — остановить",
+    tip_inputMode: "Удержание: запись пока клавиша нажата. Переключение: нажать — начать, нажать снова — остановить. Касание: нажать — начать, запись остановится сама после паузы в речи",
     tip_hotkey: "Глобальная горячая клавиша для начала/остановки записи этим пресетом",
     tip_langByKBLayout: "Автоматически определять язык транскрипции по текущей раскладке клавиатуры. Переключили раскладку — переключился язык",
     tip_language: "Язык распознавания речи. Игнорируется, если включено определение по раскладке",
@@ -316,6 +324,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDA бэкенд: не загружен",
     cuda_step_1: "Шаг 1/2: CUDA Runtime",
     cuda_step_2: "Шаг 2/2: CUDA бэкенд DLL",
+    install_stage_downloading_runtime: "Скачивание runtime",
+    install_stage_installing_runtime: "Установка runtime",
+    install_stage_downloading: "Скачивание GPU бэкенда",
+    install_stage_done: "Установлено",
+    install_stage_failed: "Ошибка установки",
+    install_stage_cancelled: "Установка отменена",
     cuda_uac_warning: "Потребуются права администратора — появится запрос UAC",
     vulkan_runtime_installed: "Vulkan: доступен",
     vulkan_dll_ready: "Vulkan бэкенд: готов",
@@ -440,6 +454,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Eingabemodus",
     hold: "Halten",
     toggle: "Umschalten",
+    tap: "Tippen",
     hotkey: "Tastenkürzel",
     language: "Sprache",
     langByKBLayout: "Sprache folgt Tastaturbelegung",
@@ -473,7 +488,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Whisper-Modell für die Spracherkennung. Größere Modelle sind genauer, aber langsamer",
     tip_openModels: "Whisper-Modelle herunterladen oder entfernen",
     tip_keepModelLoaded: "Modell zwischen Aufnahmen im Speicher halten. Verbraucht mehr RAM",
-    tip_inputMode: "Halten: Aufnahme solange Taste gedrückt. Umschalten: drücken zum Starten, nochmal drücken zum Stoppen",
+    tip_inputMode: "Halten: Aufnahme solange Taste gedrückt. Umschalten: drücken zum Starten, nochmal drücken zum Stoppen. Tippen: drücken zum Starten, stoppt nach einer Sprechpause von selbst",
     tip_hotkey: "Globales Tastenkürzel zum Starten/Stoppen der Aufnahme",
     tip_langByKBLayout: "Transkriptionssprache automatisch anhand der Tastaturbelegung bestimmen",
     tip_language: "Sprache für die Spracherkennung. Wird ignoriert wenn Tastaturbelegungserkennung aktiv ist",
@@ -526,6 +541,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDA-Backend: nicht heruntergeladen",
     cuda_step_1: "Schritt 1/2: CUDA Runtime",
     cuda_step_2: "Schritt 2/2: CUDA-Backend-DLL",
+    install_stage_downloading_runtime: "Runtime wird heruntergeladen",
+    install_stage_installing_runtime: "Runtime wird installiert",
+    install_stage_downloading: "GPU-Backend wird heruntergeladen",
+    install_stage_done: "Installiert",
+    install_stage_failed: "Installation fehlgeschlagen",
+    install_stage_cancelled: "Installation abgebrochen",
     cuda_uac_warning: "Adminrechte erforderlich — UAC-Abfrage erscheint",
     vulkan_runtime_installed: "Vulkan: verfügbar",
     vulkan_dll_ready: "Vulkan-Backend: bereit",
@@ -645,6 +666,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Modo de entrada",
     hold: "Mantener",
     toggle: "Alternar",
+    tap: "Toque",
     hotkey: "Atajo de teclado",
     language: "Idioma",
     langByKBLayout: "Idioma según distribución del teclado",
@@ -678,7 +700,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Modelo Whisper para reconocimiento de voz. Los modelos grandes son más precisos pero más lentos",
     tip_openModels: "Descargar o eliminar modelos Whisper",
     tip_keepModelLoaded: "Mantener el modelo en memoria entre grabaciones. Usa más RAM",
-    tip_inputMode: "Mantener: graba mientras la tecla está pulsada. Alternar: pulsar para iniciar, pulsar de nuevo para detener",
+    tip_inputMode: "Mantener: graba mientras la tecla está pulsada. Alternar: pulsar para iniciar, pulsar de nuevo para detener. Toque: pulsar para iniciar, se detiene sola tras una pausa al hablar",
     tip_hotkey: "Atajo global para iniciar/detener la grabación",
     tip_langByKBLayout: "Determinar automáticamente el idioma de transcripción según la distribución del teclado",
     tip_language: "Idioma para el reconocimiento de voz. Se ignora si la detección por teclado está activada",
@@ -731,6 +753,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "Backend CUDA: no descargado",
     cuda_step_1: "Paso 1/2: CUDA Runtime",
     cuda_step_2: "Paso 2/2: DLL del backend CUDA",
+    install_stage_downloading_runtime: "Descargando runtime",
+    install_stage_installing_runtime: "Instalando runtime",
+    install_stage_downloading: "Descargando backend GPU",
+    install_stage_done: "Instalado",
+    install_stage_failed: "Error de instalación",
+    install_stage_cancelled: "Instalación cancelada",
     cuda_uac_warning: "Se requieren derechos de administrador — aparecerá un aviso de UAC",
     vulkan_runtime_installed: "Vulkan: disponible",
     vulkan_dll_ready: "Backend Vulkan: listo",
@@ -850,6 +878,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Mode de saisie",
     hold: "Maintenir",
     toggle: "Basculer",
+    tap: "Appui",
     hotkey: "Raccourci clavier",
     language: "Langue",
     langByKBLayout: "Langue selon la disposition du clavier",
@@ -883,7 +912,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Modèle Whisper pour la reconnaissance vocale. Les grands modèles sont plus précis mais plus lents",
     tip_openModels: "Télécharger ou supprimer des modèles Whisper",
     tip_keepModelLoaded: "Garder le modèle en mémoire entre les enregistrements. Utilise plus de RAM",
-    tip_inputMode: "Maintenir : enregistre tant que la touche est enfoncée. Basculer : appuyer pour démarrer, appuyer à nouveau pour arrêter",
+    tip_inputMode: "Maintenir : enregistre tant que la touche est enfoncée. Basculer : appuyer pour démarrer, appuyer à nouveau pour arrêter. Appui : appuyer pour démarrer, s'arrête seul après une pause dans la parole",
     tip_hotkey: "Raccourci clavier global pour démarrer/arrêter l'enregistrement",
     tip_langByKBLayout: "Déterminer automatiquement la langue de transcription selon la disposition du clavier",
     tip_language: "Langue pour la reconnaissance vocale. Ignorée si la détection par clavier est activée",
@@ -936,6 +965,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "Backend CUDA : non téléchargé",
     cuda_step_1: "Étape 1/2 : CUDA Runtime",
     cuda_step_2: "Étape 2/2 : DLL backend CUDA",
+    install_stage_downloading_runtime: "Téléchargement du runtime",
+    install_stage_installing_runtime: "Installation du runtime",
+    install_stage_downloading: "Téléchargement du backend GPU",
+    install_stage_done: "Installé",
+    install_stage_failed: "Échec de l'installation",
+    install_stage_cancelled: "Installation annulée",
     cuda_uac_warning: "Droits administrateur requis — une invite UAC apparaîtra",
     vulkan_runtime_installed: "Vulkan : disponible",
     vulkan_dll_ready: "Backend Vulkan : prêt",
@@ -1055,6 +1090,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "输入模式",
     hold: "按住",
     toggle: "切换",
+    tap: "轻按",
     hotkey: "快捷键",
     language: "语言",
     langByKBLayout: "语言跟随键盘布局",
@@ -1088,7 +1124,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "用于语音识别的Whisper模型。较大的模型更准确但更慢",
     tip_openModels: "下载或删除Whisper模型",
     tip_keepModelLoaded: "在录音之间将模型保留在内存中以加快响应。使用更多内存",
-    tip_inputMode: "按住：按住键时录音。切换：按一次开始，再按一次停止",
+    tip_inputMode: "按住：按住键时录音。切换：按一次开始，再按一次停止。轻按：按一次开始，说话停顿后自动停止",
     tip_hotkey: "开始/停止录音的全局快捷键",
     tip_langByKBLayout: "根据当前键盘布局自动设置转录语言。切换布局即切换语言",
     tip_language: "语音识别语言。启用键盘布局检测时将被忽略",
@@ -1141,6 +1177,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDA 后端：未下载",
     cuda_step_1: "步骤 1/2：CUDA Runtime",
     cuda_step_2: "步骤 2/2：CUDA 后端 DLL",
+    install_stage_downloading_runtime: "正在下载运行时",
+    install_stage_installing_runtime: "正在安装运行时",
+    install_stage_downloading: "正在下载 GPU 后端",
+    install_stage_done: "已安装",
+    install_stage_failed: "安装失败",
+    install_stage_cancelled: "安装已取消",
     cuda_uac_warning: "需要管理员权限 — 将出现 UAC 提示",
     vulkan_runtime_installed: "Vulkan：可用",
     vulkan_dll_ready: "Vulkan 后端：已就绪",
@@ -1260,6 +1302,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "入力モード",
     hold: "長押し",
     toggle: "切り替え",
+    tap: "タップ",
     hotkey: "ホットキー",
     language: "言語",
     langByKBLayout: "キーボード配列に連動して言語を設定",
@@ -1293,7 +1336,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "音声認識に使用するWhisperモデル。大きいモデルはより正確ですが遅くなります",
     tip_openModels: "Whisperモデルのダウンロードまたは削除",
     tip_keepModelLoaded: "録音間でモデルをメモリに保持。より多くのRAMを使用します",
-    tip_inputMode: "長押し：キーを押している間録音。切り替え：押して開始、もう一度押して停止",
+    tip_inputMode: "長押し：キーを押している間録音。切り替え：押して開始、もう一度押して停止。タップ：押して開始、話が途切れると自動で停止",
     tip_hotkey: "録音の開始/停止用グローバルキーボードショートカット",
     tip_langByKBLayout: "現在のキーボード配列に基づいて文字起こし言語を自動設定",
     tip_language: "音声認識の言語。キーボード配列検出が有効な場合は無視されます",
@@ -1346,6 +1389,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDAバックエンド：未ダウンロード",
     cuda_step_1: "ステップ 1/2：CUDA Runtime",
     cuda_step_2: "ステップ 2/2：CUDAバックエンドDLL",
+    install_stage_downloading_runtime: "ランタイムをダウンロード中",
+    install_stage_installing_runtime: "ランタイムをインストール中",
+    install_stage_downloading: "GPUバックエンドをダウンロード中",
+    install_stage_done: "インストール完了",
+    install_stage_failed: "インストール失敗",
+    install_stage_cancelled: "インストールをキャンセルしました",
     cuda_uac_warning: "管理者権限が必要です — UACプロンプトが表示されます",
     vulkan_runtime_installed: "Vulkan：利用可能",
     vulkan_dll_ready: "Vulkanバックエンド：準備完了",
@@ -1465,6 +1514,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "Modo de entrada",
     hold: "Manter pressionado",
     toggle: "Alternar",
+    tap: "Toque",
     hotkey: "Atalho de teclado",
     language: "Idioma",
     langByKBLayout: "Idioma segue o layout do teclado",
@@ -1498,7 +1548,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "Modelo Whisper para reconhecimento de voz. Modelos maiores são mais precisos mas mais lentos",
     tip_openModels: "Baixar ou remover modelos Whisper",
     tip_keepModelLoaded: "Manter o modelo na memória entre gravações. Usa mais RAM",
-    tip_inputMode: "Manter: grava enquanto a tecla está pressionada. Alternar: pressione para iniciar, pressione novamente para parar",
+    tip_inputMode: "Manter: grava enquanto a tecla está pressionada. Alternar: pressione para iniciar, pressione novamente para parar. Toque: pressione para iniciar, para sozinho após uma pausa na fala",
     tip_hotkey: "Atalho de teclado global para iniciar/parar a gravação",
     tip_langByKBLayout: "Determinar automaticamente o idioma de transcrição com base no layout do teclado",
     tip_language: "Idioma para reconhecimento de voz. Ignorado quando a detecção por teclado está ativa",
@@ -1551,6 +1601,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "Backend CUDA: não baixado",
     cuda_step_1: "Passo 1/2: CUDA Runtime",
     cuda_step_2: "Passo 2/2: DLL do backend CUDA",
+    install_stage_downloading_runtime: "Baixando runtime",
+    install_stage_installing_runtime: "Instalando runtime",
+    install_stage_downloading: "Baixando backend GPU",
+    install_stage_done: "Instalado",
+    install_stage_failed: "Falha na instalação",
+    install_stage_cancelled: "Instalação cancelada",
     cuda_uac_warning: "Direitos de administrador necessários — um prompt UAC aparecerá",
     vulkan_runtime_installed: "Vulkan: disponível",
     vulkan_dll_ready: "Backend Vulkan: pronto",
@@ -1670,6 +1726,7 @@ const translations: Record<string, Record<string, string>> = {
     inputMode: "입력 모드",
     hold: "길게 누르기",
     toggle: "토글",
+    tap: "탭",
     hotkey: "단축키",
     language: "언어",
     langByKBLayout: "키보드 레이아웃에 따라 언어 설정",
@@ -1703,7 +1760,7 @@ const translations: Record<string, Record<string, string>> = {
     tip_model: "음성 인식에 사용할 Whisper 모델. 큰 모델은 더 정확하지만 느립니다",
     tip_openModels: "Whisper 모델 다운로드 또는 삭제",
     tip_keepModelLoaded: "녹음 사이에 모델을 메모리에 유지. 더 많은 RAM 사용",
-    tip_inputMode: "길게 누르기: 키를 누르고 있는 동안 녹음. 토글: 누르면 시작, 다시 누르면 중지",
+    tip_inputMode: "길게 누르기: 키를 누르고 있는 동안 녹음. 토글: 누르면 시작, 다시 누르면 중지. 탭: 누르면 시작, 말을 멈추면 자동으로 중지",
     tip_hotkey: "녹음 시작/중지를 위한 글로벌 키보드 단축키",
     tip_langByKBLayout: "현재 키보드 레이아웃에 따라 전사 언어를 자동 설정",
     tip_language: "음성 인식 언어. 키보드 레이아웃 감지가 활성화되면 무시됩니다",
@@ -1756,6 +1813,12 @@ const translations: Record<string, Record<string, string>> = {
     cuda_dll_missing: "CUDA 백엔드: 미다운로드",
     cuda_step_1: "1/2단계: CUDA Runtime",
     cuda_step_2: "2/2단계: CUDA 백엔드 DLL",
+    install_stage_downloading_runtime: "런타임 다운로드 중",
+    install_stage_installing_runtime: "런타임 설치 중",
+    install_stage_downloading: "GPU 백엔드 다운로드 중",
+    install_stage_done: "설치됨",
+    install_stage_failed: "설치 실패",
+    install_stage_cancelled: "설치 취소됨",
     cuda_uac_warning: "관리자 권한 필요 — UAC 프롬프트가 표시됩니다",
     vulkan_runtime_installed: "Vulkan: 사용 가능",
     vulkan_dll_ready: "Vulkan 백엔드: 준비 완료",
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -11,28 +12,117 @@ import (
 
 // Preset holds settings for a single transcription preset.
 type Preset struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	ModelName       string `json:"modelName"`
-	KeepModelLoaded bool   `json:"keepModelLoaded"`
-	InputMode       string `json:"inputMode"` // "hold" | "toggle"
-	Hotkey          string `json:"hotkey"`     // "ctrl+shift+f1"
-	Language        string `json:"language"`   // "auto", "en", "ru"...
-	UseKBLayout     bool   `json:"useKBLayout"`
-	KeepHistory     bool   `json:"keepHistory"`
-	Enabled         bool   `json:"enabled"`
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	ModelName            string   `json:"modelName"`
+	KeepModelLoaded      bool     `json:"keepModelLoaded"`
+	InputMode            string   `json:"inputMode"` // "hold" | "toggle" | "tap"
+	Hotkey               string   `json:"hotkey"`    // "ctrl+shift+f1"
+	Language             string   `json:"language"`  // "auto", "en", "ru"...
+	Translate            bool     `json:"translate"` // translate the speech to English (multilingual models only)
+	UseKBLayout          bool     `json:"useKBLayout"`
+	KeepHistory          bool     `json:"keepHistory"`
+	Enabled              bool     `json:"enabled"`
+	Primary              bool     `json:"primary"`              // the tray's quick record item uses this preset (else the first enabled one)
+	DoublePressCancel    bool     `json:"doublePressCancel"`    // hold mode: quick re-press cancels the recording
+	TapSilenceMs         int      `json:"tapSilenceMs"`         // tap mode: stop after this long without speech; 0 = default 1500
+	TapMaxSeconds        int      `json:"tapMaxSeconds"`        // tap mode: recording cap; 0 = the global maxRecordSeconds
+	AutoCapitalize       bool     `json:"autoCapitalize"`       // case the first word from text before the caret
+	Capitalize           bool     `json:"capitalize"`           // always upper-case the first letter
+	EndPunctuation       bool     `json:"endPunctuation"`       // end with "." if the text ends in a letter or digit
+	ConvertNumbers       bool     `json:"convertNumbers"`       // spoken numbers to digits ("twenty five" → "25"), English and Russian
+	PreserveLeadingSpace bool     `json:"preserveLeadingSpace"` // keep one leading space so dictations join as words
+	OutputFormat         string   `json:"outputFormat"`         // "" = plain text, "srt" = SubRip subtitles with timings
+	AppMatch             []string `json:"appMatch,omitempty"`   // window class / process globs; presets sharing a hotkey follow the focused app
+	Color                string   `json:"color,omitempty"`      // overlay accent color ("#rrggbb"); "" = default
+	RobustDecode         bool     `json:"robustDecode"`         // apply EntropyThreshold / LogProbThreshold to whisper's temperature fallback
+	RearmHold            string   `json:"rearmHold,omitempty"`  // hold mode, key still held at auto-stop: "" = stop, "join" = keep recording, one transcription, "split" = transcribe each segment
+	PrependText          string   `json:"prependText"`          // pasted before the text; \n, \t escapes
+	AppendText           string   `json:"appendText"`           // pasted after the text, e.g. " " so dictations don't run together
+	AutoSpace            bool     `json:"autoSpace"`            // prepend a space when the previous paste didn't end in whitespace
+	CommandMode          bool     `json:"commandMode"`          // spoken phrases in CommandMap run actions instead of being pasted
+	BeamSize             int      `json:"beamSize"`             // 0 = greedy; N = beam search of width N (more accurate on hard audio, slower)
+	EntropyThreshold     float32  `json:"entropyThreshold"`     // robustDecode: retry a segment whose token entropy is below this (repetition); 0 = default 2.4
+	LogProbThreshold     float32  `json:"logProbThreshold"`     // robustDecode: retry a segment whose average log probability is below this; 0 = default -1.0
+	LowLatency           bool     `json:"lowLatency"`           // fast path for quick commands: one greedy whisper pass, trimming only
+	UseContext           bool     `json:"useContext"`           // long recordings: each chunk is decoded with the previous chunk's text as context
+	NormalizeAudio       bool     `json:"normalizeAudio"`       // boost quiet recordings so their peak reaches -1 dBFS before transcription
+	NoiseGateDb          float64  `json:"noiseGateDb"`          // zero 20ms frames quieter than this (dBFS, e.g. -45) before transcription; 0 = off
+
+	// CommandMap maps spoken phrases to actions: "key:enter", "paste:text".
+	CommandMap map[string]string `json:"commandMap,omitempty"`
+	// DisableHallucinationFilter skips the hallucination filter, so short real
+	// answers like "yes", "no", "bye" are pasted (command-style dictation).
+	DisableHallucinationFilter bool `json:"disableHallucinationFilter"`
 }
 
 // AppConfig holds the global application settings and presets.
 type AppConfig struct {
-	MicrophoneID   string   `json:"microphoneId"`
-	ModelsDir      string   `json:"modelsDir"`
-	Theme          string   `json:"theme"`       // "dark" | "light"
-	UILang         string   `json:"uiLang"`      // "en" | "ru"
-	CloseAction    string   `json:"closeAction"` // "" = ask, "tray", "quit"
-	AutoStart      bool     `json:"autoStart"`
-	StartMinimized bool     `json:"startMinimized"`
-	Backend        string   `json:"backend"` // "auto", "cpu", "cuda", "vulkan", "metal", "rocm"
+	MicrophoneID   string `json:"microphoneId"`
+	ModelsDir      string `json:"modelsDir"`
+	ModelBaseURL   string `json:"modelBaseUrl"` // "" = HuggingFace; mirror serving ggml-*.bin files
+	MaxDownloads   int    `json:"maxDownloads"` // concurrent model downloads, 0 = default (2)
+	MaxPresets     int    `json:"maxPresets"`   // soft cap on preset count, 0 = default (50)
+	LogDir         string `json:"logDir"`       // custom log directory; "" = next to the exe, falling back to the OS log dir
+	Theme          string `json:"theme"`        // "dark" | "light"
+	UILang         string `json:"uiLang"`       // "en" | "ru"
+	CloseAction    string `json:"closeAction"`  // "" = ask, "tray", "quit"
+	AutoStart      bool   `json:"autoStart"`
+	StartMinimized bool   `json:"startMinimized"`
+	Backend        string `json:"backend"`       // "auto", "cpu", "cuda", "vulkan", "opencl", "metal", "rocm"
+	OutputMode     string `json:"outputMode"`    // "" = clipboard paste, "accessibility" = AX insert (macOS), "uia" = UI Automation (Windows)
+	SoundCues      bool   `json:"soundCues"`     // beep on record start/stop
+	Threads        int    `json:"threads"`       // whisper inference threads, 0 = auto (NumCPU, at most 8)
+	LinuxPasteKey  string `json:"linuxPasteKey"` // "" = "shift+insert", "ctrl+v", "ctrl+shift+v"
+	HotkeyBackend  string `json:"hotkeyBackend"` // "" = platform default, "evdev" = read /dev/input (Linux, needs the input group)
+	BusyBehavior   string `json:"busyBehavior"`  // hotkey press with the transcription queue full: "" / "block" = ignored, "queue" = record when a transcription finishes
+	PauseHotkey    string `json:"pauseHotkey"`   // toggles suspending all preset hotkeys, "" = none
+	RepeatHotkey   string `json:"repeatHotkey"`  // pastes the last transcription again, "" = none
+
+	// MaxRecordSeconds caps a single recording. nil = default (180s), 0 = unlimited.
+	MaxRecordSeconds *int `json:"maxRecordSeconds,omitempty"`
+	// MinRecordMs is the shortest recording that gets transcribed; shorter ones
+	// are dropped as accidental presses. 0 = default (500ms), minimum 100ms.
+	MinRecordMs int `json:"minRecordMs,omitempty"`
+	// HistoryLimit caps stored history entries. nil = default (50), 0 = history disabled.
+	HistoryLimit *int `json:"historyLimit,omitempty"`
+	// RestoreClipboard puts the user's clipboard back after a paste (Linux,
+	// macOS). nil = default (on); off leaves the transcription on the clipboard.
+	RestoreClipboard *bool `json:"restoreClipboard,omitempty"`
+	// StripNoiseMarkers removes every [bracketed] span from transcriptions, as
+	// whisper marks noise that way. nil = default (on); off removes only known
+	// markers ([MUSIC], (laughter)), so dictated "[TODO]" or code survives.
+	StripNoiseMarkers *bool `json:"stripNoiseMarkers,omitempty"`
+	// PostCommand is a shell command run on each transcription before it is
+	// pasted, with the text on stdin and {text} as a quoted argument. "" = none.
+	PostCommand string `json:"postCommand,omitempty"`
+	// PostCommandReplacesText pastes PostCommand's output instead of the text.
+	PostCommandReplacesText bool `json:"postCommandReplacesText,omitempty"`
+	// GPUDeviceIndex selects the GPU whisper runs on when there are several:
+	// whisper's gpu_device, an index into ListGPUDevices. 0 = first GPU.
+	GPUDeviceIndex int `json:"gpuDeviceIndex"`
+	// MaxLoadedEngines caps whisper models held in memory at once, 0 = unlimited.
+	// Past the cap the least recently used engine is closed before a new load.
+	MaxLoadedEngines int `json:"maxLoadedEngines"`
+	// FastestBackend is the winner of the last backend benchmark; it is
+	// recommended while it stays available. "" = not benchmarked, or no
+	// backend the app can run on won.
+	FastestBackend string `json:"fastestBackend,omitempty"`
+	// SuppressFirstRunPrompts is for scripted/kiosk deployments with pre-placed
+	// config and models: no onboarding wizard, and the window starts hidden in
+	// the tray. Set by an admin in the config file; not exposed in Settings.
+	SuppressFirstRunPrompts bool `json:"suppressFirstRunPrompts,omitempty"`
+	// HallucinationPhrases replaces the built-in phrases dropped as whisper
+	// hallucinations on silence ("thanks for watching"), in every language.
+	// Empty = built-in per-language lists.
+	HallucinationPhrases []string `json:"hallucinationPhrases,omitempty"`
+	// KeepShortOutput turns off the hallucination rules that eat real short
+	// answers: output of 3 letters or fewer, and single-word phrases ("bye").
+	KeepShortOutput bool `json:"keepShortOutput"`
+	// WatchConfig reloads config.json when it is edited outside the app (by
+	// hand, or synced from another machine). nil = default (on).
+	WatchConfig *bool `json:"watchConfig,omitempty"`
+
 	OnboardingDone bool     `json:"onboardingDone"`
 	Presets        []Preset `json:"presets"`
 }
@@ -47,22 +137,39 @@ func DefaultAppConfig() *AppConfig {
 	}
 }
 
+// Dir, when set, is used as the config directory instead of the executable's
+// or the OS one. Tests point it at t.TempDir() so they never touch a real
+// config.json.
+var Dir string
+
 // configDir returns the config directory.
-// Priority: directory of the executable (portable), fallback to OS-standard.
+// Priority: Dir, directory of the executable (portable), fallback to OS-standard.
 func configDir() (string, error) {
+	if Dir != "" {
+		return Dir, nil
+	}
 	exe, err := os.Executable()
 	if err == nil {
 		dir := filepath.Dir(exe)
-		testFile := filepath.Join(dir, ".transcribation_write_test")
-		if f, err := os.Create(testFile); err == nil {
-			f.Close()
-			os.Remove(testFile)
+		if dirWritable(dir) {
 			return dir, nil
 		}
 	}
 	return osConfigDir()
 }
 
+// dirWritable reports whether a file can be created in dir.
+func dirWritable(dir string) bool {
+	testFile := filepath.Join(dir, ".transcribation_write_test")
+	f, err := os.Create(testFile)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(testFile)
+	return true
+}
+
 func osConfigDir() (string, error) {
 	var base string
 	switch runtime.GOOS {
@@ -90,6 +197,16 @@ func configPath() (string, error) {
 	return filepath.Join(dir, "config.json"), nil
 }
 
+// TranslationsPath returns the path of the optional translations.json next to
+// config.json, which overrides built-in backend strings (see i18n.LoadOverrides).
+func TranslationsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "translations.json"), nil
+}
+
 // oldConfig is the legacy flat config format for migration.
 type oldConfig struct {
 	ModelName    string `json:"modelName"`
@@ -141,6 +258,7 @@ func migrateOldConfig(data []byte) *AppConfig {
 		InputMode:       mode,
 		Hotkey:          hotkey,
 		Language:        lang,
+		Translate:       old.Translate,
 		UseKBLayout:     false,
 		KeepHistory:     true,
 		Enabled:         hotkey != "", // enable if hotkey is set
@@ -168,7 +286,18 @@ func Load() (*AppConfig, error) {
 	// Try new format first
 	cfg := &AppConfig{}
 	if err := json.Unmarshal(data, cfg); err != nil {
-		return DefaultAppConfig(), err
+		// A truncated write must not silently wipe the presets: fall back to
+		// the copy Save kept of the last good config.
+		backup, bcfg, ok := loadBackup(path)
+		if !ok {
+			log.Printf("CONFIG CORRUPT: %s: %v; no usable backup, using defaults", path, err)
+			return DefaultAppConfig(), err
+		}
+		log.Printf("CONFIG CORRUPT: %s: %v; restored from %s", path, err, path+".bak")
+		data, cfg = backup, bcfg
+		if err := Save(cfg); err != nil {
+			log.Printf("failed to rewrite restored config: %v", err)
+		}
 	}
 
 	// Detect old format: has presets field → new format; no presets → old format
@@ -182,9 +311,7 @@ func Load() (*AppConfig, error) {
 		return DefaultAppConfig(), nil
 	}
 
-	// Existing users: if they already have presets but onboarding flag is missing,
-	// consider onboarding done so we don't show the wizard to returning users.
-	if len(cfg.Presets) > 0 && !cfg.OnboardingDone {
+	if skipOnboarding(cfg) {
 		cfg.OnboardingDone = true
 		_ = Save(cfg)
 	}
@@ -192,7 +319,30 @@ func Load() (*AppConfig, error) {
 	return cfg, nil
 }
 
-// Save writes config to disk.
+// skipOnboarding reports whether a config without the onboarding flag should
+// be treated as onboarded: existing users who already have presets, so the
+// wizard isn't shown to returning users, and managed deployments with
+// SuppressFirstRunPrompts, where an admin pre-placed config and models.
+func skipOnboarding(cfg *AppConfig) bool {
+	return !cfg.OnboardingDone && (len(cfg.Presets) > 0 || cfg.SuppressFirstRunPrompts)
+}
+
+// loadBackup reads config.json.bak next to path.
+func loadBackup(path string) ([]byte, *AppConfig, bool) {
+	data, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		return nil, nil, false
+	}
+	cfg := &AppConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, nil, false
+	}
+	return data, cfg, true
+}
+
+// Save writes config to disk atomically: into config.json.tmp, then renamed
+// over config.json, so a crash or full disk never leaves a truncated file.
+// The replaced config, if it was valid, is kept as config.json.bak.
 func Save(cfg *AppConfig) error {
 	path, err := configPath()
 	if err != nil {
@@ -203,5 +353,37 @@ func Save(cfg *AppConfig) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0o644)
+	tmp := path + ".tmp"
+	markSaved(data)
+	if err := writeSynced(tmp, data); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if old, err := os.ReadFile(path); err == nil && json.Valid(old) {
+		if err := os.WriteFile(path+".bak", old, 0o644); err != nil {
+			log.Printf("failed to back up config: %v", err)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeSynced writes data to path and flushes it to disk before returning.
+func writeSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
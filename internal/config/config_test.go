@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"os"
 	"testing"
 )
 
@@ -17,6 +18,7 @@ func TestMigrateOldConfigAudit(t *testing.T) {
 				ModelName:    "small",
 				ModelsDir:    "/path/to/models",
 				Language:     "ru",
+				Translate:    true,
 				HotkeyMod:   "ctrl",
 				HotkeyKey:   "f1",
 				MicrophoneID: "mic-123",
@@ -49,6 +51,9 @@ func TestMigrateOldConfigAudit(t *testing.T) {
 				if p.InputMode != "toggle" {
 					t.Errorf("InputMode = %q, want %q", p.InputMode, "toggle")
 				}
+				if !p.Translate {
+					t.Error("Translate should carry over from the old config")
+				}
 				if !p.Enabled {
 					t.Error("Enabled should be true when hotkey is set")
 				}
@@ -325,6 +330,24 @@ func TestMigrateOldConfig_MalformedJSON(t *testing.T) {
 	}
 }
 
+func TestSkipOnboarding(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  AppConfig
+		want bool
+	}{
+		{"fresh install", AppConfig{Presets: []Preset{}}, false},
+		{"returning user", AppConfig{Presets: []Preset{{ID: "a"}}}, true},
+		{"managed deployment", AppConfig{Presets: []Preset{}, SuppressFirstRunPrompts: true}, true},
+		{"already onboarded", AppConfig{Presets: []Preset{{ID: "a"}}, OnboardingDone: true}, false},
+	}
+	for _, tt := range tests {
+		if got := skipOnboarding(&tt.cfg); got != tt.want {
+			t.Errorf("%s: skipOnboarding = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestAppConfigJSONRoundtrip(t *testing.T) {
 	original := &AppConfig{
 		MicrophoneID:   "mic-42",
@@ -345,6 +368,7 @@ func TestAppConfigJSONRoundtrip(t *testing.T) {
 				InputMode:       "toggle",
 				Hotkey:          "ctrl+shift+f1",
 				Language:        "en",
+				Translate:       true,
 				UseKBLayout:     true,
 				KeepHistory:     false,
 				Enabled:         true,
@@ -426,6 +450,9 @@ func TestAppConfigJSONRoundtrip(t *testing.T) {
 		if rp.Language != op.Language {
 			t.Errorf("Preset[%d].Language = %q, want %q", i, rp.Language, op.Language)
 		}
+		if rp.Translate != op.Translate {
+			t.Errorf("Preset[%d].Translate = %v, want %v", i, rp.Translate, op.Translate)
+		}
 		if rp.UseKBLayout != op.UseKBLayout {
 			t.Errorf("Preset[%d].UseKBLayout = %v, want %v", i, rp.UseKBLayout, op.UseKBLayout)
 		}
@@ -437,3 +464,51 @@ func TestAppConfigJSONRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadRecoversFromBackup(t *testing.T) {
+	Dir = t.TempDir()
+	t.Cleanup(func() { Dir = "" })
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	good := &AppConfig{OnboardingDone: true, Presets: []Preset{{ID: "p1", Name: "Dictation", ModelName: "small"}}}
+	if err := Save(good); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("backup after the first save: %v, want none", err)
+	}
+	newer := &AppConfig{OnboardingDone: true, Presets: []Preset{{ID: "p2", Name: "Chat", ModelName: "base"}}}
+	if err := Save(newer); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+
+	// A truncated primary falls back to the previous good config.
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load with a valid backup: %v", err)
+	}
+	if len(cfg.Presets) != 1 || cfg.Presets[0].Name != "Dictation" {
+		t.Errorf("recovered presets = %+v, want the backup's", cfg.Presets)
+	}
+	// The corrupt primary was rewritten from the backup.
+	if data, _ := os.ReadFile(path); !json.Valid(data) {
+		t.Error("config.json still corrupt after recovery")
+	}
+
+	// Without a usable backup Load reports the error and uses defaults.
+	os.WriteFile(path, []byte("{"), 0o644)
+	os.WriteFile(path+".bak", []byte("{"), 0o644)
+	if cfg, err := Load(); err == nil || len(cfg.Presets) != len(DefaultAppConfig().Presets) {
+		t.Errorf("Load without backup = %d presets, %v; want defaults and an error", len(cfg.Presets), err)
+	}
+}
@@ -2,18 +2,23 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
-const MaxHistoryEntries = 50
+// DefaultHistoryLimit is the number of history entries kept when
+// AppConfig.HistoryLimit is unset.
+const DefaultHistoryLimit = 50
 
 // HistoryEntry represents a single transcription result.
 type HistoryEntry struct {
 	Text      string `json:"text"`
 	Timestamp int64  `json:"timestamp"`
 	Language  string `json:"language"`
+	Pinned    bool   `json:"pinned,omitempty"` // never trimmed by the history limit
 }
 
 func historyPath() (string, error) {
@@ -57,8 +62,22 @@ func SaveHistory(entries []HistoryEntry) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// AppendHistory adds a new entry at the beginning, trims to MaxHistoryEntries.
-func AppendHistory(text, language string) error {
+// HistoryLimit resolves cfg.HistoryLimit: unset → DefaultHistoryLimit,
+// 0 or negative → 0 (history disabled).
+func HistoryLimit(cfg *AppConfig) int {
+	if cfg == nil || cfg.HistoryLimit == nil {
+		return DefaultHistoryLimit
+	}
+	return max(*cfg.HistoryLimit, 0)
+}
+
+// AppendHistory adds a new entry at the beginning and trims the history to
+// limit unpinned entries, so files saved under a larger limit shrink on the
+// next append. limit 0 disables history: nothing is written.
+func AppendHistory(text, language string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
 	entries, _ := LoadHistory()
 
 	entry := HistoryEntry{
@@ -68,11 +87,36 @@ func AppendHistory(text, language string) error {
 	}
 
 	entries = append([]HistoryEntry{entry}, entries...)
-	if len(entries) > MaxHistoryEntries {
-		entries = entries[:MaxHistoryEntries]
+	return SaveHistory(trimHistory(entries, limit))
+}
+
+// trimHistory keeps the newest limit unpinned entries plus every pinned one,
+// preserving the newest-first order (pinned entries stay where they were).
+func trimHistory(entries []HistoryEntry, limit int) []HistoryEntry {
+	kept := entries[:0]
+	unpinned := 0
+	for _, e := range entries {
+		if !e.Pinned {
+			if unpinned >= limit {
+				continue
+			}
+			unpinned++
+		}
+		kept = append(kept, e)
 	}
+	return kept
+}
 
-	return SaveHistory(entries)
+// SetHistoryPinned pins or unpins the entry with the given timestamp.
+func SetHistoryPinned(timestamp int64, pinned bool) error {
+	entries, _ := LoadHistory()
+	for i := range entries {
+		if entries[i].Timestamp == timestamp {
+			entries[i].Pinned = pinned
+			return SaveHistory(entries)
+		}
+	}
+	return fmt.Errorf("history entry not found: %d", timestamp)
 }
 
 // ClearHistory removes all history entries.
@@ -91,3 +135,45 @@ func DeleteHistoryEntry(timestamp int64) error {
 	}
 	return nil
 }
+
+// SearchHistory returns the entries whose text contains every whitespace-separated
+// term of query (case-insensitive), keeping the stored newest-first order.
+// An empty query matches everything.
+func SearchHistory(entries []HistoryEntry, query string) []HistoryEntry {
+	terms := strings.Fields(strings.ToLower(query))
+	matches := []HistoryEntry{}
+	for _, e := range entries {
+		text := strings.ToLower(e.Text)
+		ok := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// FilterHistory keeps the entries in language lang recorded within
+// [sinceMs, untilMs] (Unix milliseconds), preserving order. An empty lang or a
+// zero bound leaves that filter off.
+func FilterHistory(entries []HistoryEntry, lang string, sinceMs, untilMs int64) []HistoryEntry {
+	matches := []HistoryEntry{}
+	for _, e := range entries {
+		if lang != "" && !strings.EqualFold(e.Language, lang) {
+			continue
+		}
+		if sinceMs != 0 && e.Timestamp < sinceMs {
+			continue
+		}
+		if untilMs != 0 && e.Timestamp > untilMs {
+			continue
+		}
+		matches = append(matches, e)
+	}
+	return matches
+}
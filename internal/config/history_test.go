@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 )
 
 // cleanupHistory removes the history file used by tests.
@@ -26,9 +27,9 @@ func TestAppendHistory_Trim(t *testing.T) {
 	cleanupHistory()
 	t.Cleanup(cleanupHistory)
 
-	// Append 52 entries — should trim to MaxHistoryEntries (50).
+	// Append 52 entries — should trim to DefaultHistoryLimit (50).
 	for i := 0; i < 52; i++ {
-		if err := AppendHistory(fmt.Sprintf("entry-%d", i), "en"); err != nil {
+		if err := AppendHistory(fmt.Sprintf("entry-%d", i), "en", DefaultHistoryLimit); err != nil {
 			t.Fatalf("AppendHistory(%d): %v", i, err)
 		}
 	}
@@ -37,8 +38,8 @@ func TestAppendHistory_Trim(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadHistory: %v", err)
 	}
-	if len(entries) != MaxHistoryEntries {
-		t.Errorf("len(entries) = %d, want %d", len(entries), MaxHistoryEntries)
+	if len(entries) != DefaultHistoryLimit {
+		t.Errorf("len(entries) = %d, want %d", len(entries), DefaultHistoryLimit)
 	}
 
 	// Most recent entry should be first (prepend order).
@@ -46,8 +47,8 @@ func TestAppendHistory_Trim(t *testing.T) {
 		t.Errorf("entries[0].Text = %q, want %q", entries[0].Text, "entry-51")
 	}
 	// Oldest surviving entry should be entry-2 (entries 0 and 1 were trimmed).
-	if entries[MaxHistoryEntries-1].Text != "entry-2" {
-		t.Errorf("entries[%d].Text = %q, want %q", MaxHistoryEntries-1, entries[MaxHistoryEntries-1].Text, "entry-2")
+	if entries[DefaultHistoryLimit-1].Text != "entry-2" {
+		t.Errorf("entries[%d].Text = %q, want %q", DefaultHistoryLimit-1, entries[DefaultHistoryLimit-1].Text, "entry-2")
 	}
 }
 
@@ -55,10 +56,10 @@ func TestDeleteHistoryEntry(t *testing.T) {
 	cleanupHistory()
 	t.Cleanup(cleanupHistory)
 
-	if err := AppendHistory("first", "en"); err != nil {
+	if err := AppendHistory("first", "en", DefaultHistoryLimit); err != nil {
 		t.Fatalf("AppendHistory(first): %v", err)
 	}
-	if err := AppendHistory("second", "ru"); err != nil {
+	if err := AppendHistory("second", "ru", DefaultHistoryLimit); err != nil {
 		t.Fatalf("AppendHistory(second): %v", err)
 	}
 
@@ -96,7 +97,7 @@ func TestClearHistory(t *testing.T) {
 	t.Cleanup(cleanupHistory)
 
 	for i := 0; i < 5; i++ {
-		if err := AppendHistory(fmt.Sprintf("item-%d", i), "de"); err != nil {
+		if err := AppendHistory(fmt.Sprintf("item-%d", i), "de", DefaultHistoryLimit); err != nil {
 			t.Fatalf("AppendHistory(%d): %v", i, err)
 		}
 	}
@@ -121,3 +122,167 @@ func TestClearHistory(t *testing.T) {
 		t.Errorf("len(entries) after clear = %d, want 0", len(entries))
 	}
 }
+
+func TestAppendHistory_Limit(t *testing.T) {
+	cleanupHistory()
+	t.Cleanup(cleanupHistory)
+
+	// limit 0 disables history: no file is written.
+	if err := AppendHistory("secret", "en", 0); err != nil {
+		t.Fatalf("AppendHistory(limit 0): %v", err)
+	}
+	path, _ := historyPath()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("history file written with limit 0 (stat err: %v)", err)
+	}
+
+	// A limit above the current count keeps everything.
+	for i := 0; i < 5; i++ {
+		if err := AppendHistory(fmt.Sprintf("entry-%d", i), "en", 500); err != nil {
+			t.Fatalf("AppendHistory(%d): %v", i, err)
+		}
+	}
+	entries, _ := LoadHistory()
+	if len(entries) != 5 {
+		t.Errorf("len(entries) = %d, want 5", len(entries))
+	}
+
+	// Lowering the limit trims the existing file on the next append.
+	if err := AppendHistory("latest", "en", 3); err != nil {
+		t.Fatalf("AppendHistory(limit 3): %v", err)
+	}
+	entries, _ = LoadHistory()
+	if len(entries) != 3 || entries[0].Text != "latest" || entries[2].Text != "entry-3" {
+		t.Errorf("after trim got %+v, want latest, entry-4, entry-3", entries)
+	}
+}
+
+func TestAppendHistory_KeepsPinned(t *testing.T) {
+	cleanupHistory()
+	t.Cleanup(cleanupHistory)
+
+	const limit = 3
+	// Entries are addressed by timestamp, so keep them distinct.
+	for i := 0; i < limit; i++ {
+		time.Sleep(time.Millisecond)
+		if err := AppendHistory(fmt.Sprintf("keep-%d", i), "en", limit); err != nil {
+			t.Fatalf("AppendHistory: %v", err)
+		}
+	}
+	entries, _ := LoadHistory()
+	for _, e := range entries {
+		if err := SetHistoryPinned(e.Timestamp, true); err != nil {
+			t.Fatalf("SetHistoryPinned: %v", err)
+		}
+	}
+
+	// Well past the limit: every pinned entry survives, plus limit unpinned ones.
+	for i := 0; i < 2*limit; i++ {
+		time.Sleep(time.Millisecond)
+		if err := AppendHistory(fmt.Sprintf("new-%d", i), "en", limit); err != nil {
+			t.Fatalf("AppendHistory: %v", err)
+		}
+	}
+	entries, _ = LoadHistory()
+	var pinned, unpinned []string
+	for _, e := range entries {
+		if e.Pinned {
+			pinned = append(pinned, e.Text)
+		} else {
+			unpinned = append(unpinned, e.Text)
+		}
+	}
+	if fmt.Sprint(pinned) != "[keep-2 keep-1 keep-0]" {
+		t.Errorf("pinned = %v, want [keep-2 keep-1 keep-0]", pinned)
+	}
+	if fmt.Sprint(unpinned) != "[new-5 new-4 new-3]" {
+		t.Errorf("unpinned = %v, want [new-5 new-4 new-3]", unpinned)
+	}
+
+	if err := SetHistoryPinned(-1, true); err == nil {
+		t.Error("SetHistoryPinned(unknown) = nil, want error")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	zero, neg, big := 0, -1, 500
+	tests := []struct {
+		cfg  *AppConfig
+		want int
+	}{
+		{nil, DefaultHistoryLimit},
+		{&AppConfig{}, DefaultHistoryLimit},
+		{&AppConfig{HistoryLimit: &zero}, 0},
+		{&AppConfig{HistoryLimit: &neg}, 0},
+		{&AppConfig{HistoryLimit: &big}, 500},
+	}
+	for _, tt := range tests {
+		if got := HistoryLimit(tt.cfg); got != tt.want {
+			t.Errorf("HistoryLimit(%+v) = %d, want %d", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	entries := []HistoryEntry{
+		{Text: "Send the report to Anna", Timestamp: 3},
+		{Text: "Привет, как дела?", Timestamp: 2},
+		{Text: "the weekly REPORT is late", Timestamp: 1},
+	}
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"", []int64{3, 2, 1}},
+		{"report", []int64{3, 1}},
+		{"  Report  ", []int64{3, 1}},
+		{"report late", []int64{1}},
+		{"ПРИВЕТ", []int64{2}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		got := SearchHistory(entries, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("SearchHistory(%q) returned %d entries, want %d", tt.query, len(got), len(tt.want))
+			continue
+		}
+		for i, e := range got {
+			if e.Timestamp != tt.want[i] {
+				t.Errorf("SearchHistory(%q)[%d].Timestamp = %d, want %d", tt.query, i, e.Timestamp, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	entries := []HistoryEntry{
+		{Text: "c", Timestamp: 300, Language: "en"},
+		{Text: "b", Timestamp: 200, Language: "ru"},
+		{Text: "a", Timestamp: 100, Language: "en"},
+	}
+	tests := []struct {
+		lang         string
+		since, until int64
+		want         []int64
+	}{
+		{"", 0, 0, []int64{300, 200, 100}},
+		{"en", 0, 0, []int64{300, 100}},
+		{"EN", 0, 0, []int64{300, 100}},
+		{"", 200, 0, []int64{300, 200}},
+		{"", 0, 200, []int64{200, 100}},
+		{"en", 150, 300, []int64{300}},
+		{"de", 0, 0, nil},
+	}
+	for _, tt := range tests {
+		got := FilterHistory(entries, tt.lang, tt.since, tt.until)
+		if len(got) != len(tt.want) {
+			t.Errorf("FilterHistory(%q, %d, %d) returned %d entries, want %d", tt.lang, tt.since, tt.until, len(got), len(tt.want))
+			continue
+		}
+		for i, e := range got {
+			if e.Timestamp != tt.want[i] {
+				t.Errorf("FilterHistory(%q, %d, %d)[%d].Timestamp = %d, want %d", tt.lang, tt.since, tt.until, i, e.Timestamp, tt.want[i])
+			}
+		}
+	}
+}
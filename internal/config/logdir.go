@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// LogDir returns the directory for run.log.
+// Priority: configured logDir, then the directory of the executable (portable),
+// then the OS log directory for read-only installs (AppImage, /usr/bin, app bundles),
+// then the config directory if even that can't be created.
+func LogDir() string {
+	custom := ""
+	if cfg, err := Load(); err == nil {
+		custom = cfg.LogDir
+	}
+	exeDir := ""
+	if exe, err := os.Executable(); err == nil {
+		exeDir = filepath.Dir(exe)
+	}
+	return selectLogDir(custom, exeDir)
+}
+
+func selectLogDir(custom, exeDir string) string {
+	if custom != "" {
+		if err := os.MkdirAll(custom, 0o755); err == nil && dirWritable(custom) {
+			return custom
+		}
+	}
+	if exeDir != "" && dirWritable(exeDir) {
+		return exeDir
+	}
+	dir := osLogDir()
+	if err := os.MkdirAll(dir, 0o755); err == nil && dirWritable(dir) {
+		return dir
+	}
+	if cfgDir, err := osConfigDir(); err == nil {
+		return cfgDir
+	}
+	return dir
+}
+
+// OpenLog opens path for a new run's log. The previous run's log is kept as
+// path.1 (one generation), so a crash report survives the restart after it.
+func OpenLog(path string) (*os.File, error) {
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Rename(path, path+".1"); err != nil {
+			log.Printf("log rotation failed: %v", err)
+		}
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+func osLogDir() string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		base := os.Getenv("LOCALAPPDATA")
+		if base == "" {
+			base = filepath.Join(home, "AppData", "Local")
+		}
+		return filepath.Join(base, "transcribation", "logs")
+	case "darwin":
+		return filepath.Join(home, "Library", "Logs", "transcribation")
+	default:
+		base := os.Getenv("XDG_STATE_HOME")
+		if base == "" {
+			base = filepath.Join(home, ".local", "state")
+		}
+		return filepath.Join(base, "transcribation")
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSelectLogDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tmp, "state"))
+	t.Setenv("LOCALAPPDATA", filepath.Join(tmp, "local"))
+
+	exeDir := filepath.Join(tmp, "exe")
+	if err := os.MkdirAll(exeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A regular file stands in for an unwritable location: nothing can be
+	// created beneath it, even when running as root.
+	blocker := filepath.Join(tmp, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	custom := filepath.Join(tmp, "custom", "logs")
+
+	tests := []struct {
+		name   string
+		custom string
+		exeDir string
+		want   string
+	}{
+		{"exe dir writable", "", exeDir, exeDir},
+		{"custom wins", custom, exeDir, custom},
+		{"custom unwritable falls back to exe dir", filepath.Join(blocker, "logs"), exeDir, exeDir},
+		{"exe dir unwritable falls back to OS dir", "", blocker, osLogDir()},
+		{"no exe dir", "", "", osLogDir()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectLogDir(tt.custom, tt.exeDir); got != tt.want {
+				t.Errorf("selectLogDir(%q, %q) = %q, want %q", tt.custom, tt.exeDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOSLogDirXDG(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_STATE_HOME only applies on Linux")
+	}
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	if got, want := osLogDir(), filepath.Join("/tmp/xdg-state", "transcribation"); got != want {
+		t.Errorf("osLogDir() = %q, want %q", got, want)
+	}
+}
+
+func TestSelectLogDirFallsBackToConfigDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories only apply on Linux")
+	}
+	tmp := t.TempDir()
+	blocker := filepath.Join(tmp, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_STATE_HOME", blocker)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config"))
+
+	if got, want := selectLogDir("", blocker), filepath.Join(tmp, "config", "transcribation"); got != want {
+		t.Errorf("selectLogDir() = %q, want %q", got, want)
+	}
+}
+
+func TestOpenLogKeepsPreviousRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	for _, run := range []string{"first\n", "second\n", "third\n"} {
+		f, err := OpenLog(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(run)
+		f.Close()
+	}
+	if got, _ := os.ReadFile(path); string(got) != "third\n" {
+		t.Errorf("run.log = %q, want %q", got, "third\n")
+	}
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "second\n" {
+		t.Errorf("run.log.1 = %q, want %q", got, "second\n")
+	}
+}
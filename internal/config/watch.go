@@ -0,0 +1,121 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// watchInterval is how often Watch polls config.json. A change is reported
+// once the file stayed the same for one more interval, so an editor's
+// truncate-then-write is seen as one edit.
+const watchInterval = time.Second
+
+// savedSum is the checksum of the config Save last wrote, so the watcher can
+// tell the app's own writes from external edits.
+var (
+	savedMu  sync.Mutex
+	savedSum [sha256.Size]byte
+)
+
+func markSaved(data []byte) {
+	savedMu.Lock()
+	savedSum = sha256.Sum256(data)
+	savedMu.Unlock()
+}
+
+func writtenBySave(sum [sha256.Size]byte) bool {
+	savedMu.Lock()
+	defer savedMu.Unlock()
+	return sum == savedSum
+}
+
+// Watch calls onChange after config.json was changed outside the app. Writes
+// by Save are not reported. It polls instead of using OS file notifications,
+// which an atomic rename by an editor or a sync client often slips past.
+// The returned function stops watching.
+func Watch(onChange func()) (stop func()) {
+	path, err := configPath()
+	if err != nil {
+		log.Printf("config watch disabled: %v", err)
+		return func() {}
+	}
+	return watchFile(path, watchInterval, onChange)
+}
+
+// watchFile polls path every interval and calls onChange when its content
+// changed to valid JSON that Save didn't write.
+func watchFile(path string, interval time.Duration, onChange func()) (stop func()) {
+	type stamp struct {
+		mod  time.Time
+		size int64
+	}
+	stampOf := func() (stamp, bool) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return stamp{}, false
+		}
+		return stamp{fi.ModTime(), fi.Size()}, true
+	}
+
+	last, _ := stampOf()
+	var lastSum [sha256.Size]byte
+	if data, err := os.ReadFile(path); err == nil {
+		lastSum = sha256.Sum256(data)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("recovered panic in config watch: %v", r)
+			}
+		}()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		pending := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			cur, ok := stampOf()
+			if !ok {
+				continue // being replaced, or deleted
+			}
+			if cur != last {
+				last, pending = cur, true
+				continue
+			}
+			if !pending {
+				continue
+			}
+			pending = false
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			sum := sha256.Sum256(data)
+			if sum == lastSum {
+				continue
+			}
+			if !json.Valid(data) {
+				log.Printf("config watch: %s is not valid JSON, not reloading", path)
+				continue
+			}
+			lastSum = sum
+			if writtenBySave(sum) {
+				continue
+			}
+			log.Printf("config watch: %s changed outside the app, reloading", path)
+			onChange()
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
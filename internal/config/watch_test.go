@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"presets":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 10)
+	stop := watchFile(path, 10*time.Millisecond, func() { changed <- struct{}{} })
+	defer stop()
+
+	expect := func(what string, want bool) {
+		t.Helper()
+		select {
+		case <-changed:
+			if !want {
+				t.Errorf("%s: reported as an external change", what)
+			}
+		case <-time.After(200 * time.Millisecond):
+			if want {
+				t.Errorf("%s: not reported", what)
+			}
+		}
+	}
+	// Writes are spaced out so each one changes the modification time.
+	write := func(data string) {
+		t.Helper()
+		time.Sleep(20 * time.Millisecond)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"theme":"light","presets":[]}`)
+	expect("external edit", true)
+
+	own := `{"theme":"dark","presets":[]}`
+	markSaved([]byte(own))
+	write(own)
+	expect("write by Save", false)
+
+	write(`{"theme":`)
+	expect("half-written file", false)
+}
@@ -3,6 +3,12 @@
 // Frontend translations live in frontend/src/lib/i18n.ts.
 package i18n
 
+import (
+	"encoding/json"
+	"log"
+	"os"
+)
+
 // T returns the localized string for the given language and key.
 // Falls back to English if the language or key is not found.
 func T(lang, key string) string {
@@ -17,10 +23,46 @@ func T(lang, key string) string {
 	return key
 }
 
+// LoadOverrides merges strings from a JSON file shaped like translations
+// ({"ru": {"tray_show": "…"}}) over the built-in ones, so translators can try
+// tray and dialog strings without rebuilding. Call it at startup, before any
+// T lookups. A missing file is normal; a malformed one is logged and ignored.
+func LoadOverrides(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("i18n: cannot read %s: %v", path, err)
+		}
+		return
+	}
+	var overrides map[string]map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		log.Printf("i18n: ignoring malformed %s: %v", path, err)
+		return
+	}
+	n := 0
+	for lang, strs := range overrides {
+		if translations[lang] == nil {
+			translations[lang] = make(map[string]string, len(strs))
+		}
+		for key, v := range strs {
+			translations[lang][key] = v
+			n++
+		}
+	}
+	log.Printf("i18n: %d strings overridden from %s", n, path)
+}
+
 var translations = map[string]map[string]string{
 	"en": {
 		"tray_show":            "Show",
 		"tray_history":         "History",
+		"tray_pause":           "Pause hotkeys",
+		"tray_paused_tooltip":  "MorgoTTalk — hotkeys paused",
+		"tray_start_recording": "Start recording",
+		"tray_stop_recording":  "Stop recording",
+		"tray_presets":         "Presets",
+		"tray_no_presets":      "No presets",
 		"tray_quit":            "Quit",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "What would you like to do when closing the window?",
@@ -30,6 +72,12 @@ var translations = map[string]map[string]string{
 	"ru": {
 		"tray_show":            "Показать",
 		"tray_history":         "История",
+		"tray_pause":           "Приостановить горячие клавиши",
+		"tray_paused_tooltip":  "MorgoTTalk — горячие клавиши приостановлены",
+		"tray_start_recording": "Начать запись",
+		"tray_stop_recording":  "Остановить запись",
+		"tray_presets":         "Пресеты",
+		"tray_no_presets":      "Нет пресетов",
 		"tray_quit":            "Выход",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "Что сделать при закрытии окна?",
@@ -39,6 +87,12 @@ var translations = map[string]map[string]string{
 	"de": {
 		"tray_show":            "Anzeigen",
 		"tray_history":         "Verlauf",
+		"tray_pause":           "Tastenkürzel pausieren",
+		"tray_paused_tooltip":  "MorgoTTalk — Tastenkürzel pausiert",
+		"tray_start_recording": "Aufnahme starten",
+		"tray_stop_recording":  "Aufnahme stoppen",
+		"tray_presets":         "Presets",
+		"tray_no_presets":      "Keine Presets",
 		"tray_quit":            "Beenden",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "Was möchten Sie beim Schließen des Fensters tun?",
@@ -48,6 +102,12 @@ var translations = map[string]map[string]string{
 	"es": {
 		"tray_show":            "Mostrar",
 		"tray_history":         "Historial",
+		"tray_pause":           "Pausar atajos",
+		"tray_paused_tooltip":  "MorgoTTalk — atajos en pausa",
+		"tray_start_recording": "Iniciar grabación",
+		"tray_stop_recording":  "Detener grabación",
+		"tray_presets":         "Ajustes",
+		"tray_no_presets":      "Sin ajustes",
 		"tray_quit":            "Salir",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "¿Qué desea hacer al cerrar la ventana?",
@@ -57,6 +117,12 @@ var translations = map[string]map[string]string{
 	"fr": {
 		"tray_show":            "Afficher",
 		"tray_history":         "Historique",
+		"tray_pause":           "Suspendre les raccourcis",
+		"tray_paused_tooltip":  "MorgoTTalk — raccourcis suspendus",
+		"tray_start_recording": "Démarrer l'enregistrement",
+		"tray_stop_recording":  "Arrêter l'enregistrement",
+		"tray_presets":         "Préréglages",
+		"tray_no_presets":      "Aucun préréglage",
 		"tray_quit":            "Quitter",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "Que souhaitez-vous faire en fermant la fenêtre ?",
@@ -66,6 +132,12 @@ var translations = map[string]map[string]string{
 	"zh": {
 		"tray_show":            "显示",
 		"tray_history":         "历史记录",
+		"tray_pause":           "暂停快捷键",
+		"tray_paused_tooltip":  "MorgoTTalk — 快捷键已暂停",
+		"tray_start_recording": "开始录音",
+		"tray_stop_recording":  "停止录音",
+		"tray_presets":         "预设",
+		"tray_no_presets":      "无预设",
 		"tray_quit":            "退出",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "关闭窗口时您想做什么？",
@@ -75,6 +147,12 @@ var translations = map[string]map[string]string{
 	"ja": {
 		"tray_show":            "表示",
 		"tray_history":         "履歴",
+		"tray_pause":           "ホットキーを一時停止",
+		"tray_paused_tooltip":  "MorgoTTalk — ホットキー一時停止中",
+		"tray_start_recording": "録音開始",
+		"tray_stop_recording":  "録音停止",
+		"tray_presets":         "プリセット",
+		"tray_no_presets":      "プリセットなし",
 		"tray_quit":            "終了",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "ウィンドウを閉じるときの動作を選択してください",
@@ -84,6 +162,12 @@ var translations = map[string]map[string]string{
 	"pt": {
 		"tray_show":            "Mostrar",
 		"tray_history":         "Histórico",
+		"tray_pause":           "Pausar atalhos",
+		"tray_paused_tooltip":  "MorgoTTalk — atalhos pausados",
+		"tray_start_recording": "Iniciar gravação",
+		"tray_stop_recording":  "Parar gravação",
+		"tray_presets":         "Presets",
+		"tray_no_presets":      "Nenhum preset",
 		"tray_quit":            "Sair",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "O que deseja fazer ao fechar a janela?",
@@ -93,6 +177,12 @@ var translations = map[string]map[string]string{
 	"ko": {
 		"tray_show":            "표시",
 		"tray_history":         "기록",
+		"tray_pause":           "단축키 일시 중지",
+		"tray_paused_tooltip":  "MorgoTTalk — 단축키 일시 중지됨",
+		"tray_start_recording": "녹음 시작",
+		"tray_stop_recording":  "녹음 중지",
+		"tray_presets":         "프리셋",
+		"tray_no_presets":      "프리셋 없음",
 		"tray_quit":            "종료",
 		"close_dialog_title":   "MorgoTTalk",
 		"close_dialog_message": "창을 닫을 때 어떻게 하시겠습니까?",
@@ -2,6 +2,9 @@ package i18n
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -84,3 +87,42 @@ func TestAllLanguagesHaveAllKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadOverrides(t *testing.T) {
+	saved := make(map[string]map[string]string, len(translations))
+	for lang, m := range translations {
+		saved[lang] = maps.Clone(m)
+	}
+	t.Cleanup(func() { translations = saved })
+
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"ru": "not a map"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	LoadOverrides(bad)
+	LoadOverrides(filepath.Join(dir, "missing.json"))
+	if got := T("ru", "tray_quit"); got != "Выход" {
+		t.Errorf("after malformed/missing overrides T(ru, tray_quit) = %q, want built-in", got)
+	}
+
+	good := filepath.Join(dir, "translations.json")
+	data := `{"ru": {"tray_quit": "Закрыть"}, "uk": {"tray_show": "Показати"}}`
+	if err := os.WriteFile(good, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	LoadOverrides(good)
+	tests := []struct {
+		lang, key, want string
+	}{
+		{"ru", "tray_quit", "Закрыть"},  // overridden
+		{"ru", "tray_show", "Показать"}, // built-in kept
+		{"uk", "tray_show", "Показати"}, // new language
+		{"uk", "tray_quit", "Quit"},     // English fallback
+	}
+	for _, tt := range tests {
+		if got := T(tt.lang, tt.key); got != tt.want {
+			t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
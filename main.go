@@ -25,12 +25,8 @@ var assets embed.FS
 var appIcon []byte
 
 func initLog() *os.File {
-	exe, err := os.Executable()
-	if err != nil {
-		return nil
-	}
-	logPath := filepath.Join(filepath.Dir(exe), "run.log")
-	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	logPath := filepath.Join(config.LogDir(), "run.log")
+	f, err := config.OpenLog(logPath)
 	if err != nil {
 		return nil
 	}
@@ -44,6 +40,9 @@ func main() {
 		defer logFile.Close()
 	}
 	services.AppVersion = AppVersion
+	if path, err := config.TranslationsPath(); err == nil {
+		i18n.LoadOverrides(path)
+	}
 
 	historyService := services.NewHistoryService()
 	modelService := services.NewModelService()
@@ -138,6 +137,59 @@ func main() {
 	trayMenu.Add(i18n.T(lang, "tray_history")).OnClick(func(_ *application.Context) {
 		historyService.OpenHistoryWindow()
 	})
+	// Quick record: starts/stops the primary preset (or the first enabled one).
+	// The label follows the recording state, polled like the main window does.
+	recordItem := trayMenu.Add(i18n.T(lang, "tray_start_recording"))
+	recordItem.OnClick(func(_ *application.Context) {
+		if err := presetService.ToggleQuickRecording(); err != nil {
+			log.Printf("Tray: %v", err)
+		}
+	})
+	go func() {
+		shown := "idle"
+		for range time.Tick(300 * time.Millisecond) {
+			state := presetService.QuickRecordingState()
+			if state == shown {
+				continue
+			}
+			shown = state
+			if state == "recording" {
+				recordItem.SetLabel(i18n.T(lang, "tray_stop_recording"))
+			} else {
+				recordItem.SetLabel(i18n.T(lang, "tray_start_recording"))
+			}
+			// Nothing to start with. While the last recording transcribes the
+			// next one can already start.
+			recordItem.SetEnabled(state != "")
+			trayMenu.Update()
+		}
+	}()
+	// One checkbox per preset, rebuilt whenever the presets change.
+	presetsMenu := trayMenu.AddSubmenu(i18n.T(lang, "tray_presets"))
+	var rebuildPresetsMenu func()
+	rebuildPresetsMenu = func() {
+		presetsMenu.Clear()
+		presets := presetService.GetPresets()
+		if len(presets) == 0 {
+			presetsMenu.Add(i18n.T(lang, "tray_no_presets")).SetEnabled(false)
+		}
+		for _, p := range presets {
+			id := p.ID
+			presetsMenu.AddCheckbox(p.Name, p.Enabled).OnClick(func(ctx *application.Context) {
+				if err := presetService.SetPresetEnabled(id, ctx.ClickedMenuItem().Checked()); err != nil {
+					log.Printf("Tray: %v", err)
+					rebuildPresetsMenu() // undo the checkmark
+				}
+			})
+		}
+		trayMenu.Update()
+	}
+	rebuildPresetsMenu()
+	presetService.SetOnPresetsChanged(rebuildPresetsMenu)
+	pauseItem := trayMenu.AddCheckbox(i18n.T(lang, "tray_pause"), false)
+	pauseItem.OnClick(func(ctx *application.Context) {
+		presetService.SetPaused(ctx.ClickedMenuItem().Checked())
+	})
 	trayMenu.AddSeparator()
 	trayMenu.Add(i18n.T(lang, "tray_quit")).OnClick(func(_ *application.Context) {
 		doQuit()
@@ -147,6 +199,16 @@ func main() {
 	tray.SetIcon(appIcon)
 	tray.SetMenu(trayMenu)
 	tray.SetTooltip("MorgoTTalk")
+	// Keep the checkbox and tooltip in sync when the pause hotkey toggles it.
+	presetService.SetOnPauseChanged(func(paused bool) {
+		pauseItem.SetChecked(paused)
+		trayMenu.Update()
+		if paused {
+			tray.SetTooltip(i18n.T(lang, "tray_paused_tooltip"))
+		} else {
+			tray.SetTooltip("MorgoTTalk")
+		}
+	})
 	tray.OnClick(func() {
 		mainWindow.Show()
 		mainWindow.Focus()
@@ -156,8 +218,8 @@ func main() {
 		mainWindow.Focus()
 	})
 
-	// --- Start minimized ---
-	if cfg.StartMinimized {
+	// --- Start minimized (always for managed deployments) ---
+	if cfg.StartMinimized || cfg.SuppressFirstRunPrompts {
 		mainWindow.Hide()
 	}
 
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// activeWindowClass returns identifiers of the focused application for
+// Preset.AppMatch: the window class and the process name, lowercased.
+// Returns nil if the foreground window cannot be determined.
+func activeWindowClass() []string {
+	var ids []string
+	switch runtime.GOOS {
+	case "windows":
+		ids = winActiveWindow()
+	case "linux":
+		ids = activeWindowLinux()
+	case "darwin":
+		ids = activeWindowDarwin()
+	}
+	for i := range ids {
+		ids[i] = strings.ToLower(strings.TrimSpace(ids[i]))
+	}
+	return ids
+}
+
+// focusedApp is activeWindowClass, swapped out in tests.
+var focusedApp = activeWindowClass
+
+// activeWindowLinux uses xdotool (X11 / XWayland), then hyprctl (Hyprland).
+func activeWindowLinux() []string {
+	var ids []string
+	if out, err := exec.Command("xdotool", "getactivewindow", "getwindowclassname").Output(); err == nil {
+		ids = append(ids, strings.TrimSpace(string(out)))
+		if pid, err := exec.Command("xdotool", "getactivewindow", "getwindowpid").Output(); err == nil {
+			if comm, err := os.ReadFile(filepath.Join("/proc", strings.TrimSpace(string(pid)), "comm")); err == nil {
+				ids = append(ids, strings.TrimSpace(string(comm)))
+			}
+		}
+		return ids
+	}
+	// hyprctl prints "class: <name>" among other fields.
+	if out, err := exec.Command("hyprctl", "activewindow").Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(line), "class:"); ok {
+				ids = append(ids, strings.TrimSpace(v))
+			}
+		}
+	}
+	return ids
+}
+
+// activeWindowDarwin returns the name of the frontmost application process.
+func activeWindowDarwin() []string {
+	script := `tell application "System Events" to get name of first application process whose frontmost is true`
+	if out, err := exec.Command("osascript", "-e", script).Output(); err == nil {
+		return []string{strings.TrimSpace(string(out))}
+	}
+	return nil
+}
+
+// matchApp reports whether any glob in patterns matches any of the app
+// identifiers (case-insensitive, path.Match syntax: "code*", "*slack*").
+func matchApp(patterns, ids []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		for _, id := range ids {
+			if ok, _ := path.Match(p, id); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pickPresetForApp chooses which of several presets sharing a hotkey should
+// handle a press: the first whose AppMatch matches the focused app, otherwise
+// the first without AppMatch (the catch-all). Returns "" if none applies.
+func pickPresetForApp(candidates []config.Preset, ids []string) string {
+	fallback := ""
+	for _, p := range candidates {
+		if len(p.AppMatch) == 0 {
+			if fallback == "" {
+				fallback = p.ID
+			}
+			continue
+		}
+		if matchApp(p.AppMatch, ids) {
+			return p.ID
+		}
+	}
+	return fallback
+}
+
+// siblingPick is the preset chosen for one key press among presets sharing a
+// hotkey.
+type siblingPick struct {
+	seq    uint64 // HotkeyManager.PressSeq of the press
+	winner string
+}
+
+// pickSibling returns which of siblings (presets sharing presetID's hotkey)
+// handles the current press. Every sibling's press handler runs concurrently
+// and asks; the focused app is looked up once per press and the choice shared,
+// so focus changing between two lookups can't start two recordings or none.
+func (s *PresetService) pickSibling(presetID string, siblings []config.Preset) string {
+	var seq uint64
+	if s.hotkeys != nil {
+		seq = s.hotkeys.PressSeq(presetID)
+	}
+	ids := make([]string, len(siblings))
+	for i, p := range siblings {
+		ids[i] = p.ID
+	}
+	group := strings.Join(ids, ",")
+
+	s.pickMu.Lock()
+	defer s.pickMu.Unlock()
+	if pick, ok := s.siblingPicks[group]; ok && seq != 0 && pick.seq == seq {
+		return pick.winner
+	}
+	winner := pickPresetForApp(siblings, focusedApp())
+	if s.siblingPicks == nil {
+		s.siblingPicks = make(map[string]siblingPick)
+	}
+	s.siblingPicks[group] = siblingPick{seq: seq, winner: winner}
+	return winner
+}
@@ -0,0 +1,5 @@
+//go:build !windows
+
+package services
+
+func winActiveWindow() []string { return nil }
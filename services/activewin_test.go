@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestMatchApp(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		ids      []string
+		want     bool
+	}{
+		{[]string{"code"}, []string{"code", "code"}, true},
+		{[]string{"Code.exe"}, []string{"chrome_widgetwin_1", "code.exe"}, true},
+		{[]string{"*slack*"}, []string{"com.slack.slack"}, true},
+		{[]string{"telegram*"}, []string{"telegramdesktop"}, true},
+		{[]string{"code"}, []string{"vscodium"}, false},
+		{[]string{"", "  "}, []string{"code"}, false},
+		{[]string{"code"}, nil, false},
+		{[]string{"[bad"}, []string{"code"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchApp(tt.patterns, tt.ids); got != tt.want {
+			t.Errorf("matchApp(%v, %v) = %v, want %v", tt.patterns, tt.ids, got, tt.want)
+		}
+	}
+}
+
+func TestPickPresetForApp(t *testing.T) {
+	presets := []config.Preset{
+		{ID: "default"},
+		{ID: "editor", AppMatch: []string{"code*", "*jetbrains*"}},
+		{ID: "chat", AppMatch: []string{"*slack*", "telegram*"}},
+	}
+	tests := []struct {
+		ids  []string
+		want string
+	}{
+		{[]string{"code", "code"}, "editor"},
+		{[]string{"com.slack.slack"}, "chat"},
+		{[]string{"firefox"}, "default"},
+		{nil, "default"},
+	}
+	for _, tt := range tests {
+		if got := pickPresetForApp(presets, tt.ids); got != tt.want {
+			t.Errorf("pickPresetForApp(%v) = %q, want %q", tt.ids, got, tt.want)
+		}
+	}
+
+	// Without a catch-all, unmatched apps get nothing.
+	if got := pickPresetForApp(presets[1:], []string{"firefox"}); got != "" {
+		t.Errorf("pickPresetForApp without catch-all = %q, want \"\"", got)
+	}
+}
+
+func TestCheckHotkeyConflictAppScoped(t *testing.T) {
+	s := &PresetService{
+		cfg: &config.AppConfig{Presets: []config.Preset{
+			{ID: "default", Name: "Default", Hotkey: "f9", Enabled: true},
+			{ID: "editor", Name: "Editor", Hotkey: "f9", Enabled: true, AppMatch: []string{"code"}},
+		}},
+		states:  make(map[string]string),
+		hotkeys: NewHotkeyManager(nil, nil),
+	}
+	for _, p := range s.cfg.Presets {
+		_ = s.hotkeys.Register(p.ID, p.Hotkey, "hold")
+	}
+
+	if err := s.checkHotkeyConflict(config.Preset{ID: "chat", Hotkey: "f9", Enabled: true, AppMatch: []string{"slack"}}); err != nil {
+		t.Errorf("app-scoped preset sharing a hotkey: %v", err)
+	}
+	if err := s.checkHotkeyConflict(config.Preset{ID: "other", Hotkey: "f9", Enabled: true}); err == nil {
+		t.Error("second catch-all preset on the same hotkey should conflict")
+	}
+	if err := s.checkHotkeyConflict(config.Preset{ID: "chat", Hotkey: "ctrl+f9", Enabled: true, AppMatch: []string{"slack"}}); err == nil {
+		t.Error("superset combo should conflict even when app-scoped")
+	}
+}
+
+func TestPickSiblingOncePerPress(t *testing.T) {
+	const f9 = 0x78
+	focus := []string{"code", "firefox"}
+	lookups := 0
+	old := focusedApp
+	focusedApp = func() []string {
+		app := focus[lookups%len(focus)] // focus moves on every lookup
+		lookups++
+		return []string{app}
+	}
+	t.Cleanup(func() { focusedApp = old })
+
+	siblings := []config.Preset{
+		{ID: "default", Hotkey: "f9", Enabled: true},
+		{ID: "editor", Hotkey: "f9", Enabled: true, AppMatch: []string{"code"}},
+	}
+	s := &PresetService{hotkeys: NewHotkeyManager(nil, nil)}
+	for _, p := range siblings {
+		_ = s.hotkeys.Register(p.ID, p.Hotkey, "hold")
+	}
+	pressed := map[uint16]bool{f9: true}
+	s.hotkeys.handleKeyDown(f9, pressed)
+
+	if a, b := s.pickSibling("editor", siblings), s.pickSibling("default", siblings); a != "editor" || b != "editor" {
+		t.Errorf("siblings picked %q and %q for one press, want editor for both", a, b)
+	}
+	if lookups != 1 {
+		t.Errorf("focused app looked up %d times for one press, want 1", lookups)
+	}
+
+	// The next press looks again (focus is now on firefox).
+	delete(pressed, f9)
+	s.hotkeys.handleKeyUp(f9, pressed)
+	pressed[f9] = true
+	s.hotkeys.handleKeyDown(f9, pressed)
+	if got := s.pickSibling("default", siblings); got != "default" || lookups != 2 {
+		t.Errorf("next press picked %q after %d lookups, want default after 2", got, lookups)
+	}
+}
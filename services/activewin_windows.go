@@ -0,0 +1,53 @@
+//go:build windows
+
+package services
+
+import (
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// procGetForegroundWindow is defined in overlay_windows.go.
+var (
+	procGetClassNameW              = user32.NewProc("GetClassNameW")
+	procGetWindowThreadProcessId   = user32.NewProc("GetWindowThreadProcessId")
+	procOpenProcess                = kern32.NewProc("OpenProcess")
+	procCloseHandle                = kern32.NewProc("CloseHandle")
+	procQueryFullProcessImageNameW = kern32.NewProc("QueryFullProcessImageNameW")
+)
+
+const processQueryLimitedInformation = 0x1000
+
+// winActiveWindow returns the foreground window's class name and the base
+// name of its process executable (e.g. "Chrome_WidgetWin_1", "code.exe").
+func winActiveWindow() []string {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return nil
+	}
+
+	var ids []string
+	buf := make([]uint16, 256)
+	if n, _, _ := procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf))); n > 0 {
+		ids = append(ids, syscall.UTF16ToString(buf[:n]))
+	}
+
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid == 0 {
+		return ids
+	}
+	h, _, _ := procOpenProcess.Call(processQueryLimitedInformation, 0, uintptr(pid))
+	if h == 0 {
+		return ids
+	}
+	defer procCloseHandle.Call(h)
+
+	path := make([]uint16, syscall.MAX_PATH)
+	size := uint32(len(path))
+	if ok, _, _ := procQueryFullProcessImageNameW.Call(h, 0, uintptr(unsafe.Pointer(&path[0])), uintptr(unsafe.Pointer(&size))); ok != 0 {
+		ids = append(ids, filepath.Base(syscall.UTF16ToString(path[:size])))
+	}
+	return ids
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/gen2brain/malgo"
@@ -12,16 +16,51 @@ import (
 const (
 	sampleRate = 16000
 	channels   = 1
+
+	// levelInterval throttles input level callbacks so the event bus isn't flooded.
+	levelInterval = 50 * time.Millisecond
+
+	// noiseGateFrame is the window (20ms) over which applyNoiseGate measures RMS.
+	noiseGateFrame = sampleRate / 50
 )
 
+// captureDevice is a started input stream: a malgo device in production,
+// faked in tests. Stop must not return while a data callback is still running.
+type captureDevice interface {
+	Stop() error
+	Uninit()
+	SampleRate() uint32 // rate the frames are delivered at; 0 = unknown
+}
+
+// openCaptureFunc opens and starts a capture device on micID ("" = default)
+// that delivers mono float32 frames to onFrames on the audio thread.
+type openCaptureFunc func(micID string, onFrames func(frames []float32)) (captureDevice, error)
+
+// errAudioClosed is returned by Start after Close.
+var errAudioClosed = errors.New("audio capture closed")
+
 // AudioCapture records audio from a microphone using malgo (miniaudio).
+//
+// Locking: life serializes Start/Stop/Close and is held across the device
+// calls; mu guards the buffer and is the only lock the audio callback takes.
+// life is always taken before mu, and mu is never held across device.Stop —
+// miniaudio waits there for a running callback, which may be waiting for mu.
 type AudioCapture struct {
+	life    sync.Mutex
 	mu      sync.Mutex
-	device  *malgo.Device
+	open    openCaptureFunc
+	device  captureDevice // guarded by life
 	ctx     *malgo.AllocatedContext
+	closed  bool
 	samples []float32
+	rate    int // sample rate of samples (the device's actual rate)
 	active  bool
 	micID   string // hex-encoded DeviceID, empty = default
+
+	// Input level metering (peak since last report, 0..1)
+	onLevel   func(level float32)
+	peak      float32
+	lastLevel time.Time
 }
 
 // NewAudioCapture creates a new audio capture instance.
@@ -30,28 +69,63 @@ func NewAudioCapture() (*AudioCapture, error) {
 	if err != nil {
 		return nil, fmt.Errorf("malgo init context: %w", err)
 	}
-	return &AudioCapture{ctx: ctx}, nil
+	a := &AudioCapture{ctx: ctx}
+	a.open = a.openMalgo
+	return a, nil
 }
 
 // Start begins recording audio from the microphone.
 func (a *AudioCapture) Start() error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	a.life.Lock()
+	defer a.life.Unlock()
 
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return errAudioClosed
+	}
 	if a.active {
+		a.mu.Unlock()
 		return nil
 	}
-
 	a.samples = a.samples[:0]
+	a.rate = sampleRate
+	a.peak = 0
+	a.lastLevel = time.Time{}
+	a.active = true
+	micID := a.micID
+	a.mu.Unlock()
+
+	device, err := a.open(micID, a.onFrames)
+	if err != nil {
+		a.mu.Lock()
+		a.active = false
+		a.mu.Unlock()
+		return err
+	}
+	a.device = device
+
+	// Some drivers ignore the requested rate; Stop and Drain resample then.
+	if rate := int(device.SampleRate()); rate > 0 && rate != sampleRate {
+		log.Printf("Capture device runs at %d Hz, resampling to %d Hz", rate, sampleRate)
+		a.mu.Lock()
+		a.rate = rate
+		a.mu.Unlock()
+	}
+	return nil
+}
 
+// openMalgo opens and starts a miniaudio capture device on a.ctx.
+// Must be called with a.life held.
+func (a *AudioCapture) openMalgo(micID string, onFrames func(frames []float32)) (captureDevice, error) {
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
 	deviceConfig.Capture.Format = malgo.FormatF32
 	deviceConfig.Capture.Channels = channels
 	deviceConfig.SampleRate = sampleRate
 
 	// Set specific device if configured
-	if a.micID != "" {
-		if idBytes, err := hex.DecodeString(a.micID); err == nil {
+	if micID != "" {
+		if idBytes, err := hex.DecodeString(micID); err == nil {
 			var devID malgo.DeviceID
 			copy((*[unsafe.Sizeof(devID)]byte)(unsafe.Pointer(&devID))[:], idBytes)
 			deviceConfig.Capture.DeviceID = devID.Pointer()
@@ -59,20 +133,15 @@ func (a *AudioCapture) Start() error {
 	}
 
 	onRecvFrames := func(outputSamples, inputSamples []byte, frameCount uint32) {
-		a.mu.Lock()
-		defer a.mu.Unlock()
-
-		if !a.active {
-			return
-		}
-
 		// Convert bytes to float32 slice (4 bytes per sample)
 		count := int(frameCount) * channels
 		if count*4 > len(inputSamples) {
 			count = len(inputSamples) / 4
 		}
-		floats := unsafe.Slice((*float32)(unsafe.Pointer(&inputSamples[0])), count)
-		a.samples = append(a.samples, floats...)
+		if count == 0 {
+			return
+		}
+		onFrames(unsafe.Slice((*float32)(unsafe.Pointer(&inputSamples[0])), count))
 	}
 
 	callbacks := malgo.DeviceCallbacks{
@@ -81,39 +150,195 @@ func (a *AudioCapture) Start() error {
 
 	device, err := malgo.InitDevice(a.ctx.Context, deviceConfig, callbacks)
 	if err != nil {
-		return fmt.Errorf("malgo init device: %w", err)
+		return nil, fmt.Errorf("malgo init device: %w", err)
 	}
 
 	if err := device.Start(); err != nil {
 		device.Uninit()
-		return fmt.Errorf("malgo start device: %w", err)
+		return nil, fmt.Errorf("malgo start device: %w", err)
 	}
+	return device, nil
+}
 
-	a.device = device
-	a.active = true
-	return nil
+// onFrames appends captured frames; it runs on the audio thread. frames is
+// only valid during the call.
+func (a *AudioCapture) onFrames(frames []float32) {
+	a.mu.Lock()
+
+	if !a.active {
+		a.mu.Unlock()
+		return
+	}
+	a.samples = append(a.samples, frames...)
+
+	// Track peak level and report it at most every levelInterval.
+	var report func(float32)
+	var level float32
+	if a.onLevel != nil {
+		if p := peakLevel(frames); p > a.peak {
+			a.peak = p
+		}
+		if now := time.Now(); now.Sub(a.lastLevel) >= levelInterval {
+			report, level = a.onLevel, a.peak
+			a.peak = 0
+			a.lastLevel = now
+		}
+	}
+	a.mu.Unlock()
+
+	// Called outside the lock: the callback emits a Wails event.
+	if report != nil {
+		report(level)
+	}
 }
 
 // Stop ends recording and returns captured PCM samples (16 kHz, mono, float32).
 func (a *AudioCapture) Stop() []float32 {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	a.life.Lock()
+	defer a.life.Unlock()
 
+	a.mu.Lock()
 	if !a.active {
+		a.mu.Unlock()
 		return nil
 	}
-
+	// From here on the callback drops frames, so the buffer is final.
 	a.active = false
+	result := make([]float32, len(a.samples))
+	copy(result, a.samples)
+	a.samples = a.samples[:0]
+	rate := a.rate
+	a.mu.Unlock()
+
+	a.stopDevice()
+	return resampleTo16k(result, rate)
+}
+
+// stopDevice stops and frees the device. Must be called with a.life held and
+// a.mu not held.
+func (a *AudioCapture) stopDevice() {
 	if a.device != nil {
-		a.device.Stop()
+		_ = a.device.Stop()
 		a.device.Uninit()
 		a.device = nil
 	}
+}
 
+// Drain returns the samples captured so far (16 kHz) and clears the buffer
+// without stopping the device, so recording continues with no gap.
+func (a *AudioCapture) Drain() []float32 {
+	a.mu.Lock()
 	result := make([]float32, len(a.samples))
 	copy(result, a.samples)
 	a.samples = a.samples[:0]
-	return result
+	rate := a.rate
+	a.mu.Unlock()
+
+	return resampleTo16k(result, rate)
+}
+
+// SetOnLevel sets the callback receiving the input peak level (0..1) while recording.
+// It runs on the audio thread, throttled to levelInterval, and must return quickly.
+func (a *AudioCapture) SetOnLevel(fn func(level float32)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onLevel = fn
+}
+
+// rmsLevel returns the root mean square of samples, 0 for none.
+func rmsLevel(samples []float32) float32 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += float64(v) * float64(v)
+	}
+	return float32(math.Sqrt(sum / float64(len(samples))))
+}
+
+// peakLevel returns the absolute peak of samples, clamped to 1.
+func peakLevel(samples []float32) float32 {
+	var peak float32
+	for _, v := range samples {
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak > 1 {
+		peak = 1
+	}
+	return peak
+}
+
+// normalizePCM returns samples scaled so their peak reaches targetPeak, with
+// the gain capped at maxNormalizeGain and the result clamped to [-1, 1].
+// Silence (peak below silentPeak) and audio already at or above the target
+// are returned unchanged, so noise is never amplified into hallucinations.
+// The input is not modified.
+func normalizePCM(samples []float32, targetPeak float32) []float32 {
+	peak := peakLevel(samples)
+	if peak < silentPeak || peak >= targetPeak {
+		return samples
+	}
+	gain := min(targetPeak/peak, maxNormalizeGain)
+	out := make([]float32, len(samples))
+	for i, v := range samples {
+		out[i] = max(-1, min(1, v*gain))
+	}
+	return out
+}
+
+// applyNoiseGate returns samples with every noiseGateFrame window whose RMS is
+// below thresholdDb (dBFS, e.g. -45) zeroed, so steady fan or keyboard noise
+// between words reaches whisper as silence. Louder frames pass unchanged. A
+// threshold of 0 or above turns the gate off. The input is not modified.
+func applyNoiseGate(samples []float32, thresholdDb float64) []float32 {
+	if thresholdDb >= 0 {
+		return samples
+	}
+	threshold := float32(math.Pow(10, thresholdDb/20))
+	out := make([]float32, len(samples))
+	for start := 0; start < len(samples); start += noiseGateFrame {
+		frame := samples[start:min(start+noiseGateFrame, len(samples))]
+		if rmsLevel(frame) >= threshold {
+			copy(out[start:], frame)
+		}
+	}
+	return out
+}
+
+// resampleTo16k converts mono samples captured at srcRate to sampleRate.
+// Downsampling (48 kHz, 44.1 kHz) averages the source samples around each
+// output sample, a box filter that keeps most aliasing out of the speech
+// band; upsampling interpolates linearly. Samples already at sampleRate, or
+// of an unknown rate (0), are returned as is.
+func resampleTo16k(samples []float32, srcRate int) []float32 {
+	if srcRate <= 0 || srcRate == sampleRate || len(samples) == 0 {
+		return samples
+	}
+	ratio := float64(srcRate) / sampleRate
+	out := make([]float32, int64(len(samples))*sampleRate/int64(srcRate))
+	for i := range out {
+		pos := float64(i) * ratio
+		if ratio > 1 {
+			lo := max(int(math.Ceil(pos-ratio/2)), 0)
+			hi := min(int(math.Ceil(pos+ratio/2)), len(samples))
+			var sum float32
+			for _, v := range samples[lo:hi] {
+				sum += v
+			}
+			out[i] = sum / float32(hi-lo)
+			continue
+		}
+		j := int(pos)
+		next := samples[min(j+1, len(samples)-1)]
+		out[i] = samples[j] + (next-samples[j])*float32(pos-float64(j))
+	}
+	return out
 }
 
 // SetMicrophoneID sets the device to use for next recording.
@@ -123,16 +348,18 @@ func (a *AudioCapture) SetMicrophoneID(id string) {
 	a.micID = id
 }
 
-// Close releases the malgo context.
+// Close stops any recording and releases the malgo context. The device is
+// stopped (waiting out a running callback) and freed before the context.
 func (a *AudioCapture) Close() {
+	a.life.Lock()
+	defer a.life.Unlock()
+
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	a.active = false
+	a.closed = true
+	a.mu.Unlock()
 
-	if a.device != nil {
-		a.device.Stop()
-		a.device.Uninit()
-		a.device = nil
-	}
+	a.stopDevice()
 	if a.ctx != nil {
 		_ = a.ctx.Uninit()
 		a.ctx.Free()
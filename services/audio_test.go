@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPeakLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float32
+		want    float32
+	}{
+		{"empty", nil, 0},
+		{"silence", []float32{0, 0, 0}, 0},
+		{"positive peak", []float32{0.1, 0.5, -0.2}, 0.5},
+		{"negative peak", []float32{0.1, -0.75, 0.3}, 0.75},
+		{"clipped", []float32{1.5, -0.2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := peakLevel(tt.samples); got != tt.want {
+				t.Errorf("peakLevel(%v) = %v, want %v", tt.samples, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePCM(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float32
+		want    []float32
+	}{
+		{"boosted to target", []float32{0.1, -0.4, 0.2}, []float32{0.2, -0.8, 0.4}},
+		{"gain capped", []float32{0.01, -0.005}, []float32{0.01 * maxNormalizeGain, -0.005 * maxNormalizeGain}},
+		{"silence untouched", []float32{0.001, -0.002}, []float32{0.001, -0.002}},
+		{"loud untouched", []float32{0.5, -0.9}, []float32{0.5, -0.9}},
+		{"empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append([]float32(nil), tt.samples...)
+			got := normalizePCM(in, 0.8)
+			if len(got) != len(tt.want) {
+				t.Fatalf("normalizePCM(%v) = %v, want %v", tt.samples, got, tt.want)
+			}
+			for i := range got {
+				if d := got[i] - tt.want[i]; d > 1e-6 || d < -1e-6 || got[i] > 1 || got[i] < -1 {
+					t.Errorf("normalizePCM(%v) = %v, want %v", tt.samples, got, tt.want)
+					break
+				}
+			}
+			for i := range in {
+				if in[i] != tt.samples[i] {
+					t.Errorf("normalizePCM modified its input: %v", in)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestApplyNoiseGate(t *testing.T) {
+	// Three frames: hiss at about -60 dBFS, speech at about -12 dBFS, hiss.
+	fill := func(amp float32) []float32 {
+		frame := make([]float32, noiseGateFrame)
+		for i := range frame {
+			if i%2 == 0 {
+				frame[i] = amp
+			} else {
+				frame[i] = -amp
+			}
+		}
+		return frame
+	}
+	hiss, speech := fill(0.001), fill(0.25)
+	in := append(append(append([]float32(nil), hiss...), speech...), hiss[:noiseGateFrame/2]...)
+
+	got := applyNoiseGate(in, -45)
+	if len(got) != len(in) {
+		t.Fatalf("applyNoiseGate returned %d samples, want %d", len(got), len(in))
+	}
+	for i, v := range got {
+		want := float32(0)
+		if i >= noiseGateFrame && i < 2*noiseGateFrame {
+			want = in[i] // speech passes unchanged
+		}
+		if v != want {
+			t.Fatalf("sample %d = %v, want %v", i, v, want)
+		}
+	}
+	if in[0] != 0.001 {
+		t.Error("applyNoiseGate modified its input")
+	}
+
+	if got := applyNoiseGate(in, 0); &got[0] != &in[0] {
+		t.Error("threshold 0 should leave the samples alone")
+	}
+}
+
+func TestResampleTo16k(t *testing.T) {
+	sine := func(rate, n int) []float32 {
+		out := make([]float32, n)
+		for i := range out {
+			out[i] = float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/float64(rate)))
+		}
+		return out
+	}
+	want := sine(sampleRate, sampleRate)
+
+	for _, rate := range []int{48000, 44100, 8000} {
+		t.Run(strconv.Itoa(rate), func(t *testing.T) {
+			got := resampleTo16k(sine(rate, rate), rate) // one second
+			if len(got) != sampleRate {
+				t.Fatalf("resampleTo16k from %d Hz: %d samples, want %d", rate, len(got), sampleRate)
+			}
+			// The last sample of an upsampled buffer has nothing to interpolate towards.
+			for i := range got[:len(got)-1] {
+				if d := math.Abs(float64(got[i] - want[i])); d > 0.03 {
+					t.Fatalf("sample %d = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+
+	in := []float32{0.1, 0.2}
+	if got := resampleTo16k(in, sampleRate); &got[0] != &in[0] {
+		t.Error("16 kHz input should be returned as is")
+	}
+}
+
+func TestMicTestDuration(t *testing.T) {
+	tests := map[int]time.Duration{
+		0:  2 * time.Second,
+		-5: 2 * time.Second,
+		1:  time.Second,
+		5:  5 * time.Second,
+		60: 10 * time.Second,
+	}
+	for seconds, want := range tests {
+		if got := micTestDuration(seconds); got != want {
+			t.Errorf("micTestDuration(%d) = %v, want %v", seconds, got, want)
+		}
+	}
+}
+
+// fakeCapture is a captureDevice pumping frames from its own goroutine, like
+// miniaudio's audio thread: Stop waits for a running callback to return.
+type fakeCapture struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopped  atomic.Bool
+	uninited atomic.Bool
+	misuse   *atomic.Int32 // callbacks after Uninit, Uninit before Stop
+}
+
+func (f *fakeCapture) Stop() error {
+	if f.stopped.CompareAndSwap(false, true) {
+		close(f.stop)
+		<-f.done
+	}
+	return nil
+}
+
+func (f *fakeCapture) Uninit() {
+	if !f.stopped.Load() {
+		f.misuse.Add(1)
+	}
+	f.uninited.Store(true)
+}
+
+func (f *fakeCapture) SampleRate() uint32 { return sampleRate }
+
+func TestAudioCaptureLifecycleStress(t *testing.T) {
+	var misuse, open atomic.Int32
+	a := &AudioCapture{}
+	a.open = func(_ string, onFrames func([]float32)) (captureDevice, error) {
+		f := &fakeCapture{stop: make(chan struct{}), done: make(chan struct{}), misuse: &misuse}
+		open.Add(1)
+		go func() {
+			defer close(f.done)
+			defer open.Add(-1)
+			frames := []float32{0.1, -0.2, 0.3}
+			for {
+				select {
+				case <-f.stop:
+					return
+				default:
+				}
+				if f.uninited.Load() {
+					misuse.Add(1)
+				}
+				onFrames(frames)
+			}
+		}()
+		return f, nil
+	}
+	a.SetOnLevel(func(float32) {})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				switch (g + i) % 3 {
+				case 0:
+					if err := a.Start(); err != nil && !errors.Is(err, errAudioClosed) {
+						t.Errorf("Start: %v", err)
+					}
+				case 1:
+					a.Stop()
+				case 2:
+					a.Drain()
+				}
+			}
+		}(g)
+	}
+	// Close while the others are still toggling.
+	time.Sleep(time.Millisecond)
+	a.Close()
+
+	finished := make(chan struct{})
+	go func() { wg.Wait(); close(finished) }()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("start/stop/close deadlocked")
+	}
+
+	if n := misuse.Load(); n != 0 {
+		t.Errorf("%d callbacks after Uninit or devices freed before Stop", n)
+	}
+	if n := open.Load(); n != 0 {
+		t.Errorf("%d devices still running after Close", n)
+	}
+	if err := a.Start(); !errors.Is(err, errAudioClosed) {
+		t.Errorf("Start after Close = %v, want errAudioClosed", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/UberMorgott/transcribation/internal/config"
 )
 
 // Minimum driver versions for GPU backends.
@@ -47,6 +49,7 @@ type gpuDetection struct {
 	HasAMD          bool
 	AMDModel        string // "AMD Radeon RX 7900", ""
 	ROCmAvailable   bool
+	OpenCLAvailable bool
 	PackageManager  string // "pacman", "apt", "dnf", "zypper", ""
 	GPUs            []gpuInfo
 }
@@ -99,20 +102,17 @@ func GetAllBackends() []BackendInfo {
 		{ID: "auto", Name: "Auto", Compiled: true, SystemAvailable: true},
 		{ID: "cpu", Name: "CPU", Compiled: true, SystemAvailable: true},
 		cudaBackend(det),
+		rocmBackend(det),
 		vulkanBackend(det),
+		openclBackend(det),
 		metalBackend(det),
 	}
 
-	// Mark recommended backend based on detected hardware.
-	var recID string
-	switch {
-	case runtime.GOOS == "darwin":
-		recID = "metal"
-	case det.HasNVIDIA:
-		recID = "cuda"
-	case det.VulkanAvailable:
-		recID = "vulkan"
+	fastest := ""
+	if cfg, err := config.Load(); err == nil {
+		fastest = cfg.FastestBackend
 	}
+	recID := recommendedBackend(det, backends, fastest)
 	for i := range backends {
 		if backends[i].ID == recID {
 			backends[i].Recommended = true
@@ -126,6 +126,30 @@ func GetAllBackends() []BackendInfo {
 	return backends
 }
 
+// recommendedBackend picks the backend to mark Recommended: the benchmark
+// winner (BenchmarkBackends) while it is still compiled and available,
+// otherwise the best guess from the detected hardware.
+func recommendedBackend(det gpuDetection, backends []BackendInfo, fastest string) string {
+	for _, b := range backends {
+		if fastest != "" && b.ID == fastest && b.Compiled && b.SystemAvailable {
+			return fastest
+		}
+	}
+	switch {
+	case runtime.GOOS == "darwin":
+		return "metal"
+	case det.HasNVIDIA:
+		return "cuda"
+	case det.HasAMD && runtime.GOOS == "linux":
+		return "rocm"
+	case det.VulkanAvailable:
+		return "vulkan"
+	case det.OpenCLAvailable:
+		return "opencl"
+	}
+	return ""
+}
+
 func cudaBackend(det gpuDetection) BackendInfo {
 	hasDLL := backendDLLExists("cuda")
 	info := BackendInfo{
@@ -177,6 +201,48 @@ func cudaBackend(det gpuDetection) BackendInfo {
 	return info
 }
 
+// rocmBackend mirrors cudaBackend for AMD GPUs via ROCm/HIP. Backend libraries
+// are only built for Linux, where the runtime comes from the package manager.
+func rocmBackend(det gpuDetection) BackendInfo {
+	hasDLL := backendDLLExists("rocm")
+	info := BackendInfo{
+		ID: "rocm", Name: "ROCm",
+		Compiled: hasDLL,
+	}
+
+	if !det.HasAMD {
+		info.UnavailableReason = "no_hardware"
+		return info
+	}
+
+	if names := gpuNamesByVendor(det, "amd"); names != "" {
+		info.GPUDetected = names
+	} else {
+		info.GPUDetected = det.AMDModel
+	}
+	if driverVer := gpuDriverByVendor(det, "amd"); driverVer != "" {
+		info.DriverVersion = driverVer
+		info.DriverOK = true
+	}
+
+	if !det.ROCmAvailable {
+		info.UnavailableReason = "no_runtime"
+		info.CanInstall = runtime.GOOS == "linux"
+		info.InstallHint = "ROCm HIP Runtime"
+		return info
+	}
+
+	// Runtime is present on the system.
+	info.RuntimeInstalled = true
+	info.SystemAvailable = true
+	if !hasDLL {
+		info.UnavailableReason = "not_compiled"
+		info.CanInstall = true
+	}
+
+	return info
+}
+
 func vulkanBackend(det gpuDetection) BackendInfo {
 	hasDLL := backendDLLExists("vulkan")
 	info := BackendInfo{
@@ -240,6 +306,47 @@ func vulkanBackend(det gpuDetection) BackendInfo {
 	return info
 }
 
+func openclBackend(det gpuDetection) BackendInfo {
+	hasDLL := backendDLLExists("opencl")
+	info := BackendInfo{
+		ID: "opencl", Name: "OpenCL",
+		Compiled: hasDLL,
+	}
+
+	// Populate GPU info — OpenCL works with any vendor.
+	var gpuNames []string
+	for _, g := range det.GPUs {
+		if g.Name != "" {
+			gpuNames = append(gpuNames, g.Name)
+		}
+		if g.DriverVersion != "" && info.DriverVersion == "" {
+			info.DriverVersion = g.DriverVersion
+			info.DriverOK = true
+		}
+	}
+	if len(gpuNames) > 0 {
+		info.GPUDetected = strings.Join(gpuNames, ", ")
+	}
+
+	if !det.OpenCLAvailable {
+		// The ICD loader is a package on Linux; elsewhere it ships with the GPU driver.
+		info.UnavailableReason = "no_runtime"
+		info.CanInstall = runtime.GOOS == "linux"
+		info.InstallHint = "OpenCL ICD Loader"
+		return info
+	}
+
+	// Runtime is present on the system.
+	info.RuntimeInstalled = true
+	info.SystemAvailable = true
+	if !hasDLL {
+		info.UnavailableReason = "not_compiled"
+		info.CanInstall = true
+	}
+
+	return info
+}
+
 func metalBackend(det gpuDetection) BackendInfo {
 	hasDLL := backendDLLExists("metal")
 	available := runtime.GOOS == "darwin" && hasDLL
@@ -299,10 +406,48 @@ func gpuNamesByVendor(det gpuDetection, vendor string) string {
 	return strings.Join(names, ", ")
 }
 
+// GPUDevice is a GPU device of a loaded ggml backend, as offered for the
+// GPUDeviceIndex setting. A card usable by several backends (CUDA and
+// Vulkan) is listed once per backend.
+type GPUDevice struct {
+	Index   int    `json:"index"` // whisper's gpu_device
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`  // "nvidia", "amd", "intel", "unknown"
+	Backend string `json:"backend"` // ggml backend, e.g. "CUDA", "Vulkan"
+}
+
+// gpuDevices lists devs in the order whisper counts them for gpu_device.
+func gpuDevices(devs []ggmlDevice) []GPUDevice {
+	devices := make([]GPUDevice, 0, len(devs))
+	for _, d := range devs {
+		name := d.Description
+		if name == "" {
+			name = d.Name
+		}
+		devices = append(devices, GPUDevice{Index: d.Index, Name: name, Vendor: gpuVendor(name), Backend: d.Backend})
+	}
+	return devices
+}
+
+// gpuVendor guesses the vendor from a device name.
+func gpuVendor(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "nvidia") || strings.Contains(lower, "geforce"):
+		return "nvidia"
+	case strings.Contains(lower, "amd") || strings.Contains(lower, "radeon"):
+		return "amd"
+	case strings.Contains(lower, "intel"):
+		return "intel"
+	}
+	return "unknown"
+}
+
 func backendDownloadSize(id string) int {
 	sizes := map[string]map[string]int{
 		"cuda":   {"windows": 150, "linux": 200},
 		"vulkan": {"windows": 57, "linux": 70},
+		"opencl": {"windows": 5, "linux": 5, "darwin": 5},
 		"metal":  {"darwin": 5},
 	}
 	if m, ok := sizes[id]; ok {
@@ -31,6 +31,8 @@ func detectGPU() gpuDetection {
 	// ROCm is not available on macOS
 	det.ROCmAvailable = false
 
+	// OpenCL framework ships with macOS (deprecated but still present)
+	det.OpenCLAvailable = fileExists("/System/Library/Frameworks/OpenCL.framework")
 
 	return det
 }
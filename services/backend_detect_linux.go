@@ -17,6 +17,8 @@ func detectGPU() gpuDetection {
 		lspciOut = string(out)
 	}
 
+	det.GPUs = lspciGPUs(lspciOut)
+
 	// Detect NVIDIA GPU
 	if _, err := os.Stat("/proc/driver/nvidia/version"); err == nil {
 		det.HasNVIDIA = true
@@ -59,6 +61,8 @@ func detectGPU() gpuDetection {
 		det.ROCmAvailable = ldconfigHas("libamdhip64.so") || fileExists("/opt/rocm/lib/libamdhip64.so")
 	}
 
+	// Detect OpenCL ICD loader
+	det.OpenCLAvailable = ldconfigHas("libOpenCL.so")
 
 	// Detect package manager
 	det.PackageManager = detectPackageManager()
@@ -66,6 +70,52 @@ func detectGPU() gpuDetection {
 	return det
 }
 
+// lspciGPUs lists every display controller in lspciOut (discrete and
+// integrated), in bus order. Driver versions are not known here.
+func lspciGPUs(lspciOut string) []gpuInfo {
+	var gpus []gpuInfo
+	for _, line := range strings.Split(lspciOut, "\n") {
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, "vga") && !strings.Contains(lower, "3d controller") && !strings.Contains(lower, "display controller") {
+			continue
+		}
+		vendor, brand := "unknown", ""
+		switch {
+		case strings.Contains(lower, "nvidia"):
+			vendor, brand = "nvidia", "NVIDIA"
+		case strings.Contains(lower, "amd") || strings.Contains(lower, "radeon"):
+			vendor, brand = "amd", "AMD"
+		case strings.Contains(lower, "intel"):
+			vendor, brand = "intel", "Intel"
+		}
+		gpus = append(gpus, gpuInfo{Name: lspciGPUName(line, brand), Vendor: vendor})
+	}
+	return gpus
+}
+
+// lspciGPUName returns the marketing name lspci shows in the last brackets
+// ("GA104 [GeForce RTX 3070]" → "NVIDIA GeForce RTX 3070"), else the whole
+// device description.
+func lspciGPUName(line, brand string) string {
+	desc := line
+	if i := strings.LastIndex(desc, ": "); i >= 0 {
+		desc = desc[i+2:]
+	}
+	if i := strings.LastIndex(desc, " (rev "); i >= 0 {
+		desc = desc[:i]
+	}
+	desc = strings.TrimSpace(desc)
+	open, end := strings.LastIndex(desc, "["), strings.LastIndex(desc, "]")
+	if open < 0 || end < open {
+		return desc
+	}
+	name := desc[open+1 : end]
+	if brand != "" && !strings.HasPrefix(name, brand) {
+		name = brand + " " + name
+	}
+	return name
+}
+
 // extractGPUModel parses GPU model name from lspci output line
 // e.g. "01:00.0 VGA compatible controller: NVIDIA Corporation: Device 2503 (rev a1)"
 // returns "NVIDIA RTX 5070 Ti" or similar descriptive name
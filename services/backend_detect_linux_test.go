@@ -0,0 +1,32 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLspciGPUs(t *testing.T) {
+	const out = `00:00.0 Host bridge: Intel Corporation Device a700 (rev 01)
+00:02.0 VGA compatible controller: Intel Corporation Raptor Lake-S GT1 [UHD Graphics 770] (rev 04)
+01:00.0 VGA compatible controller: NVIDIA Corporation GA104 [GeForce RTX 3070] (rev a1)
+01:00.1 Audio device: NVIDIA Corporation GA104 High Definition Audio Controller (rev a1)
+02:00.0 3D controller: NVIDIA Corporation GA102GL [A10] (rev a1)
+03:00.0 Display controller: Advanced Micro Devices, Inc. [AMD/ATI] Navi 31 [Radeon RX 7900 XT/7900 XTX] (rev c8)
+`
+	want := []gpuInfo{
+		{Name: "Intel UHD Graphics 770", Vendor: "intel"},
+		{Name: "NVIDIA GeForce RTX 3070", Vendor: "nvidia"},
+		{Name: "NVIDIA A10", Vendor: "nvidia"},
+		{Name: "AMD Radeon RX 7900 XT/7900 XTX", Vendor: "amd"},
+	}
+	if got := lspciGPUs(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("lspciGPUs =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := lspciGPUs("05:00.0 VGA compatible controller: ASPEED Technology, Inc. ASPEED Graphics Family (rev 41)"); len(got) != 1 ||
+		got[0] != (gpuInfo{Name: "ASPEED Technology, Inc. ASPEED Graphics Family", Vendor: "unknown"}) {
+		t.Errorf("lspciGPUs(ASPEED) = %+v", got)
+	}
+	if got := lspciGPUs(""); got != nil {
+		t.Errorf("lspciGPUs(\"\") = %+v, want nil", got)
+	}
+}
@@ -42,6 +42,9 @@ func detectGPU() gpuDetection {
 	sys32 := filepath.Join(os.Getenv("SystemRoot"), "System32")
 	det.VulkanAvailable = fileExists(filepath.Join(sys32, "vulkan-1.dll"))
 
+	// OpenCL ICD loader (OpenCL.dll in system32, installed by GPU drivers)
+	det.OpenCLAvailable = fileExists(filepath.Join(sys32, "OpenCL.dll"))
+
 	// ROCm/HIP runtime
 	if det.HasAMD {
 		det.ROCmAvailable = os.Getenv("HIP_PATH") != ""
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
@@ -50,24 +52,167 @@ func backendDownloadURL(id string) string {
 	return fmt.Sprintf("%s/%s/%s", backendReleaseBase, backendReleaseTag, filename)
 }
 
+// InstallStage is a step of a backend install, sent as "stage" in
+// backend:install:progress. The frontend labels it with the i18n key
+// "install_stage_<stage>" (StageKey).
+type InstallStage string
+
+const (
+	StageDownloadingRuntime InstallStage = "downloading_runtime" // system runtime installer (CUDA on Windows)
+	StageInstallingRuntime  InstallStage = "installing_runtime"  // package manager or runtime installer
+	StageDownloading        InstallStage = "downloading"         // the GPU backend library
+	StageDone               InstallStage = "done"
+	StageFailed             InstallStage = "failed"
+	StageCancelled          InstallStage = "cancelled"
+)
+
+// StageKey returns the frontend i18n key for the stage label.
+func (s InstallStage) StageKey() string { return "install_stage_" + string(s) }
+
+// BackendInstallProgress is the payload of backend:install:progress.
+// StageIndex (1-based) and StageTotal place Stage among the working stages of
+// this install, for a stepper: a CUDA install on Windows without the runtime
+// has three, an install that only downloads the library has one. Done and
+// failed events keep the total; cancelled events carry no position.
+type BackendInstallProgress struct {
+	BackendID  string       `json:"backendId"`
+	Stage      InstallStage `json:"stage"`
+	StageKey   string       `json:"stageKey"`
+	StageIndex int          `json:"stageIndex"`
+	StageTotal int          `json:"stageTotal"`
+	StageText  string       `json:"stageText"` // untranslated detail, e.g. from the CUDA installer log
+	Percent    float64      `json:"percent"`
+	Done       bool         `json:"done"`
+	Error      string       `json:"error"`
+}
+
 // emitBackendProgress sends a backend:install:progress event to the frontend.
-func emitBackendProgress(backendID, stage, stageText string, pct float64, done bool, errMsg string) {
+func emitBackendProgress(p BackendInstallProgress) {
 	if app := application.Get(); app != nil {
-		app.Event.Emit("backend:install:progress", map[string]any{
-			"backendId": backendID,
-			"stage":     stage,
-			"stageText": stageText,
-			"percent":   pct,
-			"done":      done,
-			"error":     errMsg,
-		})
+		app.Event.Emit("backend:install:progress", p)
 	}
 }
 
+// installReporter reports the stages of one backend install. It goes quiet
+// once ctx is cancelled, as cancelBackendInstall sent the final event.
+type installReporter struct {
+	ctx    context.Context
+	id     string
+	stages []InstallStage // working stages of this install, in order
+
+	mu   sync.Mutex
+	step int // 1-based index of the current stage in stages
+}
+
+func newInstallReporter(ctx context.Context, id string, stages ...InstallStage) *installReporter {
+	return &installReporter{ctx: ctx, id: id, stages: stages}
+}
+
+// event builds the progress event for stage and makes a working stage the
+// current one.
+func (r *installReporter) event(stage InstallStage, text string, pct float64) BackendInstallProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, s := range r.stages {
+		if s == stage {
+			r.step = i + 1
+		}
+	}
+	p := BackendInstallProgress{
+		BackendID:  r.id,
+		Stage:      stage,
+		StageKey:   stage.StageKey(),
+		StageIndex: r.step,
+		StageTotal: len(r.stages),
+		StageText:  text,
+		Percent:    pct,
+	}
+	switch stage {
+	case StageDone:
+		p.StageIndex, p.Percent, p.Done = len(r.stages), 100, true
+	case StageFailed:
+		p.Done, p.Error = true, text
+		p.StageText = ""
+	}
+	return p
+}
+
+// progress reports a working stage; text is an optional detail line.
+func (r *installReporter) progress(stage InstallStage, text string, pct float64) {
+	if r.ctx.Err() == nil {
+		emitBackendProgress(r.event(stage, text, pct))
+	}
+}
+
+// fail ends the install with an error, at the stage that failed.
+func (r *installReporter) fail(msg string) { r.progress(StageFailed, msg, 0) }
+
+// done ends the install successfully.
+func (r *installReporter) done() { r.progress(StageDone, "", 100) }
+
+// backendInstalls holds the cancel functions of running backend installs, by
+// backend ID.
+var (
+	backendInstallMu sync.Mutex
+	backendInstalls  = map[string]context.CancelFunc{}
+)
+
+// startBackendInstall runs install in the background as the install of id.
+// Its context is cancelled by CancelBackendInstall. Fails if id is already
+// being installed (or a cancelled install is still stopping).
+func startBackendInstall(id string, install func(ctx context.Context)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	backendInstallMu.Lock()
+	if _, busy := backendInstalls[id]; busy {
+		backendInstallMu.Unlock()
+		cancel()
+		return fmt.Errorf("backend %s is already being installed", id)
+	}
+	backendInstalls[id] = cancel
+	backendInstallMu.Unlock()
+
+	go func() {
+		defer func() {
+			backendInstallMu.Lock()
+			delete(backendInstalls, id)
+			backendInstallMu.Unlock()
+			cancel()
+			if r := recover(); r != nil {
+				log.Printf("recovered panic in backend install (%s): %v", id, r)
+			}
+		}()
+		install(ctx)
+	}()
+	return nil
+}
+
+// cancelBackendInstall cancels the running install of id and emits its final
+// StageCancelled; false if id isn't being installed. A child process that
+// can't be killed (an elevated installer) runs on, but its outcome is no
+// longer reported or applied.
+func cancelBackendInstall(id string) bool {
+	backendInstallMu.Lock()
+	cancel, ok := backendInstalls[id]
+	backendInstallMu.Unlock()
+	if !ok {
+		return false
+	}
+	log.Printf("Backend %s: cancelling install", id)
+	cancel()
+	emitBackendProgress(BackendInstallProgress{
+		BackendID: id,
+		Stage:     StageCancelled,
+		StageKey:  StageCancelled.StageKey(),
+		Done:      true,
+	})
+	return true
+}
+
 // downloadBackendDLL downloads a GPU backend library from GitHub Releases
-// and places it next to the executable. Reports progress via events.
-// Supports HTTP Range resume if a partial .tmp file exists from a previous attempt.
-func downloadBackendDLL(backendID string) error {
+// and places it next to the executable, reporting the percentage done.
+// Supports HTTP Range resume if a partial .tmp file exists from a previous
+// attempt; cancelling ctx deletes it instead.
+func downloadBackendDLL(ctx context.Context, backendID string, onProgress func(pct float64)) error {
 	exe, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("cannot find executable path: %w", err)
@@ -85,7 +230,7 @@ func downloadBackendDLL(backendID string) error {
 		log.Printf("Backend %s: found partial download (%d bytes), attempting resume", backendID, resumeOffset)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
@@ -95,6 +240,10 @@ func downloadBackendDLL(backendID string) error {
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			os.Remove(tmpFile)
+			return ctx.Err()
+		}
 		return fmt.Errorf("download failed: %w", err)
 	}
 	defer resp.Body.Close()
@@ -141,10 +290,10 @@ func downloadBackendDLL(backendID string) error {
 				return wErr
 			}
 			loaded += int64(n)
-			if total > 0 {
+			if total > 0 && ctx.Err() == nil {
 				pct := float64(loaded) / float64(total) * 100
 				if pct-lastPct >= 1 || readErr == io.EOF {
-					emitBackendProgress(backendID, "downloading", "", pct, false, "")
+					onProgress(pct)
 					lastPct = pct
 				}
 			}
@@ -154,6 +303,11 @@ func downloadBackendDLL(backendID string) error {
 		}
 		if readErr != nil {
 			f.Close()
+			if ctx.Err() != nil {
+				os.Remove(tmpFile)
+				log.Printf("Backend %s: download cancelled at %d bytes", backendID, loaded)
+				return ctx.Err()
+			}
 			// Keep partial file for resume on network errors.
 			log.Printf("Backend %s: download interrupted at %d bytes: %v", backendID, loaded, readErr)
 			return readErr
@@ -3,22 +3,19 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os/exec"
 )
 
 func installBackend(id string) (string, error) {
 	switch id {
-	case "vulkan":
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("recovered panic in installBackendAsyncDarwin(%s): %v", id, r)
-				}
-			}()
-			installBackendAsyncDarwin(id)
-		}()
+	case "vulkan", "opencl":
+		if err := startBackendInstall(id, func(ctx context.Context) {
+			installBackendAsyncDarwin(ctx, id)
+		}); err != nil {
+			return "", err
+		}
 		return "installing", nil
 	case "metal":
 		// Metal is statically linked into the binary on macOS.
@@ -28,34 +25,40 @@ func installBackend(id string) (string, error) {
 	}
 }
 
-func installBackendAsyncDarwin(id string) {
-	emit := func(stage, stageText string, pct float64, done bool, errMsg string) {
-		emitBackendProgress(id, stage, stageText, pct, done, errMsg)
+func installBackendAsyncDarwin(ctx context.Context, id string) {
+	_, brewErr := exec.LookPath("brew")
+	withRuntime := id == "vulkan" && brewErr == nil
+	r := newInstallReporter(ctx, id, StageDownloading)
+	if withRuntime {
+		r = newInstallReporter(ctx, id, StageInstallingRuntime, StageDownloading)
 	}
 
 	// Step 1: Install Vulkan runtime (MoltenVK) via Homebrew if available.
-	if id == "vulkan" {
-		if _, err := exec.LookPath("brew"); err == nil {
-			emit("installing_runtime", "", 0, false, "")
-			cmd := exec.Command("brew", "install", "molten-vk")
-			if out, err := cmd.CombinedOutput(); err != nil {
-				emit("", "", 0, true, fmt.Sprintf("brew install failed: %s\n%s", err, string(out)))
-				return
-			}
+	if withRuntime {
+		r.progress(StageInstallingRuntime, "", 0)
+		cmd := exec.CommandContext(ctx, "brew", "install", "molten-vk")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			r.fail(fmt.Sprintf("brew install failed: %s\n%s", err, string(out)))
+			return
 		}
 	}
 
 	// Step 2: Download the backend library (.dylib).
-	emit("downloading", "", 0, false, "")
-	if err := downloadBackendDLL(id); err != nil {
-		emit("", "", 0, true, fmt.Sprintf("Backend download failed: %v", err))
+	r.progress(StageDownloading, "", 0)
+	if err := downloadBackendDLL(ctx, id, func(pct float64) {
+		r.progress(StageDownloading, "", pct)
+	}); err != nil {
+		r.fail(fmt.Sprintf("Backend download failed: %v", err))
 		return
 	}
 
-	// Step 3: Hot-apply.
+	// Step 3: Hot-apply, unless cancelled meanwhile.
+	if ctx.Err() != nil {
+		return
+	}
 	if onBackendInstalled != nil {
 		onBackendInstalled(id)
 	}
 
-	emit("", "", 100, true, "")
+	r.done()
 }
@@ -3,9 +3,9 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -13,52 +13,52 @@ import (
 )
 
 func installBackend(id string) (string, error) {
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("recovered panic in installBackendAsyncLinux(%s): %v", id, r)
-			}
-		}()
-		installBackendAsyncLinux(id)
-	}()
+	if err := startBackendInstall(id, func(ctx context.Context) {
+		installBackendAsyncLinux(ctx, id)
+	}); err != nil {
+		return "", err
+	}
 	return "installing", nil
 }
 
-func installBackendAsyncLinux(id string) {
-	emit := func(stage, stageText string, pct float64, done bool, errMsg string) {
-		emitBackendProgress(id, stage, stageText, pct, done, errMsg)
-	}
+func installBackendAsyncLinux(ctx context.Context, id string) {
+	r := newInstallReporter(ctx, id, StageInstallingRuntime, StageDownloading)
 
 	// Step 1: Install system runtime via package manager.
-	emit("installing_runtime", "", 0, false, "")
-	if err := installSystemRuntime(id); err != nil {
-		emit("", "", 0, true, err.Error())
+	r.progress(StageInstallingRuntime, "", 0)
+	if err := installSystemRuntime(ctx, id); err != nil {
+		r.fail(err.Error())
 		return
 	}
 
 	// Step 2: Download the backend library (.so).
-	emit("downloading", "", 0, false, "")
-	if err := downloadBackendDLL(id); err != nil {
-		emit("", "", 0, true, fmt.Sprintf("Backend download failed: %v", err))
+	r.progress(StageDownloading, "", 0)
+	if err := downloadBackendDLL(ctx, id, func(pct float64) {
+		r.progress(StageDownloading, "", pct)
+	}); err != nil {
+		r.fail(fmt.Sprintf("Backend download failed: %v", err))
 		return
 	}
 
-	// Step 3: Hot-apply.
+	// Step 3: Hot-apply, unless cancelled meanwhile.
+	if ctx.Err() != nil {
+		return
+	}
 	if onBackendInstalled != nil {
 		onBackendInstalled(id)
 	}
 
-	emit("", "", 100, true, "")
+	r.done()
 }
 
-func installSystemRuntime(id string) error {
+func installSystemRuntime(ctx context.Context, id string) error {
 	pm := detectPackageManager()
 	if pm == "" {
 		return fmt.Errorf("no supported package manager found")
 	}
 
 	if id == "cuda" && pm != "pacman" {
-		_, err := installCUDALinux(pm)
+		_, err := installCUDALinux(ctx, pm)
 		return err
 	}
 
@@ -69,7 +69,7 @@ func installSystemRuntime(id string) error {
 	}
 
 	args := installArgs(pm, packages)
-	cmd := exec.Command("pkexec", args...)
+	cmd := exec.CommandContext(ctx, "pkexec", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("install failed: %s\n%s", err, string(out))
@@ -79,7 +79,7 @@ func installSystemRuntime(id string) error {
 
 // installCUDALinux adds NVIDIA's official repo and installs cuda-toolkit meta-package.
 // See https://docs.nvidia.com/cuda/cuda-installation-guide-linux/#meta-packages
-func installCUDALinux(pm string) (string, error) {
+func installCUDALinux(ctx context.Context, pm string) (string, error) {
 	distroID, version := detectDistro()
 	slug := nvidiaRepoSlug(distroID, version)
 	if slug == "" {
@@ -89,11 +89,11 @@ func installCUDALinux(pm string) (string, error) {
 	var err error
 	switch pm {
 	case "apt":
-		err = installCUDADebian(slug)
+		err = installCUDADebian(ctx, slug)
 	case "dnf":
-		err = installCUDAFedora(distroID, slug)
+		err = installCUDAFedora(ctx, distroID, slug)
 	case "zypper":
-		err = installCUDAOpenSUSE(slug)
+		err = installCUDAOpenSUSE(ctx, slug)
 	default:
 		return "", fmt.Errorf("CUDA install not supported for package manager %q", pm)
 	}
@@ -157,24 +157,24 @@ func nvidiaRepoSlug(distroID, version string) string {
 const nvidiaRepoBase = "https://developer.download.nvidia.com/compute/cuda/repos/"
 
 // installCUDADebian adds NVIDIA keyring and installs cuda-toolkit on Debian/Ubuntu.
-func installCUDADebian(slug string) error {
+func installCUDADebian(ctx context.Context, slug string) error {
 	keyringURL := nvidiaRepoBase + slug + "/cuda-keyring_1.1-1_all.deb"
 	keyringPath := "/tmp/cuda-keyring.deb"
 
-	if err := downloadFile(keyringURL, keyringPath); err != nil {
+	if err := downloadFile(ctx, keyringURL, keyringPath); err != nil {
 		return fmt.Errorf("download keyring: %w", err)
 	}
 	defer os.Remove(keyringPath)
 
-	cmd := exec.Command("pkexec", "dpkg", "-i", keyringPath)
+	cmd := exec.CommandContext(ctx, "pkexec", "dpkg", "-i", keyringPath)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("dpkg install failed: %s\n%s", err, string(out))
 	}
-	cmd2 := exec.Command("pkexec", "apt-get", "update")
+	cmd2 := exec.CommandContext(ctx, "pkexec", "apt-get", "update")
 	if out, err := cmd2.CombinedOutput(); err != nil {
 		return fmt.Errorf("apt-get update failed: %s\n%s", err, string(out))
 	}
-	cmd3 := exec.Command("pkexec", "apt-get", "install", "-y", "cuda-toolkit")
+	cmd3 := exec.CommandContext(ctx, "pkexec", "apt-get", "install", "-y", "cuda-toolkit")
 	if out, err := cmd3.CombinedOutput(); err != nil {
 		return fmt.Errorf("apt-get install cuda-toolkit failed: %s\n%s", err, string(out))
 	}
@@ -182,14 +182,14 @@ func installCUDADebian(slug string) error {
 }
 
 // installCUDAFedora adds NVIDIA repo and installs cuda-toolkit on Fedora/RHEL.
-func installCUDAFedora(distroID, slug string) error {
+func installCUDAFedora(ctx context.Context, distroID, slug string) error {
 	repoURL := nvidiaRepoBase + slug + "/cuda-" + distroID + ".repo"
 
-	cmd := exec.Command("pkexec", "dnf", "config-manager", "--add-repo", repoURL)
+	cmd := exec.CommandContext(ctx, "pkexec", "dnf", "config-manager", "--add-repo", repoURL)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("dnf config-manager failed: %s\n%s", err, string(out))
 	}
-	cmd2 := exec.Command("pkexec", "dnf", "install", "-y", "cuda-toolkit")
+	cmd2 := exec.CommandContext(ctx, "pkexec", "dnf", "install", "-y", "cuda-toolkit")
 	if out, err := cmd2.CombinedOutput(); err != nil {
 		return fmt.Errorf("dnf install cuda-toolkit failed: %s\n%s", err, string(out))
 	}
@@ -197,27 +197,31 @@ func installCUDAFedora(distroID, slug string) error {
 }
 
 // installCUDAOpenSUSE adds NVIDIA repo and installs cuda-toolkit on openSUSE.
-func installCUDAOpenSUSE(slug string) error {
+func installCUDAOpenSUSE(ctx context.Context, slug string) error {
 	repoURL := nvidiaRepoBase + slug + "/"
 
-	cmd := exec.Command("pkexec", "zypper", "addrepo", "--refresh", repoURL, "cuda-repo")
+	cmd := exec.CommandContext(ctx, "pkexec", "zypper", "addrepo", "--refresh", repoURL, "cuda-repo")
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("zypper addrepo failed: %s\n%s", err, string(out))
 	}
-	cmd2 := exec.Command("pkexec", "zypper", "--gpg-auto-import-keys", "refresh")
+	cmd2 := exec.CommandContext(ctx, "pkexec", "zypper", "--gpg-auto-import-keys", "refresh")
 	if out, err := cmd2.CombinedOutput(); err != nil {
 		return fmt.Errorf("zypper refresh failed: %s\n%s", err, string(out))
 	}
-	cmd3 := exec.Command("pkexec", "zypper", "install", "-y", "cuda-toolkit")
+	cmd3 := exec.CommandContext(ctx, "pkexec", "zypper", "install", "-y", "cuda-toolkit")
 	if out, err := cmd3.CombinedOutput(); err != nil {
 		return fmt.Errorf("zypper install cuda-toolkit failed: %s\n%s", err, string(out))
 	}
 	return nil
 }
 
-// downloadFile downloads a URL to a local path.
-func downloadFile(url, dest string) error {
-	resp, err := httpClient.Get(url)
+// downloadFile downloads a URL to a local path; a partial file is removed.
+func downloadFile(ctx context.Context, url, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -231,9 +235,11 @@ func downloadFile(url, dest string) error {
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
 	_, err = io.Copy(f, resp.Body)
+	f.Close()
+	if err != nil {
+		os.Remove(dest)
+	}
 	return err
 }
 
@@ -255,6 +261,17 @@ func backendPackages(pm, id string) []string {
 		case "zypper":
 			return []string{"libvulkan1", "Mesa-vulkan-drivers"}
 		}
+	case "opencl":
+		switch pm {
+		case "pacman":
+			return []string{"ocl-icd"}
+		case "apt":
+			return []string{"ocl-icd-libopencl1"}
+		case "dnf":
+			return []string{"ocl-icd"}
+		case "zypper":
+			return []string{"libOpenCL1"}
+		}
 	case "rocm":
 		switch pm {
 		case "pacman":
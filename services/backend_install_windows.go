@@ -3,6 +3,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -22,25 +23,12 @@ const cudaComponents = "-s cudart_13.1 cublas_13.1"
 
 func installBackend(id string) (string, error) {
 	switch id {
-	case "cuda":
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("recovered panic in installBackendAsync(%s): %v", id, r)
-				}
-			}()
-			installBackendAsync(id)
-		}()
-		return "installing", nil
-	case "vulkan":
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("recovered panic in installBackendAsync(%s): %v", id, r)
-				}
-			}()
-			installBackendAsync(id)
-		}()
+	case "cuda", "vulkan", "opencl":
+		if err := startBackendInstall(id, func(ctx context.Context) {
+			installBackendAsync(ctx, id)
+		}); err != nil {
+			return "", err
+		}
 		return "installing", nil
 	case "rocm":
 		return openURL("https://rocm.docs.amd.com/")
@@ -52,51 +40,61 @@ func installBackend(id string) (string, error) {
 // installBackendAsync handles the full async installation flow:
 // 1. Install system runtime if needed (CUDA only)
 // 2. Download the GPU backend DLL from GitHub Releases
-func installBackendAsync(id string) {
-	emit := func(stage, stageText string, pct float64, done bool, errMsg string) {
-		emitBackendProgress(id, stage, stageText, pct, done, errMsg)
+func installBackendAsync(ctx context.Context, id string) {
+	withRuntime := id == "cuda" && !detectGPU().CUDAAvailable
+	r := newInstallReporter(ctx, id, StageDownloading)
+	if withRuntime {
+		r = newInstallReporter(ctx, id, StageDownloadingRuntime, StageInstallingRuntime, StageDownloading)
 	}
 
-	// Step 1: Install system runtime if needed.
-	if id == "cuda" {
-		det := detectGPU()
-		if !det.CUDAAvailable {
-			if err := installCUDARuntimeWindows(emit); err != nil {
-				emit("", "", 0, true, err.Error())
-				return
-			}
+	// Step 1: Install system runtime if needed (CUDA only).
+	if withRuntime {
+		if err := installCUDARuntimeWindows(ctx, r); err != nil {
+			r.fail(err.Error())
+			return
 		}
 	}
 
 	// Step 2: Download the backend DLL.
-	emit("downloading", "", 0, false, "")
-	if err := downloadBackendDLL(id); err != nil {
-		emit("", "", 0, true, fmt.Sprintf("Backend download failed: %v", err))
+	r.progress(StageDownloading, "", 0)
+	if err := downloadBackendDLL(ctx, id, func(pct float64) {
+		r.progress(StageDownloading, "", pct)
+	}); err != nil {
+		r.fail(fmt.Sprintf("Backend download failed: %v", err))
 		return
 	}
 
-	// Step 3: Hot-apply — flush engine caches and switch backend.
+	// Step 3: Hot-apply — flush engine caches and switch backend — unless
+	// cancelled meanwhile.
+	if ctx.Err() != nil {
+		return
+	}
 	if onBackendInstalled != nil {
 		onBackendInstalled(id)
 	}
 
-	emit("", "", 100, true, "")
+	r.done()
 }
 
 // installCUDARuntimeWindows downloads and silently installs CUDA runtime components.
-func installCUDARuntimeWindows(emit func(stage, stageText string, pct float64, done bool, errMsg string)) error {
+// Cancelling ctx stops the download or the waiting PowerShell; the elevated
+// installer it started can't be killed and finishes on its own.
+func installCUDARuntimeWindows(ctx context.Context, r *installReporter) error {
 	installerPath := filepath.Join(os.TempDir(), "cuda_13.1.1_windows_network.exe")
 
 	// Download network installer (~30 MB).
-	err := downloadFileWithProgress(cudaNetworkInstaller, installerPath, func(pct float64) {
-		emit("downloading_runtime", "", pct, false, "")
+	err := downloadFileWithProgress(ctx, cudaNetworkInstaller, installerPath, func(pct float64) {
+		r.progress(StageDownloadingRuntime, "", pct)
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			os.Remove(installerPath)
+		}
 		return fmt.Errorf("download CUDA installer: %w", err)
 	}
 
 	// Silent install with log monitoring.
-	emit("installing_runtime", "", 0, false, "")
+	r.progress(StageInstallingRuntime, "", 0)
 
 	logDone := make(chan struct{})
 	go func() {
@@ -106,14 +104,14 @@ func installCUDARuntimeWindows(emit func(stage, stageText string, pct float64, d
 			}
 		}()
 		watchCUDAInstallerLog(logDone, func(text string) {
-			emit("installing_runtime", text, 0, false, "")
+			r.progress(StageInstallingRuntime, text, 0)
 		})
 	}()
 
 	// Escape single quotes for PowerShell single-quoted strings ('' = literal ').
 	escapedPath := strings.ReplaceAll(installerPath, "'", "''")
 	escapedArgs := strings.ReplaceAll(cudaComponents, "'", "''")
-	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command",
 		fmt.Sprintf(`Start-Process -FilePath '%s' -ArgumentList '%s' -Verb RunAs -Wait`,
 			escapedPath, escapedArgs))
 	hideWindow(cmd)
@@ -265,7 +263,7 @@ func parseCUDALogStage(logPath string, offset int64) string {
 	return ""
 }
 
-func downloadFileWithProgress(url, dest string, onProgress func(pct float64)) error {
+func downloadFileWithProgress(ctx context.Context, url, dest string, onProgress func(pct float64)) error {
 	// Resume support: check if a partial file already exists.
 	var resumeOffset int64
 	if info, err := os.Stat(dest); err == nil && info.Size() > 0 {
@@ -273,7 +271,7 @@ func downloadFileWithProgress(url, dest string, onProgress func(pct float64)) er
 		log.Printf("downloadFileWithProgress: found partial file %s (%d bytes), attempting resume", dest, resumeOffset)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
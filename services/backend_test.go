@@ -1,7 +1,11 @@
 package services
 
 import (
+	"context"
+	"reflect"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestBackendUseGPU(t *testing.T) {
@@ -68,6 +72,67 @@ func TestCudaBackend_NVIDIANoCUDA(t *testing.T) {
 	}
 }
 
+func TestRocmBackend_NoAMD(t *testing.T) {
+	det := gpuDetection{HasNVIDIA: true, ROCmAvailable: true}
+	info := rocmBackend(det)
+
+	if info.ID != "rocm" {
+		t.Errorf("ID = %q, want %q", info.ID, "rocm")
+	}
+	if info.UnavailableReason != "no_hardware" {
+		t.Errorf("UnavailableReason = %q, want %q", info.UnavailableReason, "no_hardware")
+	}
+	if info.CanInstall {
+		t.Error("CanInstall = true, want false (no AMD hardware)")
+	}
+}
+
+func TestRocmBackend_AMDNoROCm(t *testing.T) {
+	det := gpuDetection{
+		HasAMD:   true,
+		AMDModel: "AMD Radeon RX 7900 XTX",
+	}
+	info := rocmBackend(det)
+
+	if info.UnavailableReason != "no_runtime" {
+		t.Errorf("UnavailableReason = %q, want %q", info.UnavailableReason, "no_runtime")
+	}
+	if info.CanInstall != (runtime.GOOS == "linux") {
+		t.Errorf("CanInstall = %v, want %v (ROCm runtime installable on Linux only)", info.CanInstall, runtime.GOOS == "linux")
+	}
+	if info.GPUDetected != "AMD Radeon RX 7900 XTX" {
+		t.Errorf("GPUDetected = %q, want %q", info.GPUDetected, "AMD Radeon RX 7900 XTX")
+	}
+	if info.InstallHint != "ROCm HIP Runtime" {
+		t.Errorf("InstallHint = %q, want %q", info.InstallHint, "ROCm HIP Runtime")
+	}
+}
+
+func TestRocmBackend_RuntimePresent(t *testing.T) {
+	det := gpuDetection{
+		HasAMD:        true,
+		ROCmAvailable: true,
+		GPUs: []gpuInfo{
+			{Name: "AMD Radeon RX 6800", Vendor: "amd", DriverVersion: "6.8.0"},
+			{Name: "Intel UHD 770", Vendor: "intel"},
+		},
+	}
+	info := rocmBackend(det)
+
+	if !info.SystemAvailable || !info.RuntimeInstalled {
+		t.Errorf("SystemAvailable = %v, RuntimeInstalled = %v, want both true", info.SystemAvailable, info.RuntimeInstalled)
+	}
+	if info.GPUDetected != "AMD Radeon RX 6800" {
+		t.Errorf("GPUDetected = %q, want %q", info.GPUDetected, "AMD Radeon RX 6800")
+	}
+	if !info.DriverOK || info.DriverVersion != "6.8.0" {
+		t.Errorf("DriverVersion = %q, DriverOK = %v, want 6.8.0 and true", info.DriverVersion, info.DriverOK)
+	}
+	if !info.Compiled && !info.CanInstall {
+		t.Error("CanInstall = false, want true (library downloadable)")
+	}
+}
+
 func TestVulkanBackend_NoVulkan(t *testing.T) {
 	det := gpuDetection{VulkanAvailable: false}
 	info := vulkanBackend(det)
@@ -85,3 +150,119 @@ func TestVulkanBackend_NoVulkan(t *testing.T) {
 		t.Errorf("InstallHint = %q, want %q", info.InstallHint, "Vulkan ICD Loader")
 	}
 }
+
+func TestOpenCLBackend_NoOpenCL(t *testing.T) {
+	det := gpuDetection{OpenCLAvailable: false}
+	info := openclBackend(det)
+
+	if info.ID != "opencl" {
+		t.Errorf("ID = %q, want %q", info.ID, "opencl")
+	}
+	if info.UnavailableReason != "no_runtime" {
+		t.Errorf("UnavailableReason = %q, want %q", info.UnavailableReason, "no_runtime")
+	}
+	if info.InstallHint != "OpenCL ICD Loader" {
+		t.Errorf("InstallHint = %q, want %q", info.InstallHint, "OpenCL ICD Loader")
+	}
+	if info.RuntimeInstalled {
+		t.Error("RuntimeInstalled = true, want false")
+	}
+}
+
+func TestOpenCLBackend_RuntimePresent(t *testing.T) {
+	det := gpuDetection{
+		OpenCLAvailable: true,
+		GPUs: []gpuInfo{
+			{Name: "Intel Arc A770", Vendor: "intel", DriverVersion: "31.0.101.5186"},
+		},
+	}
+	info := openclBackend(det)
+
+	if !info.SystemAvailable || !info.RuntimeInstalled {
+		t.Errorf("SystemAvailable = %v, RuntimeInstalled = %v, want both true", info.SystemAvailable, info.RuntimeInstalled)
+	}
+	if info.GPUDetected != "Intel Arc A770" {
+		t.Errorf("GPUDetected = %q, want %q", info.GPUDetected, "Intel Arc A770")
+	}
+	if !info.DriverOK {
+		t.Error("DriverOK = false, want true (driver version detected)")
+	}
+	if !info.Compiled && !info.CanInstall {
+		t.Error("CanInstall = false, want true (library downloadable)")
+	}
+}
+
+func TestGPUDevices(t *testing.T) {
+	devs := []ggmlDevice{
+		{Index: 0, Backend: "CUDA", Name: "CUDA0", Description: "NVIDIA GeForce RTX 4070"},
+		{Index: 1, Backend: "Vulkan", Name: "Vulkan0", Description: "AMD Radeon RX 7900 XTX (RADV NAVI31)"},
+		{Index: 2, Backend: "Vulkan", Name: "Vulkan1"},
+	}
+	want := []GPUDevice{
+		{0, "NVIDIA GeForce RTX 4070", "nvidia", "CUDA"},
+		{1, "AMD Radeon RX 7900 XTX (RADV NAVI31)", "amd", "Vulkan"},
+		{2, "Vulkan1", "unknown", "Vulkan"},
+	}
+	if got := gpuDevices(devs); !reflect.DeepEqual(got, want) {
+		t.Errorf("gpuDevices = %+v, want %+v", got, want)
+	}
+	if got := gpuDevices(nil); len(got) != 0 {
+		t.Errorf("gpuDevices(no GPU) = %+v, want none", got)
+	}
+}
+
+func TestBackendInstallCancel(t *testing.T) {
+	started, finished := make(chan struct{}), make(chan error, 1)
+	err := startBackendInstall("test", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		finished <- ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("startBackendInstall: %v", err)
+	}
+	<-started
+	if err := startBackendInstall("test", func(context.Context) {}); err == nil {
+		t.Error("second install of the same backend = nil error")
+	}
+	if cancelBackendInstall("other") {
+		t.Error("cancelBackendInstall(not installing) = true")
+	}
+	if !cancelBackendInstall("test") {
+		t.Fatal("cancelBackendInstall = false")
+	}
+	select {
+	case err := <-finished:
+		if err != context.Canceled {
+			t.Errorf("install context error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("install not cancelled")
+	}
+
+	// Once the cancelled install returned, the backend can be installed again.
+	deadline := time.Now().Add(2 * time.Second)
+	for startBackendInstall("test", func(context.Context) {}) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("backend still busy after the cancelled install returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInstallReporterStages(t *testing.T) {
+	r := newInstallReporter(context.Background(), "cuda",
+		StageDownloadingRuntime, StageInstallingRuntime, StageDownloading)
+
+	p := r.event(StageInstallingRuntime, "Extracting...", 0)
+	if p.StageIndex != 2 || p.StageTotal != 3 || p.StageKey != "install_stage_installing_runtime" || p.StageText != "Extracting..." {
+		t.Errorf("installing_runtime = %+v, want step 2/3 with its key and text", p)
+	}
+	if p := r.event(StageFailed, "boom", 0); !p.Done || p.Error != "boom" || p.StageText != "" || p.StageIndex != 2 {
+		t.Errorf("failed = %+v, want done with the error at step 2", p)
+	}
+	r.event(StageDownloading, "", 40)
+	if p := r.event(StageDone, "", 0); !p.Done || p.Error != "" || p.Percent != 100 || p.StageIndex != 3 || p.StageTotal != 3 {
+		t.Errorf("done = %+v, want done at 100%% and step 3/3", p)
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// benchmarkSeconds is the length of the synthetic benchmark sample.
+const benchmarkSeconds = 5
+
+// BackendBenchmark is the result of timing one backend on the benchmark sample.
+type BackendBenchmark struct {
+	Backend string  `json:"backend"` // backend ID, e.g. "cpu", "cuda"
+	Name    string  `json:"name"`
+	LoadMs  int64   `json:"loadMs"` // model load time
+	Millis  int64   `json:"millis"` // transcription wall-clock time
+	RTF     float64 `json:"rtf"`    // real-time factor: processing time / audio length (< 1 = faster than real time)
+	Error   string  `json:"error"`  // non-empty if the backend failed to init or transcribe
+}
+
+// BenchmarkBackends loads modelName with each compiled backend, transcribes a
+// fixed sample and reports the timings, fastest first. Backends that fail are
+// listed last with Error set instead of aborting the run. Takes a few seconds
+// per backend; the model is loaded fresh each time and closed afterwards.
+//
+// An empty modelName uses the smallest downloaded model. Progress is emitted as
+// backend:benchmark:progress {backendId, done, error, millis} around each
+// backend. The fastest backend is saved and becomes the Recommended one in
+// GetAllBackends while it stays available.
+func (s *SettingsService) BenchmarkBackends(modelName string) ([]BackendBenchmark, error) {
+	dir := s.models.ResolveModelsDir()
+	if modelName == "" {
+		modelName = smallestModel(downloadedModels(dir))
+		if modelName == "" {
+			return nil, fmt.Errorf("no downloaded model to benchmark with")
+		}
+	}
+	if !isValidModelName(modelName) && !isCustomModelName(modelName) {
+		return nil, fmt.Errorf("invalid model name: %s", modelName)
+	}
+	modelPath := filepath.Join(dir, "ggml-"+modelName+".bin")
+	if _, err := os.Stat(modelPath); err != nil {
+		return nil, fmt.Errorf("model %s is not downloaded", modelName)
+	}
+
+	sample := benchmarkSample()
+	devices := ggmlGPUDevices()
+	var results []BackendBenchmark
+	for _, b := range GetAllBackends() {
+		// "auto" resolves to one of the others.
+		if b.ID == "auto" || !b.Compiled {
+			continue
+		}
+		emitBenchmarkProgress(BackendBenchmark{Backend: b.ID, Name: b.Name}, false)
+		var res BackendBenchmark
+		if device, ok := backendDevice(devices, b.ID); ok {
+			res = benchmarkBackend(modelPath, b, device, sample)
+		} else {
+			res = BackendBenchmark{Backend: b.ID, Name: b.Name, Error: fmt.Sprintf("no %s device found", b.Name)}
+		}
+		if res.Error != "" {
+			log.Printf("Benchmark %s: %s", b.ID, res.Error)
+		} else {
+			log.Printf("Benchmark %s: load %dms, transcribe %dms (RTF %.2f)", b.ID, res.LoadMs, res.Millis, res.RTF)
+		}
+		emitBenchmarkProgress(res, true)
+		results = append(results, res)
+	}
+	sortBenchmarks(results)
+
+	saveFastestBackend(benchmarkWinner(results, devices, checkGPUDevice(gpuDeviceSetting(), len(devices))))
+	return results, nil
+}
+
+// benchmarkWinner returns the backend to recommend from sorted results, "" if
+// none. Whatever GPU backend is selected, whisper runs on the GPU device with
+// index device, so only the CPU or that device's backend can win: a faster
+// backend on another device isn't what the user would get.
+func benchmarkWinner(results []BackendBenchmark, devices []ggmlDevice, device int) string {
+	if len(results) == 0 || results[0].Error != "" {
+		return ""
+	}
+	id := results[0].Backend
+	if i, ok := backendDevice(devices, id); !ok || (backendUseGPU(id) && i != device) {
+		return ""
+	}
+	return id
+}
+
+// emitBenchmarkProgress reports a backend starting (done=false) or finishing.
+func emitBenchmarkProgress(res BackendBenchmark, done bool) {
+	if app := application.Get(); app != nil {
+		app.Event.Emit("backend:benchmark:progress", map[string]any{
+			"backendId": res.Backend,
+			"done":      done,
+			"error":     res.Error,
+			"millis":    res.Millis,
+		})
+	}
+}
+
+// saveFastestBackend stores the benchmark winner for the Recommended flag;
+// "" clears the one from an earlier run.
+func saveFastestBackend(id string) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return
+	}
+	cfg.FastestBackend = id
+	if err := config.Save(cfg); err != nil {
+		log.Printf("failed to save benchmark result: %v", err)
+	}
+}
+
+// smallestModel returns the name of the smallest model in models, "" if none.
+func smallestModel(models []ModelInfo) string {
+	name, size := "", int64(0)
+	for _, m := range models {
+		if name == "" || m.SizeBytes < size {
+			name, size = m.Name, m.SizeBytes
+		}
+	}
+	return name
+}
+
+// backendDevice returns the gpu_device index that makes whisper run on
+// backend: the first GPU device of that ggml backend. whisper_init only
+// chooses between GPU and CPU, so the device index is what selects the
+// backend. The CPU needs no device.
+func backendDevice(devices []ggmlDevice, backend string) (int, bool) {
+	if !backendUseGPU(backend) {
+		return 0, true
+	}
+	for _, d := range devices {
+		if strings.EqualFold(d.Backend, backend) {
+			return d.Index, true
+		}
+	}
+	return 0, false
+}
+
+// benchmarkBackend times model load and one transcription of sample on
+// backend b, running on the GPU device with index device.
+func benchmarkBackend(modelPath string, b BackendInfo, device int, sample []float32) BackendBenchmark {
+	res := BackendBenchmark{Backend: b.ID, Name: b.Name}
+
+	// Same init timeout as getOrLoadEngine: a broken GPU backend can hang here.
+	type initResult struct {
+		engine *WhisperEngine
+		err    error
+	}
+	ch := make(chan initResult, 1)
+	start := time.Now()
+	go func() {
+		eng, err := NewWhisperEngine(modelPath, b.ID, device)
+		ch <- initResult{eng, err}
+	}()
+
+	var engine *WhisperEngine
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			res.Error = r.err.Error()
+			return res
+		}
+		engine = r.engine
+	case <-time.After(modelInitTimeout):
+		// Free the engine if the load finishes after all.
+		go func() {
+			if r := <-ch; r.engine != nil {
+				r.engine.Close()
+			}
+		}()
+		res.Error = fmt.Sprintf("model init timed out (%v)", modelInitTimeout)
+		return res
+	}
+	defer engine.Close()
+	res.LoadMs = time.Since(start).Milliseconds()
+
+	engine.SetThreads(inferenceThreadSetting())
+	start = time.Now()
+	if _, err := engine.Transcribe(sample, "en", DecodeOptions{}); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	elapsed := time.Since(start)
+	res.Millis = elapsed.Milliseconds()
+	res.RTF = elapsed.Seconds() / benchmarkSeconds
+	return res
+}
+
+// sortBenchmarks orders results fastest first; failed backends go last.
+func sortBenchmarks(results []BackendBenchmark) {
+	sort.SliceStable(results, func(i, j int) bool {
+		ei, ej := results[i].Error != "", results[j].Error != ""
+		if ei != ej {
+			return !ei
+		}
+		return results[i].Millis < results[j].Millis
+	})
+}
+
+// benchmarkSample synthesizes benchmarkSeconds of voice-like audio: a 140 Hz
+// harmonic series with a slowly gliding pitch, chopped into ~4 syllables per
+// second. It is deterministic, so runs are comparable, and needs no bundled
+// file. Whisper's encoder cost doesn't depend on content; the decoder sees
+// something speech-like rather than silence.
+func benchmarkSample() []float32 {
+	n := benchmarkSeconds * sampleRate
+	pcm := make([]float32, n)
+	phase := 0.0
+	for i := range pcm {
+		t := float64(i) / sampleRate
+		f0 := 140 + 20*math.Sin(2*math.Pi*0.7*t)
+		phase += 2 * math.Pi * f0 / sampleRate
+		var v float64
+		for h := 1; h <= 8; h++ {
+			v += math.Sin(float64(h)*phase) / float64(h)
+		}
+		// Syllable envelope: raised cosine at 4 Hz, never fully silent.
+		env := 0.15 + 0.85*0.5*(1-math.Cos(2*math.Pi*4*t))
+		pcm[i] = float32(0.2 * env * v)
+	}
+	return pcm
+}
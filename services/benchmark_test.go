@@ -0,0 +1,121 @@
+package services
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+func TestSortBenchmarks(t *testing.T) {
+	results := []BackendBenchmark{
+		{Backend: "cpu", Millis: 900},
+		{Backend: "opencl", Error: "failed to load whisper model"},
+		{Backend: "cuda", Millis: 120},
+		{Backend: "vulkan", Millis: 300},
+	}
+	sortBenchmarks(results)
+
+	want := []string{"cuda", "vulkan", "cpu", "opencl"}
+	for i, r := range results {
+		if r.Backend != want[i] {
+			t.Fatalf("order = %v, want %v", results, want)
+		}
+	}
+}
+
+func TestBackendDevice(t *testing.T) {
+	devices := []ggmlDevice{
+		{Index: 0, Backend: "Vulkan", Name: "Vulkan0"},
+		{Index: 1, Backend: "CUDA", Name: "CUDA0"},
+		{Index: 2, Backend: "CUDA", Name: "CUDA1"},
+	}
+	for _, tc := range []struct {
+		backend string
+		want    int
+		ok      bool
+	}{
+		{"cpu", 0, true},
+		{"cuda", 1, true},
+		{"vulkan", 0, true},
+		{"rocm", 0, false},
+	} {
+		got, ok := backendDevice(devices, tc.backend)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("backendDevice(%q) = %d, %v; want %d, %v", tc.backend, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestBenchmarkWinner(t *testing.T) {
+	devices := []ggmlDevice{
+		{Index: 0, Backend: "Vulkan", Name: "Vulkan0"},
+		{Index: 1, Backend: "CUDA", Name: "CUDA0"},
+	}
+	results := []BackendBenchmark{{Backend: "cuda", Millis: 120}, {Backend: "cpu", Millis: 900}}
+	if got := benchmarkWinner(results, devices, 1); got != "cuda" {
+		t.Errorf("winner on the selected device = %q, want cuda", got)
+	}
+	// GPU runs use device 0 (Vulkan) whatever backend is chosen.
+	if got := benchmarkWinner(results, devices, 0); got != "" {
+		t.Errorf("winner on another device = %q, want none", got)
+	}
+	results = []BackendBenchmark{{Backend: "cpu", Millis: 300}, {Backend: "vulkan", Millis: 400}}
+	if got := benchmarkWinner(results, devices, 0); got != "cpu" {
+		t.Errorf("winner = %q, want cpu", got)
+	}
+	if got := benchmarkWinner([]BackendBenchmark{{Backend: "cuda", Error: "failed"}}, devices, 1); got != "" {
+		t.Errorf("winner of failed runs = %q, want none", got)
+	}
+}
+
+func TestBenchmarkSample(t *testing.T) {
+	a, b := benchmarkSample(), benchmarkSample()
+	if len(a) != benchmarkSeconds*sampleRate {
+		t.Fatalf("len = %d, want %d", len(a), benchmarkSeconds*sampleRate)
+	}
+	var peak float64
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("sample differs between calls at %d", i)
+		}
+		peak = math.Max(peak, math.Abs(float64(a[i])))
+	}
+	// Audible, but not clipping.
+	if peak < 0.1 || peak > 1 {
+		t.Errorf("peak = %.3f, want within [0.1, 1]", peak)
+	}
+}
+
+func TestSmallestModel(t *testing.T) {
+	models := []ModelInfo{
+		{Name: "base", SizeBytes: 147_500_000},
+		{Name: "tiny-q5_1", SizeBytes: 47_500_000},
+		{Name: "my-finetune", SizeBytes: 90_000_000},
+	}
+	if got := smallestModel(models); got != "tiny-q5_1" {
+		t.Errorf("smallestModel = %q, want tiny-q5_1", got)
+	}
+	if got := smallestModel(nil); got != "" {
+		t.Errorf("smallestModel(nil) = %q, want empty", got)
+	}
+}
+
+func TestRecommendedBackendPrefersBenchmark(t *testing.T) {
+	det := gpuDetection{HasNVIDIA: true, VulkanAvailable: true}
+	backends := []BackendInfo{
+		{ID: "cpu", Compiled: true, SystemAvailable: true},
+		{ID: "cuda", Compiled: true, SystemAvailable: true},
+		{ID: "vulkan", Compiled: false, SystemAvailable: true},
+	}
+	hardware := recommendedBackend(det, backends, "")
+	if runtime.GOOS != "darwin" && hardware != "cuda" {
+		t.Errorf("recommendedBackend without benchmark = %q, want cuda", hardware)
+	}
+	if got := recommendedBackend(det, backends, "cpu"); got != "cpu" {
+		t.Errorf("recommendedBackend(fastest cpu) = %q, want cpu", got)
+	}
+	// A benchmark winner that is no longer installed falls back to detection.
+	if got := recommendedBackend(det, backends, "vulkan"); got != hardware {
+		t.Errorf("recommendedBackend(fastest vulkan, not compiled) = %q, want %q", got, hardware)
+	}
+}
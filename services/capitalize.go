@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// caretContextChars is how many characters before the caret are read for context.
+const caretContextChars = 16
+
+// caretContext reads the text immediately before the caret of the focused input.
+type caretContext interface {
+	PrecedingText(maxChars int) (string, error)
+}
+
+// systemCaretContext reads caret context through the platform accessibility API:
+// UI Automation on Windows, AXUIElement on macOS. Linux has no portable API for
+// this, so it always reports an error and the preset's Capitalize setting
+// decides instead.
+type systemCaretContext struct{}
+
+func (systemCaretContext) PrecedingText(maxChars int) (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return winPrecedingText(maxChars)
+	case "darwin":
+		return axPrecedingText(maxChars)
+	}
+	return "", fmt.Errorf("caret context not supported on %s", runtime.GOOS)
+}
+
+// caretReader is swapped out in tests.
+var caretReader caretContext = systemCaretContext{}
+
+// capitalizeForContext adjusts the first letter of text to fit the text already
+// before the caret. If the context can't be read, it falls back to the
+// preset's fixed formatting: the first letter is capitalized if capitalize is
+// set, otherwise text is returned unchanged.
+func capitalizeForContext(text string, ctx caretContext, capitalize bool) string {
+	before, err := ctx.PrecedingText(caretContextChars)
+	if err != nil {
+		if capitalize {
+			return applyTextFormatting(text, textFormat{Capitalize: true})
+		}
+		return text
+	}
+	return contextCapitalize(text, before)
+}
+
+// contextCapitalize decides the case of the first word of text given the text
+// that precedes the insertion point:
+//   - start of field, start of line, or after . ! ? … → capitalize
+//   - anything else (mid-sentence) → lowercase, except "I" / "I'm" and acronyms
+func contextCapitalize(text, before string) string {
+	// A preserved leading space belongs to the context, not the first word.
+	body := strings.TrimLeftFunc(text, unicode.IsSpace)
+	lead := text[:len(text)-len(body)]
+
+	r, size := utf8.DecodeRuneInString(body)
+	if size == 0 || !unicode.IsLetter(r) {
+		return text
+	}
+
+	if sentenceStart(before) {
+		return lead + string(unicode.ToUpper(r)) + body[size:]
+	}
+	if keepCase(firstWord(body)) {
+		return text
+	}
+	return lead + string(unicode.ToLower(r)) + body[size:]
+}
+
+// sentenceStart reports whether new text inserted after before begins a sentence.
+func sentenceStart(before string) bool {
+	if strings.HasSuffix(strings.TrimRight(before, " \t"), "\n") {
+		return true
+	}
+	trimmed := strings.TrimRightFunc(before, unicode.IsSpace)
+	if trimmed == "" {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(trimmed)
+	return strings.ContainsRune(".!?…", last)
+}
+
+// firstWord returns the leading run of letters, digits and apostrophes.
+func firstWord(text string) string {
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	if end < 0 {
+		return text
+	}
+	return text[:end]
+}
+
+// keepCase reports whether word must stay capitalized mid-sentence:
+// the English pronoun "I" (and its contractions) or an all-caps acronym.
+func keepCase(word string) bool {
+	if word == "I" || strings.HasPrefix(word, "I'") || strings.HasPrefix(word, "I’") {
+		return true
+	}
+	if utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsLetter(r) && !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// textFormat is the preset's fixed formatting of a transcription (Capitalize,
+// EndPunctuation), independent of the text around the caret.
+type textFormat struct {
+	Capitalize     bool // upper-case the first letter
+	EndPunctuation bool // end with "." unless the text already ends in punctuation
+}
+
+// presetTextFormat returns the fixed formatting options of a preset.
+func presetTextFormat(preset config.Preset) textFormat {
+	return textFormat{Capitalize: preset.Capitalize, EndPunctuation: preset.EndPunctuation}
+}
+
+// applyTextFormatting capitalizes the first letter of text (after any leading
+// digits or punctuation) and appends "." when text ends with a letter or
+// digit, as opts asks. Whitespace around text is kept.
+func applyTextFormatting(text string, opts textFormat) string {
+	if opts.Capitalize {
+		if i := strings.IndexFunc(text, unicode.IsLetter); i >= 0 {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			text = text[:i] + string(unicode.ToTitle(r)) + text[i+size:]
+		}
+	}
+	if opts.EndPunctuation {
+		body := strings.TrimRightFunc(text, unicode.IsSpace)
+		last, _ := utf8.DecodeLastRuneInString(body)
+		if body != "" && (unicode.IsLetter(last) || unicode.IsDigit(last)) {
+			text = body + "." + text[len(body):]
+		}
+	}
+	return text
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeCaret struct {
+	text string
+	err  error
+}
+
+func (f fakeCaret) PrecedingText(int) (string, error) { return f.text, f.err }
+
+func TestContextCapitalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		before string
+		want   string
+	}{
+		{"empty field", "hello world", "", "Hello world"},
+		{"after period", "hello", "Done. ", "Hello"},
+		{"after question", "what", "Really?", "What"},
+		{"after exclamation", "ok", "Wow!  ", "Ok"},
+		{"after ellipsis", "and then", "Well… ", "And then"},
+		{"new line", "next", "first line\n", "Next"},
+		{"new line with indent", "next", "line\n  ", "Next"},
+		{"mid sentence", "Hello", "I said ", "hello"},
+		{"after comma", "Then", "first, ", "then"},
+		{"pronoun I", "I think", "and ", "I think"},
+		{"contraction I'm", "I'm here", "so ", "I'm here"},
+		{"acronym", "NASA launched", "the ", "NASA launched"},
+		{"cyrillic", "Привет", "сказал ", "привет"},
+		{"cyrillic sentence", "привет", "Да. ", "Привет"},
+		{"leading digit", "42 items", "", "42 items"},
+		{"leading space", " hello", "Done.", " Hello"},
+		{"leading space mid sentence", " Hello", "said", " hello"},
+		{"empty text", "", "abc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contextCapitalize(tt.text, tt.before); got != tt.want {
+				t.Errorf("contextCapitalize(%q, %q) = %q, want %q", tt.text, tt.before, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapitalizeForContextFallback(t *testing.T) {
+	unreadable := fakeCaret{err: errors.New("unsupported")}
+	if got := capitalizeForContext("hello", unreadable, false); got != "hello" {
+		t.Errorf("unreadable context without Capitalize should leave text unchanged, got %q", got)
+	}
+	if got := capitalizeForContext("hello", unreadable, true); got != "Hello" {
+		t.Errorf("unreadable context with Capitalize = %q, want %q", got, "Hello")
+	}
+	got := capitalizeForContext("Hello", fakeCaret{text: "one, "}, true)
+	if got != "hello" {
+		t.Errorf("got %q, want %q", got, "hello")
+	}
+}
+
+func TestApplyTextFormatting(t *testing.T) {
+	both := textFormat{Capitalize: true, EndPunctuation: true}
+	tests := []struct {
+		text string
+		opts textFormat
+		want string
+	}{
+		{"hello world", textFormat{}, "hello world"},
+		{"hello world", textFormat{Capitalize: true}, "Hello world"},
+		{"hello world", textFormat{EndPunctuation: true}, "hello world."},
+		{"hello world", both, "Hello world."},
+		{"привет, мир", both, "Привет, мир."},
+		{"élan vital", both, "Élan vital."},
+		{" hello", both, " Hello."},          // preserved leading space
+		{"3 apples", both, "3 Apples."},      // first letter, after digits
+		{"«цитата»", both, "«Цитата»"},       // ends in punctuation
+		{"is it done?", both, "Is it done?"}, // already punctuated
+		{"wait…", both, "Wait…"},
+		{"call 911", both, "Call 911."},
+		{"Hello.", both, "Hello."},
+		{"ǆungla", textFormat{Capitalize: true}, "ǅungla"}, // title case, not upper
+		{"", both, ""},
+		{"...", both, "..."},
+	}
+	for _, tt := range tests {
+		if got := applyTextFormatting(tt.text, tt.opts); got != tt.want {
+			t.Errorf("applyTextFormatting(%q, %+v) = %q, want %q", tt.text, tt.opts, got, tt.want)
+		}
+	}
+}
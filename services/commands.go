@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Voice command actions (values of Preset.CommandMap):
+//
+//	key:<name>     press a single key, e.g. "key:enter", "key:backspace"
+//	paste:<text>   paste literal text; \n, \t and \\ escapes are expanded
+const (
+	actionKey   = "key:"
+	actionPaste = "paste:"
+)
+
+// commandKey describes how to press a key on each platform.
+type commandKey struct {
+	scan     int    // Linux evdev scancode (ydotool)
+	keysym   string // X11 keysym (wtype, xdotool)
+	macCode  int    // macOS virtual key code (System Events "key code")
+	vk       uint16 // Windows virtual-key code
+	extended bool   // Windows: needs KEYEVENTF_EXTENDEDKEY
+}
+
+var commandKeys = map[string]commandKey{
+	"enter":     {scan: 28, keysym: "Return", macCode: 36, vk: 0x0D},
+	"backspace": {scan: 14, keysym: "BackSpace", macCode: 51, vk: 0x08},
+	"tab":       {scan: 15, keysym: "Tab", macCode: 48, vk: 0x09},
+	"escape":    {scan: 1, keysym: "Escape", macCode: 53, vk: 0x1B},
+	"space":     {scan: 57, keysym: "space", macCode: 49, vk: 0x20},
+	"delete":    {scan: 111, keysym: "Delete", macCode: 117, vk: 0x2E, extended: true},
+	"up":        {scan: 103, keysym: "Up", macCode: 126, vk: 0x26, extended: true},
+	"down":      {scan: 108, keysym: "Down", macCode: 125, vk: 0x28, extended: true},
+	"left":      {scan: 105, keysym: "Left", macCode: 123, vk: 0x25, extended: true},
+	"right":     {scan: 106, keysym: "Right", macCode: 124, vk: 0x27, extended: true},
+	"home":      {scan: 102, keysym: "Home", macCode: 115, vk: 0x24, extended: true},
+	"end":       {scan: 107, keysym: "End", macCode: 119, vk: 0x23, extended: true},
+}
+
+// normalizeCommand folds a phrase for command lookup: lowercase, surrounding
+// punctuation dropped, inner whitespace collapsed. "New line." → "new line".
+func normalizeCommand(phrase string) string {
+	phrase = strings.TrimFunc(strings.ToLower(phrase), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return strings.Join(strings.Fields(phrase), " ")
+}
+
+// matchCommand looks the transcription up in commands (phrase → action).
+// Both sides are normalized, so map keys needn't match whisper's punctuation.
+func matchCommand(text string, commands map[string]string) (string, bool) {
+	if len(commands) == 0 {
+		return "", false
+	}
+	spoken := normalizeCommand(text)
+	if spoken == "" {
+		return "", false
+	}
+	for phrase, action := range commands {
+		if normalizeCommand(phrase) == spoken {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+// runCommand executes a voice command action.
+func runCommand(action string) error {
+	switch {
+	case strings.HasPrefix(action, actionKey):
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(action, actionKey)))
+		key, ok := commandKeys[name]
+		if !ok {
+			return fmt.Errorf("unknown key %q in command %q", name, action)
+		}
+		return pressKey(key)
+	case strings.HasPrefix(action, actionPaste):
+		return pasteText(unescapeAffix(strings.TrimPrefix(action, actionPaste)))
+	}
+	return fmt.Errorf("unknown command action %q (want key:<name> or paste:<text>)", action)
+}
+
+// pressKey presses and releases a single key in the focused app, with the same
+// tools the paste path uses for Shift+Insert / Cmd+V.
+func pressKey(key commandKey) error {
+	switch runtime.GOOS {
+	case "linux":
+		return pressKeyLinux(key)
+	case "darwin":
+		script := fmt.Sprintf(`tell application "System Events" to key code %d`, key.macCode)
+		if err := exec.Command("osascript", "-e", script).Run(); err != nil {
+			return fmt.Errorf("key simulation failed: %w", err)
+		}
+		return nil
+	case "windows":
+		return winSendKey(key.vk, key.extended)
+	}
+	return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+}
+
+func pressKeyLinux(key commandKey) error {
+	scan := strconv.Itoa(key.scan)
+	if path, err := exec.LookPath("ydotool"); err == nil {
+		if err := exec.Command(path, "key", scan+":1", scan+":0").Run(); err == nil {
+			return nil
+		}
+	}
+	if path, err := exec.LookPath("wtype"); err == nil {
+		if err := exec.Command(path, "-k", key.keysym).Run(); err == nil {
+			return nil
+		}
+	}
+	if path, err := exec.LookPath("xdotool"); err == nil {
+		return exec.Command(path, "key", "--clearmodifiers", key.keysym).Run()
+	}
+	return fmt.Errorf("no key simulation tool found (install ydotool, wtype, or xdotool)")
+}
@@ -0,0 +1,56 @@
+package services
+
+import "testing"
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"New line.", "new line"},
+		{"  press   Enter! ", "press enter"},
+		{"Delete that...", "delete that"},
+		{"«Новая строка»", "новая строка"},
+		{"...", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCommand(tt.in); got != tt.want {
+			t.Errorf("normalizeCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	commands := map[string]string{
+		"new line":    "key:enter",
+		"Delete that": "key:backspace",
+		"sign off":    `paste:Thanks,\nAnna`,
+	}
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{" New line.", "key:enter", true},
+		{"delete that!", "key:backspace", true},
+		{"Sign off", `paste:Thanks,\nAnna`, true},
+		{"new line please", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := matchCommand(tt.text, commands)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("matchCommand(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+	if _, ok := matchCommand("new line", nil); ok {
+		t.Error("matchCommand with no commands matched")
+	}
+}
+
+func TestRunCommandRejectsUnknown(t *testing.T) {
+	for _, action := range []string{"key:hyperdrive", "launch:rocket", ""} {
+		if err := runCommand(action); err == nil {
+			t.Errorf("runCommand(%q) = nil, want error", action)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"log"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// onConfigChanged applies a config.json edited outside the app (see
+// config.Watch), unless WatchConfig is off. Only what changed is redone:
+// presets whose hotkey or model settings differ are re-activated, removed
+// ones deactivated, new ones activated; the global hotkeys and microphone
+// are switched. Emits config:changed so the UI reloads presets and settings.
+// An edit with more presets than maxPresets is ignored, like CreatePreset
+// refuses one past the cap.
+func (s *PresetService) onConfigChanged() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config watch: failed to load config: %v", err)
+		return
+	}
+	if !watchConfigEnabled(cfg) {
+		return
+	}
+	if limit := presetLimit(cfg); len(cfg.Presets) > limit {
+		log.Printf("config watch: edit ignored: %d presets exceed the limit of %d (maxPresets)", len(cfg.Presets), limit)
+		return
+	}
+
+	s.mu.Lock()
+	old := s.cfg
+	s.cfg = cfg
+	for _, p := range cfg.Presets {
+		if _, ok := s.states[p.ID]; !ok {
+			s.states[p.ID] = "idle"
+		}
+	}
+	for _, p := range old.Presets {
+		if findPreset(cfg.Presets, p.ID) == nil {
+			delete(s.states, p.ID)
+		}
+	}
+	s.mu.Unlock()
+	deactivate, activate := presetChanges(old.Presets, cfg.Presets)
+
+	for _, id := range deactivate {
+		s.deactivatePreset(id)
+	}
+	for i := range activate {
+		s.activatePreset(&activate[i])
+	}
+	s.rebindGlobalHotkey(pauseHotkeyID, old.PauseHotkey, cfg.PauseHotkey)
+	s.rebindGlobalHotkey(repeatHotkeyID, old.RepeatHotkey, cfg.RepeatHotkey)
+	if old.MicrophoneID != cfg.MicrophoneID && s.audio != nil {
+		s.audio.SetMicrophoneID(cfg.MicrophoneID)
+	}
+	s.presetsChanged()
+	log.Printf("PresetService: applied external config edit (%d presets re-registered, %d unregistered)", len(activate), len(deactivate))
+
+	if app := application.Get(); app != nil {
+		app.Event.Emit("config:changed")
+	}
+}
+
+// rebindGlobalHotkey moves the global hotkey id from old to hotkey.
+func (s *PresetService) rebindGlobalHotkey(id, old, hotkey string) {
+	if old == hotkey || s.hotkeys == nil {
+		return
+	}
+	s.hotkeys.Unregister(id)
+	if hotkey != "" {
+		if err := s.hotkeys.Register(id, hotkey, "toggle"); err != nil {
+			log.Printf("Failed to register hotkey %s: %v", id, err)
+		}
+	}
+}
+
+// presetChanges diffs two preset lists: the IDs of enabled presets to
+// deactivate (removed, disabled or changed) and the enabled presets of next
+// to activate (new, enabled or changed). Presets whose hotkey and model
+// settings are the same are left alone.
+func presetChanges(prev, next []config.Preset) (deactivate []string, activate []config.Preset) {
+	for _, old := range prev {
+		if !old.Enabled {
+			continue
+		}
+		if p := findPreset(next, old.ID); p == nil || needsReactivation(old, *p) {
+			deactivate = append(deactivate, old.ID)
+		}
+	}
+	for _, p := range next {
+		if !p.Enabled {
+			continue
+		}
+		if old := findPreset(prev, p.ID); old == nil || needsReactivation(*old, p) {
+			activate = append(activate, p)
+		}
+	}
+	return deactivate, activate
+}
+
+func findPreset(presets []config.Preset, id string) *config.Preset {
+	for i := range presets {
+		if presets[i].ID == id {
+			return &presets[i]
+		}
+	}
+	return nil
+}
+
+// watchConfigEnabled reports whether external config edits are applied
+// (AppConfig.WatchConfig, default on).
+func watchConfigEnabled(cfg *config.AppConfig) bool {
+	return cfg.WatchConfig == nil || *cfg.WatchConfig
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestPresetChanges(t *testing.T) {
+	a := config.Preset{ID: "a", Hotkey: "f1", Enabled: true}
+	b := config.Preset{ID: "b", Hotkey: "f2", Enabled: true}
+	c := config.Preset{ID: "c", Hotkey: "f3"}
+
+	renamed := a
+	renamed.Name, renamed.Language = "Renamed", "de" // read at record time, no re-registration
+	rebound := b
+	rebound.Hotkey = "f4"
+	enabled := c
+	enabled.Enabled = true
+	d := config.Preset{ID: "d", Hotkey: "f5", Enabled: true}
+
+	deactivate, activate := presetChanges([]config.Preset{a, b, c}, []config.Preset{renamed, rebound, enabled, d})
+	if want := []string{"b"}; !reflect.DeepEqual(deactivate, want) {
+		t.Errorf("deactivate = %v, want %v", deactivate, want)
+	}
+	if want := []config.Preset{rebound, enabled, d}; !reflect.DeepEqual(activate, want) {
+		t.Errorf("activate = %v, want %v", activate, want)
+	}
+
+	// Removed and disabled presets are only deactivated.
+	disabled := b
+	disabled.Enabled = false
+	deactivate, activate = presetChanges([]config.Preset{a, b}, []config.Preset{disabled})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(deactivate, want) {
+		t.Errorf("deactivate = %v, want %v", deactivate, want)
+	}
+	if len(activate) != 0 {
+		t.Errorf("activate = %v, want none", activate)
+	}
+}
+
+func TestConfigEditOverPresetCap(t *testing.T) {
+	useTempConfig(t)
+	current := &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}}}
+	s := &PresetService{cfg: current, states: map[string]string{"a": "idle"}}
+
+	edited := &config.AppConfig{MaxPresets: 2, Presets: []config.Preset{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	if err := config.Save(edited); err != nil {
+		t.Fatal(err)
+	}
+	s.onConfigChanged()
+	if s.cfg != current || len(s.states) != 1 {
+		t.Errorf("edit over the preset cap applied: %d presets, states %v", len(s.cfg.Presets), s.states)
+	}
+
+	edited.Presets = edited.Presets[:2]
+	if err := config.Save(edited); err != nil {
+		t.Fatal(err)
+	}
+	s.onConfigChanged()
+	if len(s.cfg.Presets) != 2 {
+		t.Errorf("edit within the cap: %d presets, want 2", len(s.cfg.Presets))
+	}
+}
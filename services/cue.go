@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/gen2brain/malgo"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// cueKind selects one of the short feedback tones played around a recording.
+type cueKind int
+
+const (
+	cueStart   cueKind = iota // recording started: rising two-tone
+	cueStop                   // recording stopped: falling two-tone
+	cueDiscard                // nothing pasted (too short / silence): low buzz
+)
+
+const (
+	cueAmplitude = 0.25
+	cueFade      = 5 * time.Millisecond  // fade in/out to avoid clicks
+	cueDrain     = 80 * time.Millisecond // let the device play out its last buffer
+)
+
+// playCue plays a feedback tone if sound cues are enabled. It never blocks:
+// playback runs on its own goroutine and its own malgo context and playback
+// device, so it doesn't touch the capture device used by AudioCapture. The
+// returned channel is closed once the tone has played (at once with cues off),
+// for callers that must not record it.
+func playCue(kind cueKind) <-chan struct{} {
+	done := make(chan struct{})
+	if !soundCuesEnabled() {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("recovered panic in playCue: %v", r)
+			}
+		}()
+		if err := playPCM(cueTone(kind)); err != nil {
+			log.Printf("Sound cue failed: %v", err)
+		}
+	}()
+	return done
+}
+
+func soundCuesEnabled() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	return cfg.SoundCues
+}
+
+// cueTone synthesizes the PCM (16 kHz mono float32) for a cue.
+func cueTone(kind cueKind) []float32 {
+	switch kind {
+	case cueStart:
+		return append(sineTone(660, 70*time.Millisecond), sineTone(880, 70*time.Millisecond)...)
+	case cueStop:
+		return append(sineTone(880, 70*time.Millisecond), sineTone(660, 70*time.Millisecond)...)
+	default:
+		return sineTone(330, 180*time.Millisecond)
+	}
+}
+
+// sineTone returns a sine wave of freq Hz lasting d, with short linear fades.
+func sineTone(freq float64, d time.Duration) []float32 {
+	n := int(d * sampleRate / time.Second)
+	fade := int(cueFade * sampleRate / time.Second)
+	pcm := make([]float32, n)
+	for i := range pcm {
+		env := 1.0
+		if i < fade {
+			env = float64(i) / float64(fade)
+		} else if n-1-i < fade {
+			env = float64(n-1-i) / float64(fade)
+		}
+		pcm[i] = float32(cueAmplitude * env * math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+	}
+	return pcm
+}
+
+// playPCM plays 16 kHz mono float32 samples on the default output device and
+// returns when playback is done.
+func playPCM(pcm []float32) error {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return fmt.Errorf("malgo init context: %w", err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = channels
+	deviceConfig.SampleRate = sampleRate
+
+	pos := 0
+	done := make(chan struct{})
+	var doneOnce sync.Once
+	onSendFrames := func(outputSamples, _ []byte, frameCount uint32) {
+		if len(outputSamples) < 4 {
+			return
+		}
+		out := unsafe.Slice((*float32)(unsafe.Pointer(&outputSamples[0])), len(outputSamples)/4)
+		n := copy(out, pcm[pos:])
+		clear(out[n:])
+		pos += n
+		if pos >= len(pcm) {
+			doneOnce.Do(func() { close(done) })
+		}
+	}
+
+	device, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{Data: onSendFrames})
+	if err != nil {
+		return fmt.Errorf("malgo init playback device: %w", err)
+	}
+	defer device.Uninit()
+
+	if err := device.Start(); err != nil {
+		return fmt.Errorf("malgo start playback device: %w", err)
+	}
+	select {
+	case <-done:
+		time.Sleep(cueDrain)
+	case <-time.After(time.Second):
+	}
+	return device.Stop()
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCueTone(t *testing.T) {
+	for _, kind := range []cueKind{cueStart, cueStop, cueDiscard} {
+		pcm := cueTone(kind)
+		if len(pcm) == 0 {
+			t.Fatalf("cue %d: empty tone", kind)
+		}
+		if dur := time.Duration(len(pcm)) * time.Second / sampleRate; dur > 250*time.Millisecond {
+			t.Errorf("cue %d lasts %v, want a short blip", kind, dur)
+		}
+		if peak := peakLevel(pcm); peak == 0 || peak > cueAmplitude+1e-6 {
+			t.Errorf("cue %d peak = %v, want (0, %v]", kind, peak, cueAmplitude)
+		}
+		if pcm[0] != 0 {
+			t.Errorf("cue %d should fade in from silence, first sample = %v", kind, pcm[0])
+		}
+	}
+
+	start, stop := cueTone(cueStart), cueTone(cueStop)
+	same := len(start) == len(stop)
+	for i := 0; same && i < len(start); i++ {
+		same = start[i] == stop[i]
+	}
+	if same {
+		t.Error("start and stop cues should sound different")
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"log"
+	"strings"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// defaultHallucinationPhrases are outputs whisper produces on silence, mostly
+// video-outro captions it was trained on, keyed by transcription language.
+// Matching is by substring of the lowercased text.
+var defaultHallucinationPhrases = map[string][]string{
+	"ru": {
+		"продолжение следует",
+		"субтитры сделал",
+		"субтитры делал",
+		"субтитры создан",
+		"спасибо за просмотр",
+		"спасибо за внимание",
+		"подписывайтесь на канал",
+		"до свидания",
+		"до новых встреч",
+		"благодарю за внимание",
+		"редактор субтитров",
+	},
+	"en": {
+		"thank you",
+		"thanks for watching",
+		"subscribe",
+		"like and subscribe",
+		"please subscribe",
+		"the end",
+		"to be continued",
+		"subtitles by",
+		"translated by",
+		"you",
+		"bye",
+	},
+	"de": {
+		"untertitel im auftrag des zdf",
+		"untertitelung des zdf",
+		"vielen dank fürs zuschauen",
+	},
+}
+
+// hallucinationFilter decides which whisper outputs are hallucinations on
+// silence. The zero value uses the built-in phrases and all rules.
+type hallucinationFilter struct {
+	phrases   []string // user list (HallucinationPhrases), matched in every language; nil = defaults
+	keepShort bool     // KeepShortOutput: no "3 letters or fewer" rule, single-word phrases ignored
+}
+
+// loadHallucinationFilter reads the filter settings from the config.
+func loadHallucinationFilter() hallucinationFilter {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+	}
+	return hallucinationFilter{
+		phrases:   cleanPhrases(cfg.HallucinationPhrases),
+		keepShort: cfg.KeepShortOutput,
+	}
+}
+
+// cleanPhrases lowercases and trims phrases and drops empty ones; nil if none
+// are left, so an empty list falls back to the defaults.
+func cleanPhrases(phrases []string) []string {
+	var out []string
+	for _, p := range phrases {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// phrasesFor returns the phrases checked for a transcription in lang: the
+// user's list if set, else the defaults of lang, or of every language when
+// lang is "auto" (whisper may drift into any of them on silence).
+func (f hallucinationFilter) phrasesFor(lang string) []string {
+	if f.phrases != nil {
+		return f.phrases
+	}
+	if list, ok := defaultHallucinationPhrases[lang]; ok {
+		return list
+	}
+	if lang != "" && lang != "auto" {
+		return nil
+	}
+	var all []string
+	for _, list := range defaultHallucinationPhrases {
+		all = append(all, list...)
+	}
+	return all
+}
+
+// match reports whether text, transcribed in lang, is a hallucination.
+// Output that is only punctuation or music notes always is.
+func (f hallucinationFilter) match(text, lang string) bool {
+	if text == "" {
+		return false
+	}
+	lower := strings.ToLower(strings.TrimSpace(text))
+
+	// Pure punctuation / ellipsis / musical notes
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '.' || r == ',' || r == '!' || r == '?' || r == '-' ||
+			r == '…' || r == ' ' || r == '\n' || r == '\t' ||
+			r == '♪' || r == '♫' || r == '🎵' || r == '*' {
+			return -1
+		}
+		return r
+	}, lower)
+	if cleaned == "" {
+		return true
+	}
+
+	// Known hallucination phrases (whisper on silence)
+	for _, h := range f.phrasesFor(lang) {
+		if f.keepShort && !strings.Contains(h, " ") {
+			continue // "you", "bye" are also real answers
+		}
+		if strings.Contains(lower, h) {
+			return true
+		}
+	}
+
+	// Very short output (1-2 words) that's just filler
+	if !f.keepShort && len([]rune(cleaned)) <= 3 {
+		return true
+	}
+
+	return false
+}
+
+// isHallucination detects common whisper hallucinations produced on silence,
+// with the default settings and every language's phrases.
+func isHallucination(text string) bool {
+	return hallucinationFilter{}.match(text, "auto")
+}
@@ -0,0 +1,42 @@
+package services
+
+import "testing"
+
+func TestHallucinationFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter hallucinationFilter
+		text   string
+		lang   string
+		want   bool
+	}{
+		{"default phrase", hallucinationFilter{}, "Thanks for watching!", "en", true},
+		{"other language's phrase", hallucinationFilter{}, "Thanks for watching!", "ru", false},
+		{"auto checks every language", hallucinationFilter{}, "Продолжение следует...", "auto", true},
+		{"language without a list", hallucinationFilter{}, "Thanks for watching!", "fr", false},
+		{"user phrase", hallucinationFilter{phrases: []string{"merci d'avoir regardé"}}, "Merci d'avoir regardé !", "fr", true},
+		{"user list replaces defaults", hallucinationFilter{phrases: []string{"merci d'avoir regardé"}}, "Thanks for watching, folks", "en", false},
+		{"short output dropped", hallucinationFilter{}, "Bye.", "en", true},
+		{"keepShort keeps bye", hallucinationFilter{keepShort: true}, "Bye.", "en", false},
+		{"keepShort keeps ok", hallucinationFilter{keepShort: true}, "Ok", "en", false},
+		{"keepShort still drops phrases", hallucinationFilter{keepShort: true}, "Thank you.", "en", true},
+		{"punctuation always dropped", hallucinationFilter{keepShort: true}, "... ♪", "en", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(tt.text, tt.lang); got != tt.want {
+				t.Errorf("match(%q, %q) = %v, want %v", tt.text, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanPhrases(t *testing.T) {
+	if got := cleanPhrases([]string{" ", ""}); got != nil {
+		t.Errorf("cleanPhrases(blank) = %q, want nil (defaults)", got)
+	}
+	got := cleanPhrases([]string{"  Thanks For Watching ", "", "SUBSCRIBE"})
+	if len(got) != 2 || got[0] != "thanks for watching" || got[1] != "subscribe" {
+		t.Errorf("cleanPhrases = %q", got)
+	}
+}
@@ -28,11 +28,48 @@ func (s *HistoryService) GetHistory() []config.HistoryEntry {
 	return entries
 }
 
-// AddEntry saves a new transcription result.
+// HistorySearchResult is a filtered history view; Total lets the UI show
+// "3 of 50".
+type HistorySearchResult struct {
+	Entries []config.HistoryEntry `json:"entries"` // newest first
+	Matches int                   `json:"matches"`
+	Total   int                   `json:"total"`
+}
+
+// SearchHistory returns entries whose text contains every word of query
+// (case-insensitive), newest first.
+func (s *HistoryService) SearchHistory(query string) HistorySearchResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, _ := config.LoadHistory()
+	matches := config.SearchHistory(entries, query)
+	return HistorySearchResult{Entries: matches, Matches: len(matches), Total: len(entries)}
+}
+
+// FilterHistory narrows SearchHistory by language and time: entries matching
+// query, recorded in lang, between sinceMs and untilMs (Unix milliseconds),
+// newest first. Empty lang and zero bounds are ignored, so the UI can call it
+// as the user types with only the filters that are set.
+func (s *HistoryService) FilterHistory(query, lang string, sinceMs, untilMs int64) []config.HistoryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, _ := config.LoadHistory()
+	return config.FilterHistory(config.SearchHistory(entries, query), lang, sinceMs, untilMs)
+}
+
+// AddEntry saves a new transcription result, keeping at most the configured
+// historyLimit entries. With historyLimit 0 nothing is saved.
 func (s *HistoryService) AddEntry(text, language string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if err := config.AppendHistory(text, language); err != nil {
+	cfg, _ := config.Load()
+	limit := config.HistoryLimit(cfg)
+	if limit == 0 {
+		return nil
+	}
+	if err := config.AppendHistory(text, language, limit); err != nil {
 		return err
 	}
 	if app := application.Get(); app != nil {
@@ -55,6 +92,14 @@ func (s *HistoryService) DeleteEntry(timestamp int64) error {
 	return config.DeleteHistoryEntry(timestamp)
 }
 
+// SetPinned pins or unpins an entry; pinned entries are kept when the history
+// is trimmed to its limit.
+func (s *HistoryService) SetPinned(timestamp int64, pinned bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return config.SetHistoryPinned(timestamp, pinned)
+}
+
 // OpenHistoryWindow opens a separate window to display transcription history.
 func (s *HistoryService) OpenHistoryWindow() {
 	app := application.Get()
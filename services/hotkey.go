@@ -6,15 +6,24 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
-// HotkeyManager manages global hotkey registrations using a platform keyboard hook.
+// doublePressWindow is how long a hold-mode release is held back waiting for a
+// re-press that cancels the recording (only for bindings with double-press cancel).
+const doublePressWindow = 300 * time.Millisecond
+
+// HotkeyManager manages global hotkey registrations using platform keyboard and mouse hooks.
 // Single event loop processes both hotkey matching and key capture.
 type HotkeyManager struct {
 	mu        sync.Mutex
 	active    map[string]*hotkeyBinding // presetID → binding
 	onPress   func(presetID string)
 	onRelease func(presetID string)
+	onCancel  func(presetID string)
+
+	doublePressWindow time.Duration
+	keyDownSeq        uint64 // numbers key-down events, see PressSeq
 
 	// Event loop
 	running bool
@@ -30,19 +39,35 @@ type HotkeyManager struct {
 }
 
 type hotkeyBinding struct {
-	keys    []uint16 // sorted VK codes
-	mode    string   // "hold" | "toggle"
-	pressed bool     // currently matched
+	keys    []uint16        // sorted VK codes
+	anySide map[uint16]bool // generic modifiers ("ctrl"): the right-hand key matches too
+	mode    string          // "hold" | "toggle" | "tap"
+	pressed bool            // currently matched
+	seq     uint64          // key-down event that last pressed it (PressSeq)
+
+	// Double-press cancel (hold mode only): a release is deferred by
+	// doublePressWindow; a re-press inside the window cancels instead.
+	doublePressCancel bool
+	releaseTimer      *time.Timer // pending deferred release
+	swallowRelease    bool        // the cancelling press: ignore its release
 }
 
 func NewHotkeyManager(onPress, onRelease func(presetID string)) *HotkeyManager {
 	return &HotkeyManager{
-		active:    make(map[string]*hotkeyBinding),
-		onPress:   onPress,
-		onRelease: onRelease,
+		active:            make(map[string]*hotkeyBinding),
+		onPress:           onPress,
+		onRelease:         onRelease,
+		doublePressWindow: doublePressWindow,
 	}
 }
 
+// SetOnCancel sets the callback fired when a double-press cancels a hold-mode recording.
+func (m *HotkeyManager) SetOnCancel(fn func(presetID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onCancel = fn
+}
+
 // SetOnHookStatus sets a callback for reporting hook installation status.
 func (m *HotkeyManager) SetOnHookStatus(fn func(bool, string)) {
 	m.mu.Lock()
@@ -91,7 +116,7 @@ func (m *HotkeyManager) Stop() {
 
 // Register adds a hotkey binding for a preset.
 func (m *HotkeyManager) Register(presetID, hotkeyStr, mode string) error {
-	keys, err := parseHotkeyStr(hotkeyStr)
+	keys, anySide, err := parseHotkey(hotkeyStr)
 	if err != nil {
 		return fmt.Errorf("parse hotkey %q: %w", hotkeyStr, err)
 	}
@@ -99,19 +124,118 @@ func (m *HotkeyManager) Register(presetID, hotkeyStr, mode string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.active[presetID] = &hotkeyBinding{keys: keys, mode: mode}
+	m.active[presetID] = &hotkeyBinding{keys: keys, anySide: anySide, mode: mode}
 	log.Printf("Hotkey registered: %q for preset %s (mode=%s)", hotkeyStr, presetID, mode)
 	return nil
 }
 
+// SetDoublePressCancel enables cancel-on-re-press for a registered hold-mode binding.
+func (m *HotkeyManager) SetDoublePressCancel(presetID string, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.active[presetID]; ok {
+		b.doublePressCancel = enabled && b.mode == "hold"
+	}
+}
+
+// PressSeq returns the number of the key-down event that last pressed the
+// preset's binding, 0 if none did. Bindings pressed by the same key event
+// (presets sharing a hotkey) get the same number.
+func (m *HotkeyManager) PressSeq(presetID string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.active[presetID]; ok {
+		return b.seq
+	}
+	return 0
+}
+
+// IsPressed reports whether the preset's hotkey is currently held down.
+func (m *HotkeyManager) IsPressed(presetID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.active[presetID]
+	return ok && b.pressed
+}
+
 // Unregister removes a hotkey for a preset.
 func (m *HotkeyManager) Unregister(presetID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if b, ok := m.active[presetID]; ok && b.releaseTimer != nil {
+		b.releaseTimer.Stop()
+	}
 	delete(m.active, presetID)
 	log.Printf("Hotkey unregistered for preset %s", presetID)
 }
 
+// hotkeyConflict is another preset's binding that collides with a combo.
+type hotkeyConflict struct {
+	PresetID string
+	Subset   bool // strict subset/superset (ctrl+a vs ctrl+shift+a) rather than identical
+}
+
+// FindConflicts checks keys against the bindings of all other registered presets.
+// anySide are the generic modifiers of keys (see parseHotkey); "ctrl+f1" and
+// "rctrl+f1" count as identical. Identical combos are listed before
+// subsets/supersets, each group ordered by preset ID.
+func (m *HotkeyManager) FindConflicts(presetID string, keys []uint16, anySide map[uint16]bool) []hotkeyConflict {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.active))
+	for id := range m.active {
+		if id != presetID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids) // deterministic order when several presets collide
+
+	var identical, subsets []hotkeyConflict
+	for _, id := range ids {
+		other := m.active[id]
+		switch {
+		case keySetEqual(keys, anySide, other.keys, other.anySide):
+			identical = append(identical, hotkeyConflict{PresetID: id})
+		case keySubset(keys, anySide, other.keys, other.anySide) || keySubset(other.keys, other.anySide, keys, anySide):
+			subsets = append(subsets, hotkeyConflict{PresetID: id, Subset: true})
+		}
+	}
+	return append(identical, subsets...)
+}
+
+// FindConflict returns the first of FindConflicts ("" if none) and whether the
+// collision is a strict subset/superset rather than an identical combo.
+func (m *HotkeyManager) FindConflict(presetID string, keys []uint16, anySide map[uint16]bool) (string, bool) {
+	conflicts := m.FindConflicts(presetID, keys, anySide)
+	if len(conflicts) == 0 {
+		return "", false
+	}
+	return conflicts[0].PresetID, conflicts[0].Subset
+}
+
+// consumesKey reports whether kc is part of an active binding or a hotkey is
+// being captured. Used by the hook to swallow media keys only when they are ours.
+func (m *HotkeyManager) consumesKey(kc uint16) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.capturing {
+		return true
+	}
+	for _, b := range m.active {
+		for _, k := range b.keys {
+			if k == kc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // UnregisterAll removes all hotkey bindings.
 func (m *HotkeyManager) UnregisterAll() {
 	m.mu.Lock()
@@ -154,19 +278,22 @@ type keyEvent struct {
 	down bool
 }
 
-// eventLoop runs the keyboard hook and processes key events.
+// eventLoop runs the keyboard/mouse hook and processes key events.
+// Mouse buttons arrive as synthetic vkMouse* codes and are handled like keys.
 func (m *HotkeyManager) eventLoop() {
 	keyCh := make(chan keyEvent, 256)
 	pressedKeys := make(map[uint16]bool)
 
 	// onKey is called from the hook thread — must return fast.
 	// Sends to a buffered channel (non-blocking) to avoid holding up the hook.
-	onKey := func(vk uint16, down bool) {
+	// Bound media keys are swallowed so the player doesn't also act on them.
+	onKey := func(vk uint16, down bool) bool {
 		select {
 		case keyCh <- keyEvent{vk, down}:
 		default:
 			// Channel full — drop event (shouldn't happen with 256 buffer)
 		}
+		return isMediaKey(vk) && m.consumesKey(vk)
 	}
 
 	onInstalled := func(err error) {
@@ -224,9 +351,20 @@ func (m *HotkeyManager) handleKeyDown(kc uint16, pressedKeys map[uint16]bool) {
 		return
 	}
 
+	m.keyDownSeq++
 	for id, b := range m.active {
-		if !b.pressed && matchBinding(b.keys, pressedKeys) {
+		if !b.pressed && matchBinding(b.keys, b.anySide, pressedKeys) {
 			b.pressed = true
+			b.seq = m.keyDownSeq
+			// Re-press while a release is still deferred → cancel the recording.
+			if b.releaseTimer != nil && b.releaseTimer.Stop() {
+				b.releaseTimer = nil
+				b.swallowRelease = true
+				if m.onCancel != nil {
+					go m.onCancel(id)
+				}
+				continue
+			}
 			if m.onPress != nil {
 				go m.onPress(id)
 			}
@@ -244,8 +382,16 @@ func (m *HotkeyManager) handleKeyUp(kc uint16, pressedKeys map[uint16]bool) {
 	}
 
 	for id, b := range m.active {
-		if b.pressed && !matchBinding(b.keys, pressedKeys) {
+		if b.pressed && !matchBinding(b.keys, b.anySide, pressedKeys) {
 			b.pressed = false
+			if b.swallowRelease {
+				b.swallowRelease = false
+				continue
+			}
+			if b.doublePressCancel {
+				m.deferRelease(id, b)
+				continue
+			}
 			if m.onRelease != nil {
 				go m.onRelease(id)
 			}
@@ -253,6 +399,25 @@ func (m *HotkeyManager) handleKeyUp(kc uint16, pressedKeys map[uint16]bool) {
 	}
 }
 
+// deferRelease fires onRelease after doublePressWindow unless a re-press cancels it.
+// Must be called with m.mu held.
+func (m *HotkeyManager) deferRelease(id string, b *hotkeyBinding) {
+	var t *time.Timer
+	t = time.AfterFunc(m.doublePressWindow, func() {
+		m.mu.Lock()
+		fire := b.releaseTimer == t && m.active[id] == b
+		if b.releaseTimer == t {
+			b.releaseTimer = nil
+		}
+		fn := m.onRelease
+		m.mu.Unlock()
+		if fire && fn != nil {
+			fn(id)
+		}
+	})
+	b.releaseTimer = t
+}
+
 // captureKeyDown handles key presses during capture mode.
 func (m *HotkeyManager) captureKeyDown(kc uint16, pressedKeys map[uint16]bool) {
 	// Escape cancels capture
@@ -263,6 +428,11 @@ func (m *HotkeyManager) captureKeyDown(kc uint16, pressedKeys map[uint16]bool) {
 		return
 	}
 
+	// Clicking in the UI must not end up as the captured hotkey.
+	if isPrimaryMouse(kc) {
+		return
+	}
+
 	if isModifier(kc) {
 		// Accumulate modifiers for modifier-only capture
 		if m.captureKeys == nil {
@@ -275,7 +445,7 @@ func (m *HotkeyManager) captureKeyDown(kc uint16, pressedKeys map[uint16]bool) {
 	// Non-modifier pressed → finalize with all currently held keys
 	keys := make([]uint16, 0, len(pressedKeys))
 	for pk := range pressedKeys {
-		if pk != vkEscape {
+		if pk != vkEscape && !isPrimaryMouse(pk) {
 			keys = append(keys, pk)
 		}
 	}
@@ -312,23 +482,99 @@ func (m *HotkeyManager) finishCapture(keys []uint16) {
 	m.captureCh <- hotkeyStr
 }
 
-// matchBinding returns true if all binding keys are currently pressed.
-func matchBinding(bindingKeys []uint16, pressedKeys map[uint16]bool) bool {
+// matchBinding returns true if all binding keys are currently pressed. A key
+// in anySide (a generic "ctrl") is also satisfied by its right-hand twin.
+func matchBinding(bindingKeys []uint16, anySide map[uint16]bool, pressedKeys map[uint16]bool) bool {
 	if len(bindingKeys) == 0 {
 		return false
 	}
 	for _, kc := range bindingKeys {
-		if !pressedKeys[kc] {
+		if pressedKeys[kc] {
+			continue
+		}
+		if anySide[kc] && pressedKeys[rightModifier[kc]] {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// keysOverlap reports whether key a of one binding and key b of another are
+// satisfied by the same key press: the same key, or a modifier given by its
+// generic name (anySide) and its right-hand key.
+func keysOverlap(a uint16, aAnySide map[uint16]bool, b uint16, bAnySide map[uint16]bool) bool {
+	return a == b || (aAnySide[a] && rightModifier[a] == b) || (bAnySide[b] && rightModifier[b] == a)
+}
+
+// keysCovered reports whether every key of a overlaps a different key of b,
+// so pressing b also satisfies a.
+func keysCovered(a []uint16, aAnySide map[uint16]bool, b []uint16, bAnySide map[uint16]bool) bool {
+	used := make([]bool, len(b))
+	for _, kc := range a {
+		found := false
+		for i, o := range b {
+			if !used[i] && keysOverlap(kc, aAnySide, o, bAnySide) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
 			return false
 		}
 	}
 	return true
 }
 
+// keySetEqual reports whether two sorted key sets can be pressed as the same
+// combo, e.g. "ctrl+f1" and "rctrl+f1".
+func keySetEqual(a []uint16, aAnySide map[uint16]bool, b []uint16, bAnySide map[uint16]bool) bool {
+	return len(a) == len(b) && keysCovered(a, aAnySide, b, bAnySide)
+}
+
+// keySubset reports whether a is a strict subset of b, side-aware like
+// keySetEqual.
+func keySubset(a []uint16, aAnySide map[uint16]bool, b []uint16, bAnySide map[uint16]bool) bool {
+	return len(a) > 0 && len(a) < len(b) && keysCovered(a, aAnySide, b, bAnySide)
+}
+
 // --- VK code constants and maps ---
 
 const vkEscape = 0x1B
 
+// Synthetic codes for mouse buttons. They sit above the 0x00-0xFF VK range so
+// they never collide with keyboard keys in a binding's key set.
+const (
+	vkMouse1 uint16 = 0x101 + iota // left
+	vkMouse2                       // right
+	vkMouse3                       // middle
+	vkMouse4                       // X1 (back)
+	vkMouse5                       // X2 (forward)
+)
+
+// isPrimaryMouse reports whether kc is the left or right mouse button.
+// Those are used to operate the UI, so capture mode never records them.
+func isPrimaryMouse(kc uint16) bool {
+	return kc == vkMouse1 || kc == vkMouse2
+}
+
+// Windows Virtual Key codes for media keys. Bluetooth headset buttons
+// (play/pause, next, previous, volume) usually arrive as these.
+const (
+	vkVolumeMute     = 0xAD
+	vkVolumeDown     = 0xAE
+	vkVolumeUp       = 0xAF
+	vkMediaNext      = 0xB0
+	vkMediaPrev      = 0xB1
+	vkMediaStop      = 0xB2
+	vkMediaPlayPause = 0xB3
+)
+
+// isMediaKey reports whether kc is a media or volume key.
+func isMediaKey(kc uint16) bool {
+	return kc >= vkVolumeMute && kc <= vkMediaPlayPause
+}
+
 // Windows Virtual Key codes for modifiers.
 var modifierVKCodes = map[uint16]bool{
 	0xA0: true, // VK_LSHIFT
@@ -408,6 +654,13 @@ var vkToName = map[uint16]string{
 	0xBE: ".",  // VK_OEM_PERIOD
 	0xBF: "/",  // VK_OEM_2
 	0xC0: "`",  // VK_OEM_3
+	// Media keys (headset buttons, see isMediaKey)
+	vkMediaPlayPause: "playpause", vkMediaNext: "nexttrack",
+	vkMediaPrev: "prevtrack", vkMediaStop: "mediastop",
+	vkVolumeMute: "volumemute", vkVolumeDown: "volumedown", vkVolumeUp: "volumeup",
+	// Mouse buttons (synthetic codes, see vkMouse1)
+	vkMouse1: "mouse1", vkMouse2: "mouse2", vkMouse3: "mouse3",
+	vkMouse4: "mouse4", vkMouse5: "mouse5",
 }
 
 // nameToVK is the reverse map, built at init.
@@ -428,29 +681,72 @@ func init() {
 	nameToVK["meta"] = 0x5B
 	nameToVK["win"] = 0x5B
 	nameToVK["option"] = 0xA4
+	nameToVK["mbutton"] = vkMouse3
+	nameToVK["xbutton1"] = vkMouse4
+	nameToVK["xbutton2"] = vkMouse5
+	nameToVK["play"] = vkMediaPlayPause
+	nameToVK["next"] = vkMediaNext
+	nameToVK["prev"] = vkMediaPrev
+	nameToVK["mute"] = vkVolumeMute
+}
+
+// rightModifier maps each left modifier VK code to its right-hand twin.
+var rightModifier = map[uint16]uint16{
+	0xA2: 0xA3, // ctrl
+	0xA0: 0xA1, // shift
+	0xA4: 0xA5, // alt
+	0x5B: 0x5C, // super
+}
+
+// leftModifierNames pin a modifier to the left key. The generic names ("ctrl",
+// "shift", "alt", "super" and their aliases) accept either side; "rctrl" etc.
+// only the right one.
+var leftModifierNames = map[string]uint16{
+	"lctrl":  0xA2,
+	"lshift": 0xA0,
+	"lalt":   0xA4,
+	"lsuper": 0x5B,
 }
 
 // parseHotkeyStr parses "ctrl+shift+a" into sorted VK codes.
 func parseHotkeyStr(s string) ([]uint16, error) {
+	keys, _, err := parseHotkey(s)
+	return keys, err
+}
+
+// parseHotkey is parseHotkeyStr that also reports which modifiers were given
+// by their generic name and so match either side (see matchBinding).
+func parseHotkey(s string) ([]uint16, map[uint16]bool, error) {
 	s = strings.TrimSpace(strings.ToLower(s))
 	if s == "" {
-		return nil, fmt.Errorf("empty hotkey string")
+		return nil, nil, fmt.Errorf("empty hotkey string")
 	}
 
 	parts := strings.Split(s, "+")
 	keys := make([]uint16, 0, len(parts))
+	var anySide map[uint16]bool
 
 	for _, p := range parts {
 		p = strings.TrimSpace(p)
+		if kc, ok := leftModifierNames[p]; ok {
+			keys = append(keys, kc)
+			continue
+		}
 		kc, ok := nameToVK[p]
 		if !ok {
-			return nil, fmt.Errorf("unknown key: %q", p)
+			return nil, nil, fmt.Errorf("unknown key: %q", p)
+		}
+		if _, generic := rightModifier[kc]; generic {
+			if anySide == nil {
+				anySide = make(map[uint16]bool)
+			}
+			anySide[kc] = true
 		}
 		keys = append(keys, kc)
 	}
 
 	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
-	return keys, nil
+	return keys, anySide, nil
 }
 
 // modifierOrder defines display order: ctrl, shift, alt, super.
@@ -0,0 +1,243 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// On Linux global hotkeys come from evdev: key events are read straight from
+// /dev/input/event*, which works the same under X11 and every Wayland
+// compositor. It is opt-in (hotkeyBackend: "evdev") because reading input
+// devices needs permission — usually membership in the "input" group:
+//
+//	sudo usermod -aG input $USER   # then log out and back in
+//
+// Devices are opened read-only and not grabbed, so keys can't be swallowed
+// (bound media keys also reach the player) and devices plugged in after
+// startup are not picked up until the app restarts.
+
+const (
+	evKey = 0x01 // EV_KEY event type
+
+	procInputDevices = "/proc/bus/input/devices"
+)
+
+// evdevEventSize is sizeof(struct input_event): a timeval, then type, code, value.
+var evdevEventSize = int(unsafe.Sizeof(syscall.Timeval{})) + 8
+
+// evdevHook holds the state of the running evdev hook.
+var evdevHook struct {
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// hotkeyBackend returns the configured hotkey backend ("" = platform default).
+func hotkeyBackend() string {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return ""
+	}
+	return cfg.HotkeyBackend
+}
+
+// startHook reads key events from every evdev device that reports keys or
+// buttons. Blocks until stopHook() is called. onKey's return value is ignored:
+// evdev readers can't swallow events.
+// onInstalled is called once after the devices are opened (nil error = success).
+func startHook(onKey func(vk uint16, down bool) bool, onInstalled func(error)) error {
+	if hotkeyBackend() != "evdev" {
+		err := fmt.Errorf(`no global keyboard hook on Linux by default: set "hotkeyBackend": "evdev" in config.json (needs read access to /dev/input, e.g. the input group)`)
+		onInstalled(err)
+		return err
+	}
+
+	f, err := os.Open(procInputDevices)
+	if err != nil {
+		onInstalled(err)
+		return err
+	}
+	paths := evdevKeyDevices(f)
+	f.Close()
+
+	var files []*os.File
+	var openErr error
+	for _, p := range paths {
+		dev, err := os.Open(p)
+		if err != nil {
+			openErr = err
+			continue
+		}
+		files = append(files, dev)
+	}
+	if len(files) == 0 {
+		err := fmt.Errorf("no readable input device in /dev/input (add your user to the input group): %v", openErr)
+		onInstalled(err)
+		return err
+	}
+	log.Printf("evdev hook: reading %d of %d input devices", len(files), len(paths))
+
+	done := make(chan struct{})
+	evdevHook.mu.Lock()
+	evdevHook.done = done
+	evdevHook.mu.Unlock()
+	onInstalled(nil)
+
+	var wg sync.WaitGroup
+	for _, dev := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readEvdev(dev, onKey)
+		}()
+	}
+	<-done
+	// Closing the files unblocks the pending reads.
+	for _, dev := range files {
+		dev.Close()
+	}
+	wg.Wait()
+	return nil
+}
+
+// stopHook ends a running startHook. Non-blocking.
+func stopHook() {
+	evdevHook.mu.Lock()
+	defer evdevHook.mu.Unlock()
+	if evdevHook.done != nil {
+		close(evdevHook.done)
+		evdevHook.done = nil
+	}
+}
+
+// readEvdev forwards key events from one device until it is closed or unplugged.
+// Autorepeat (value 2) is passed on as a key down, like Windows repeats.
+func readEvdev(dev *os.File, onKey func(vk uint16, down bool) bool) {
+	buf := make([]byte, evdevEventSize*64)
+	for {
+		n, err := dev.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Printf("evdev hook: %s: %v", dev.Name(), err)
+			}
+			return
+		}
+		for off := 0; off+evdevEventSize <= n; off += evdevEventSize {
+			typ, code, value := parseEvdevEvent(buf[off : off+evdevEventSize])
+			if typ != evKey {
+				continue
+			}
+			if vk, ok := evdevToVK[code]; ok {
+				onKey(vk, value != 0)
+			}
+		}
+	}
+}
+
+// parseEvdevEvent decodes type, code and value from a struct input_event.
+func parseEvdevEvent(b []byte) (typ, code uint16, value int32) {
+	tv := len(b) - 8
+	return binary.NativeEndian.Uint16(b[tv:]),
+		binary.NativeEndian.Uint16(b[tv+2:]),
+		int32(binary.NativeEndian.Uint32(b[tv+4:]))
+}
+
+// evdevKeyDevices lists the /dev/input/event* nodes of the devices in a
+// /proc/bus/input/devices listing that report EV_KEY (keyboards, mice,
+// headset and media buttons).
+func evdevKeyDevices(r io.Reader) []string {
+	var paths []string
+	var handler string
+	var hasKeys bool
+	flush := func() {
+		if handler != "" && hasKeys {
+			paths = append(paths, filepath.Join("/dev/input", handler))
+		}
+		handler, hasKeys = "", false
+	}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "H: Handlers="):
+			for _, h := range strings.Fields(strings.TrimPrefix(line, "H: Handlers=")) {
+				if strings.HasPrefix(h, "event") {
+					handler = h
+				}
+			}
+		case strings.HasPrefix(line, "B: EV="):
+			bits, err := strconv.ParseUint(strings.TrimPrefix(line, "B: EV="), 16, 64)
+			hasKeys = err == nil && bits&(1<<evKey) != 0
+		}
+	}
+	flush()
+	return paths
+}
+
+// evdevToVK maps Linux input key codes (linux/input-event-codes.h) to the
+// Windows VK codes HotkeyManager works with.
+var evdevToVK = map[uint16]uint16{
+	// Letters (KEY_Q..KEY_P, KEY_A..KEY_L, KEY_Z..KEY_M)
+	16: 0x51, 17: 0x57, 18: 0x45, 19: 0x52, 20: 0x54, 21: 0x59, 22: 0x55, 23: 0x49, 24: 0x4F, 25: 0x50,
+	30: 0x41, 31: 0x53, 32: 0x44, 33: 0x46, 34: 0x47, 35: 0x48, 36: 0x4A, 37: 0x4B, 38: 0x4C,
+	44: 0x5A, 45: 0x58, 46: 0x43, 47: 0x56, 48: 0x42, 49: 0x4E, 50: 0x4D,
+	// Numbers (KEY_1..KEY_0)
+	2: 0x31, 3: 0x32, 4: 0x33, 5: 0x34, 6: 0x35, 7: 0x36, 8: 0x37, 9: 0x38, 10: 0x39, 11: 0x30,
+	// Function keys (KEY_F1..KEY_F10, KEY_F11, KEY_F12)
+	59: 0x70, 60: 0x71, 61: 0x72, 62: 0x73, 63: 0x74, 64: 0x75, 65: 0x76, 66: 0x77, 67: 0x78, 68: 0x79,
+	87: 0x7A, 88: 0x7B,
+	// Modifiers
+	29: 0xA2, 97: 0xA3, // KEY_LEFTCTRL, KEY_RIGHTCTRL
+	42: 0xA0, 54: 0xA1, // KEY_LEFTSHIFT, KEY_RIGHTSHIFT
+	56: 0xA4, 100: 0xA5, // KEY_LEFTALT, KEY_RIGHTALT
+	125: 0x5B, 126: 0x5C, // KEY_LEFTMETA, KEY_RIGHTMETA
+	// Special
+	1:  0x1B, // KEY_ESC
+	14: 0x08, // KEY_BACKSPACE
+	15: 0x09, // KEY_TAB
+	28: 0x0D, // KEY_ENTER
+	57: 0x20, // KEY_SPACE
+	// Arrows and navigation
+	103: 0x26, 108: 0x28, 105: 0x25, 106: 0x27, // KEY_UP, KEY_DOWN, KEY_LEFT, KEY_RIGHT
+	102: 0x24, 107: 0x23, 104: 0x21, 109: 0x22, // KEY_HOME, KEY_END, KEY_PAGEUP, KEY_PAGEDOWN
+	110: 0x2D, 111: 0x2E, // KEY_INSERT, KEY_DELETE
+	// Misc
+	58:  0x14, // KEY_CAPSLOCK
+	99:  0x2C, // KEY_SYSRQ (print screen)
+	119: 0x13, // KEY_PAUSE
+	// Numpad
+	71: 0x67, 72: 0x68, 73: 0x69,
+	75: 0x64, 76: 0x65, 77: 0x66,
+	79: 0x61, 80: 0x62, 81: 0x63,
+	82: 0x60,
+	74: 0x6D, 78: 0x6B, 55: 0x6A, // KEY_KPMINUS, KEY_KPPLUS, KEY_KPASTERISK
+	98: 0x6F, 96: 0x0E, // KEY_KPSLASH, KEY_KPENTER ("numenter")
+	// Symbols
+	12: 0xBD, 13: 0xBB, // KEY_MINUS, KEY_EQUAL
+	26: 0xDB, 27: 0xDD, 43: 0xDC, // KEY_LEFTBRACE, KEY_RIGHTBRACE, KEY_BACKSLASH
+	39: 0xBA, 40: 0xDE, 41: 0xC0, // KEY_SEMICOLON, KEY_APOSTROPHE, KEY_GRAVE
+	51: 0xBC, 52: 0xBE, 53: 0xBF, // KEY_COMMA, KEY_DOT, KEY_SLASH
+	// Media keys; KEY_PLAYCD/KEY_PAUSECD come from some headsets
+	164: vkMediaPlayPause, 200: vkMediaPlayPause, 201: vkMediaPlayPause,
+	163: vkMediaNext, 165: vkMediaPrev, 166: vkMediaStop,
+	113: vkVolumeMute, 114: vkVolumeDown, 115: vkVolumeUp,
+	// Mouse buttons (BTN_LEFT, BTN_RIGHT, BTN_MIDDLE, BTN_SIDE, BTN_EXTRA)
+	0x110: vkMouse1, 0x111: vkMouse2, 0x112: vkMouse3, 0x113: vkMouse4, 0x114: vkMouse5,
+}
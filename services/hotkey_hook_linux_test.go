@@ -0,0 +1,53 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvdevKeyDevices(t *testing.T) {
+	const listing = `I: Bus=0011 Vendor=0001 Product=0001 Version=ab41
+N: Name="AT Translated Set 2 keyboard"
+H: Handlers=sysrq kbd event3 leds
+B: EV=120013
+
+I: Bus=0003 Vendor=046d Product=c52b Version=0111
+N: Name="Logitech USB Receiver Mouse"
+H: Handlers=mouse0 event5
+B: EV=17
+
+I: Bus=0019 Vendor=0000 Product=0000 Version=0000
+N: Name="Accelerometer"
+H: Handlers=event7
+B: EV=9
+`
+	got := evdevKeyDevices(strings.NewReader(listing))
+	if fmt.Sprint(got) != "[/dev/input/event3 /dev/input/event5]" {
+		t.Errorf("evdevKeyDevices = %v, want event3 and event5", got)
+	}
+}
+
+func TestParseEvdevEvent(t *testing.T) {
+	b := make([]byte, evdevEventSize)
+	tv := evdevEventSize - 8
+	binary.NativeEndian.PutUint16(b[tv:], evKey)
+	binary.NativeEndian.PutUint16(b[tv+2:], 29) // KEY_LEFTCTRL
+	binary.NativeEndian.PutUint32(b[tv+4:], 1)
+	if typ, code, value := parseEvdevEvent(b); typ != evKey || code != 29 || value != 1 {
+		t.Errorf("parseEvdevEvent = %d, %d, %d; want %d, 29, 1", typ, code, value, evKey)
+	}
+}
+
+func TestEvdevToVKNamed(t *testing.T) {
+	// Every translated key must have a hotkey name, or capture would drop it.
+	for code, vk := range evdevToVK {
+		if _, ok := vkToName[vk]; !ok {
+			t.Errorf("evdev code %d maps to VK 0x%X, which has no name", code, vk)
+		}
+	}
+	if got := keysToString([]uint16{evdevToVK[42], evdevToVK[29], evdevToVK[59]}); got != "ctrl+shift+f1" {
+		t.Errorf("KEY_LEFTSHIFT+KEY_LEFTCTRL+KEY_F1 = %q, want ctrl+shift+f1", got)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"unsafe"
 )
 
-// Win32 API procs for keyboard hook.
+// Win32 API procs for keyboard and mouse hooks.
 // user32 and kern32 are already defined in paste_windows.go.
 var (
 	pSetWindowsHookExW   = user32.NewProc("SetWindowsHookExW")
@@ -24,11 +24,23 @@ var (
 
 const (
 	whKeyboardLL = 13
+	whMouseLL    = 14
 	wmKeyDown    = 0x0100
 	wmKeyUp      = 0x0101
 	wmSysKeyDown = 0x0104
 	wmSysKeyUp   = 0x0105
 	wmQuit       = 0x0012
+
+	wmLButtonDown = 0x0201
+	wmLButtonUp   = 0x0202
+	wmRButtonDown = 0x0204
+	wmRButtonUp   = 0x0205
+	wmMButtonDown = 0x0207
+	wmMButtonUp   = 0x0208
+	wmXButtonDown = 0x020B
+	wmXButtonUp   = 0x020C
+	xButton1      = 0x0001
+	xButton2      = 0x0002
 )
 
 // kbdLLHookStruct matches the Win32 KBDLLHOOKSTRUCT layout.
@@ -40,6 +52,15 @@ type kbdLLHookStruct struct {
 	DwExtraInfo uintptr
 }
 
+// msLLHookStruct matches the Win32 MSLLHOOKSTRUCT layout.
+type msLLHookStruct struct {
+	Pt          [2]int32
+	MouseData   uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
 // winMsg matches the Win32 MSG struct layout.
 type winMsg struct {
 	HWnd    uintptr
@@ -55,14 +76,16 @@ var hookState struct {
 	mu       sync.Mutex
 	threadID uint32
 	hhook    uintptr
-	onKey    func(vk uint16, down bool)
+	mhook    uintptr
+	onKey    func(vk uint16, down bool) bool
 }
 
-// startHook installs a low-level keyboard hook and runs the message pump.
+// startHook installs low-level keyboard and mouse hooks and runs the message pump.
 // Blocks until stopHook() is called. Must be called from a goroutine.
 // onKey is called from the hook thread for every key event — it must return fast.
+// Returning true swallows a keyboard event so no other application sees it.
 // onInstalled is called once after hook installation (nil error = success).
-func startHook(onKey func(vk uint16, down bool), onInstalled func(error)) error {
+func startHook(onKey func(vk uint16, down bool) bool, onInstalled func(error)) error {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
@@ -91,11 +114,23 @@ func startHook(onKey func(vk uint16, down bool), onInstalled func(error)) error
 		return e
 	}
 
+	// Mouse hook is optional: without it only mouse-button hotkeys stop working.
+	mhook, _, err := pSetWindowsHookExW.Call(
+		whMouseLL,
+		syscall.NewCallback(llMouseProc),
+		0,
+		0,
+	)
+	if mhook == 0 {
+		log.Printf("HotkeyHook: mouse hook failed, mouse-button hotkeys disabled: %v", err)
+	}
+
 	hookState.mu.Lock()
 	hookState.hhook = hhook
+	hookState.mhook = mhook
 	hookState.mu.Unlock()
 
-	log.Printf("HotkeyHook: installed (hhook=%#x, tid=%d)", hhook, tid)
+	log.Printf("HotkeyHook: installed (hhook=%#x, mhook=%#x, tid=%d)", hhook, mhook, tid)
 	if onInstalled != nil {
 		onInstalled(nil)
 	}
@@ -114,8 +149,12 @@ func startHook(onKey func(vk uint16, down bool), onInstalled func(error)) error
 
 	// Cleanup
 	pUnhookWindowsHookEx.Call(hhook)
+	if mhook != 0 {
+		pUnhookWindowsHookEx.Call(mhook)
+	}
 	hookState.mu.Lock()
 	hookState.hhook = 0
+	hookState.mhook = 0
 	hookState.threadID = 0
 	hookState.onKey = nil
 	hookState.mu.Unlock()
@@ -150,6 +189,45 @@ func llKeyboardProc(nCode int, wParam uintptr, lParam uintptr) uintptr {
 			fn := hookState.onKey
 			hookState.mu.Unlock()
 
+			if fn != nil && fn(vk, down) {
+				return 1 // swallowed
+			}
+		}
+	}
+
+	ret, _, _ := pCallNextHookEx.Call(0, uintptr(nCode), wParam, lParam)
+	return ret
+}
+
+// llMouseProc is the Win32 low-level mouse hook callback.
+// Button events are reported as synthetic vkMouse* codes; the event is never swallowed.
+func llMouseProc(nCode int, wParam uintptr, lParam uintptr) uintptr {
+	if nCode >= 0 && lParam != 0 {
+		var vk uint16
+		down := false
+		switch wParam {
+		case wmLButtonDown, wmLButtonUp:
+			vk, down = vkMouse1, wParam == wmLButtonDown
+		case wmRButtonDown, wmRButtonUp:
+			vk, down = vkMouse2, wParam == wmRButtonDown
+		case wmMButtonDown, wmMButtonUp:
+			vk, down = vkMouse3, wParam == wmMButtonDown
+		case wmXButtonDown, wmXButtonUp:
+			ms := (*msLLHookStruct)(unsafe.Pointer(lParam))
+			switch ms.MouseData >> 16 {
+			case xButton1:
+				vk = vkMouse4
+			case xButton2:
+				vk = vkMouse5
+			}
+			down = wParam == wmXButtonDown
+		}
+
+		if vk != 0 {
+			hookState.mu.Lock()
+			fn := hookState.onKey
+			hookState.mu.Unlock()
+
 			if fn != nil {
 				fn(vk, down)
 			}
@@ -3,6 +3,7 @@ package services
 import (
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestParseHotkeyStr(t *testing.T) {
@@ -31,6 +32,11 @@ func TestParseHotkeyStr(t *testing.T) {
 		{"win alias", "win+a", false, 2},
 		{"option alias", "option+a", false, 2},
 
+		// Mouse buttons
+		{"mouse4", "mouse4", false, 1},
+		{"ctrl+mouse5", "ctrl+mouse5", false, 2},
+		{"xbutton1 alias", "xbutton1", false, 1},
+
 		// Whitespace handling
 		{"with spaces", " ctrl + a ", false, 2},
 		{"leading spaces", "  ctrl+a", false, 2},
@@ -78,6 +84,7 @@ func TestKeysToString(t *testing.T) {
 		{"f1", []uint16{0x70}, "f1"},
 		{"empty", []uint16{}, ""},
 		{"unknown keycode", []uint16{0xFFFF}, ""}, // unknown code skipped
+		{"mouse after modifier", []uint16{vkMouse5, 0xA2}, "ctrl+mouse5"},
 	}
 
 	for _, tt := range tests {
@@ -99,6 +106,10 @@ func TestParseKeysRoundTrip(t *testing.T) {
 		"shift+space",
 		"f12",
 		"rctrl+rshift+delete",
+		"mouse4",
+		"shift+mouse3",
+		"playpause",
+		"ctrl+nexttrack",
 	}
 
 	for _, combo := range combos {
@@ -203,7 +214,7 @@ func TestMatchBinding(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := matchBinding(tt.bindingKeys, tt.pressedKeys)
+			got := matchBinding(tt.bindingKeys, nil, tt.pressedKeys)
 			if got != tt.want {
 				t.Errorf("matchBinding(%v, %v) = %v, want %v", tt.bindingKeys, tt.pressedKeys, got, tt.want)
 			}
@@ -211,6 +222,42 @@ func TestMatchBinding(t *testing.T) {
 	}
 }
 
+func TestMatchBindingSides(t *testing.T) {
+	const lctrl, rctrl, a = 0xA2, 0xA3, 0x41
+	tests := []struct {
+		hotkey  string
+		pressed []uint16
+		want    bool
+	}{
+		// Generic name: either side.
+		{"ctrl+a", []uint16{lctrl, a}, true},
+		{"ctrl+a", []uint16{rctrl, a}, true},
+		{"control+a", []uint16{rctrl, a}, true},
+		// Right only.
+		{"rctrl+a", []uint16{rctrl, a}, true},
+		{"rctrl+a", []uint16{lctrl, a}, false},
+		// Left only.
+		{"lctrl+a", []uint16{lctrl, a}, true},
+		{"lctrl+a", []uint16{rctrl, a}, false},
+		// Still needs every key.
+		{"ctrl+a", []uint16{rctrl}, false},
+		{"ctrl+shift+a", []uint16{rctrl, 0xA1, a}, true},
+	}
+	for _, tt := range tests {
+		keys, anySide, err := parseHotkey(tt.hotkey)
+		if err != nil {
+			t.Fatalf("parseHotkey(%q): %v", tt.hotkey, err)
+		}
+		pressed := make(map[uint16]bool)
+		for _, kc := range tt.pressed {
+			pressed[kc] = true
+		}
+		if got := matchBinding(keys, anySide, pressed); got != tt.want {
+			t.Errorf("%q with %#x pressed = %v, want %v", tt.hotkey, tt.pressed, got, tt.want)
+		}
+	}
+}
+
 func TestIsModifier(t *testing.T) {
 	tests := []struct {
 		name string
@@ -240,3 +287,272 @@ func TestIsModifier(t *testing.T) {
 		})
 	}
 }
+
+func TestCaptureIgnoresPrimaryMouse(t *testing.T) {
+	m := NewHotkeyManager(nil, nil)
+	m.capturing = true
+	m.captureCh = make(chan string, 1)
+
+	pressed := map[uint16]bool{vkMouse1: true}
+	m.captureKeyDown(vkMouse1, pressed)
+	if !m.capturing {
+		t.Fatal("left click finished capture, want it ignored")
+	}
+
+	pressed[0xA2] = true
+	pressed[vkMouse4] = true
+	m.captureKeyDown(vkMouse4, pressed)
+	if got := <-m.captureCh; got != "ctrl+mouse4" {
+		t.Errorf("captured %q, want %q", got, "ctrl+mouse4")
+	}
+}
+
+func TestDoublePressCancel(t *testing.T) {
+	const f9 = 0x78
+
+	newManager := func() (*HotkeyManager, chan string) {
+		events := make(chan string, 8)
+		m := NewHotkeyManager(
+			func(id string) { events <- "press" },
+			func(id string) { events <- "release" },
+		)
+		m.SetOnCancel(func(id string) { events <- "cancel" })
+		m.doublePressWindow = 80 * time.Millisecond
+		if err := m.Register("p1", "f9", "hold"); err != nil {
+			t.Fatal(err)
+		}
+		m.SetDoublePressCancel("p1", true)
+		return m, events
+	}
+
+	next := func(t *testing.T, events chan string, within time.Duration) string {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(within):
+			return ""
+		}
+	}
+
+	tap := func(m *HotkeyManager, pressed map[uint16]bool, down bool) {
+		if down {
+			pressed[f9] = true
+			m.handleKeyDown(f9, pressed)
+		} else {
+			delete(pressed, f9)
+			m.handleKeyUp(f9, pressed)
+		}
+	}
+
+	t.Run("re-press inside window cancels", func(t *testing.T) {
+		m, events := newManager()
+		pressed := map[uint16]bool{}
+
+		tap(m, pressed, true)
+		if ev := next(t, events, time.Second); ev != "press" {
+			t.Fatalf("got %q, want press", ev)
+		}
+		tap(m, pressed, false)
+		if ev := next(t, events, 20*time.Millisecond); ev != "" {
+			t.Fatalf("release fired before window elapsed: %q", ev)
+		}
+		tap(m, pressed, true)
+		if ev := next(t, events, time.Second); ev != "cancel" {
+			t.Fatalf("got %q, want cancel", ev)
+		}
+		tap(m, pressed, false)
+		if ev := next(t, events, 200*time.Millisecond); ev != "" {
+			t.Fatalf("cancelling press produced %q, want nothing", ev)
+		}
+	})
+
+	t.Run("release after window stops normally", func(t *testing.T) {
+		m, events := newManager()
+		pressed := map[uint16]bool{}
+
+		tap(m, pressed, true)
+		next(t, events, time.Second)
+		tap(m, pressed, false)
+		if ev := next(t, events, time.Second); ev != "release" {
+			t.Fatalf("got %q, want release", ev)
+		}
+		tap(m, pressed, true)
+		if ev := next(t, events, time.Second); ev != "press" {
+			t.Fatalf("got %q, want press (new recording)", ev)
+		}
+	})
+
+	t.Run("disabled releases immediately", func(t *testing.T) {
+		m, events := newManager()
+		m.SetDoublePressCancel("p1", false)
+		pressed := map[uint16]bool{}
+
+		tap(m, pressed, true)
+		next(t, events, time.Second)
+		tap(m, pressed, false)
+		if ev := next(t, events, 40*time.Millisecond); ev != "release" {
+			t.Fatalf("got %q, want immediate release", ev)
+		}
+	})
+
+	t.Run("toggle mode ignores option", func(t *testing.T) {
+		m, events := newManager()
+		if err := m.Register("p1", "f9", "toggle"); err != nil {
+			t.Fatal(err)
+		}
+		m.SetDoublePressCancel("p1", true)
+		pressed := map[uint16]bool{}
+
+		tap(m, pressed, true)
+		next(t, events, time.Second)
+		tap(m, pressed, false)
+		if ev := next(t, events, 40*time.Millisecond); ev != "release" {
+			t.Fatalf("got %q, want immediate release", ev)
+		}
+	})
+}
+
+func TestMouseOnlyBinding(t *testing.T) {
+	for _, mode := range []string{"hold", "toggle"} {
+		t.Run(mode, func(t *testing.T) {
+			events := make(chan string, 4)
+			m := NewHotkeyManager(
+				func(id string) { events <- "press:" + id },
+				func(id string) { events <- "release:" + id },
+			)
+			if err := m.Register("p1", "mouse5", mode); err != nil {
+				t.Fatal(err)
+			}
+
+			next := func() string {
+				select {
+				case ev := <-events:
+					return ev
+				case <-time.After(time.Second):
+					return ""
+				}
+			}
+
+			pressed := map[uint16]bool{}
+
+			// A plain left click must not trigger the binding.
+			pressed[vkMouse1] = true
+			m.handleKeyDown(vkMouse1, pressed)
+			delete(pressed, vkMouse1)
+			m.handleKeyUp(vkMouse1, pressed)
+
+			pressed[vkMouse5] = true
+			m.handleKeyDown(vkMouse5, pressed)
+			if ev := next(); ev != "press:p1" {
+				t.Fatalf("got %q, want press:p1", ev)
+			}
+			delete(pressed, vkMouse5)
+			m.handleKeyUp(vkMouse5, pressed)
+			if ev := next(); ev != "release:p1" {
+				t.Fatalf("got %q, want release:p1", ev)
+			}
+		})
+	}
+}
+
+func TestIsPressed(t *testing.T) {
+	const f9 = 0x78
+	m := NewHotkeyManager(nil, nil)
+	if err := m.Register("p1", "f9", "hold"); err != nil {
+		t.Fatal(err)
+	}
+	pressed := map[uint16]bool{}
+
+	if m.IsPressed("p1") {
+		t.Error("IsPressed before key down = true")
+	}
+	pressed[f9] = true
+	m.handleKeyDown(f9, pressed)
+	if !m.IsPressed("p1") {
+		t.Error("IsPressed while held = false")
+	}
+	delete(pressed, f9)
+	m.handleKeyUp(f9, pressed)
+	if m.IsPressed("p1") {
+		t.Error("IsPressed after release = true")
+	}
+	if m.IsPressed("unknown") {
+		t.Error("IsPressed for unregistered preset = true")
+	}
+}
+
+func TestConsumesMediaKey(t *testing.T) {
+	m := NewHotkeyManager(nil, nil)
+	if err := m.Register("p1", "play", "hold"); err != nil {
+		t.Fatal(err)
+	}
+	if !m.consumesKey(vkMediaPlayPause) {
+		t.Error("bound play/pause not consumed")
+	}
+	if m.consumesKey(vkMediaNext) {
+		t.Error("unbound next track consumed")
+	}
+	m.Unregister("p1")
+	if m.consumesKey(vkMediaPlayPause) {
+		t.Error("play/pause still consumed after unregister")
+	}
+}
+
+func TestFindConflict(t *testing.T) {
+	m := NewHotkeyManager(nil, nil)
+	for id, hk := range map[string]string{
+		"a": "ctrl+shift+a",
+		"b": "f9",
+		"c": "ctrl+mouse5",
+		"d": "rctrl+f1",
+	} {
+		if err := m.Register(id, hk, "hold"); err != nil {
+			t.Fatalf("Register(%s): %v", hk, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		presetID   string
+		hotkey     string
+		wantID     string
+		wantSubset bool
+	}{
+		{"identical", "new", "ctrl+shift+a", "a", false},
+		{"identical different order", "new", "shift+ctrl+a", "a", false},
+		{"strict subset", "new", "ctrl+a", "a", true},
+		{"strict superset", "new", "ctrl+shift+alt+a", "a", true},
+		{"single key superset", "new", "ctrl+f9", "b", true},
+		{"mouse combo", "new", "ctrl+mouse5", "c", false},
+		{"no overlap", "new", "ctrl+b", "", false},
+		{"same preset ignored", "a", "ctrl+shift+a", "", false},
+		{"right modifier of generic", "new", "rctrl+mouse5", "c", false},
+		{"generic of right modifier", "new", "ctrl+f1", "d", false},
+		{"left modifier of right", "new", "lctrl+f1", "", false},
+		{"right modifier subset", "new", "rctrl+shift+mouse5", "c", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, anySide, err := parseHotkey(tt.hotkey)
+			if err != nil {
+				t.Fatalf("parseHotkey(%q): %v", tt.hotkey, err)
+			}
+			id, subset := m.FindConflict(tt.presetID, keys, anySide)
+			if id != tt.wantID || subset != tt.wantSubset {
+				t.Errorf("FindConflict(%q) = (%q, %v), want (%q, %v)", tt.hotkey, id, subset, tt.wantID, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestFindConflictPrefersIdentical(t *testing.T) {
+	m := NewHotkeyManager(nil, nil)
+	_ = m.Register("a", "ctrl+shift+a", "hold") // superset of ctrl+a
+	_ = m.Register("b", "ctrl+a", "toggle")     // identical
+
+	keys, anySide, _ := parseHotkey("ctrl+a")
+	if id, subset := m.FindConflict("new", keys, anySide); id != "b" || subset {
+		t.Errorf("FindConflict = (%q, %v), want (\"b\", false)", id, subset)
+	}
+}
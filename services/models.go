@@ -1,16 +1,20 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/UberMorgott/transcribation/internal/config"
 	"github.com/wailsapp/wails/v3/pkg/application"
@@ -18,6 +22,12 @@ import (
 
 const baseURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/"
 
+// defaultMaxDownloads caps simultaneous model downloads when the config has no limit set.
+const defaultMaxDownloads = 2
+
+// ggmlMagic is the little-endian "ggml" file magic at the start of whisper.cpp models.
+var ggmlMagic = []byte{0x6c, 0x6d, 0x67, 0x67}
+
 // ModelInfo describes a whisper model.
 type ModelInfo struct {
 	Name        string `json:"name"`
@@ -42,6 +52,56 @@ type DownloadProgress struct {
 	Percent     float64 `json:"percent"`
 	Done        bool    `json:"done"`
 	Error       string  `json:"error,omitempty"`
+	Stage       string  `json:"stage,omitempty"` // "queued" while waiting for a free slot
+	BytesPerSec float64 `json:"bytesPerSec"`     // recent download speed, 0 = not known yet
+	ETASeconds  int     `json:"etaSeconds"`      // estimated time left, 0 = unknown
+}
+
+// rateWindow is how many progress emits the download speed is averaged over.
+const rateWindow = 8
+
+// downloadRate estimates download speed as a moving average over the last
+// rateWindow progress emits, so a brief stall doesn't swing the ETA wildly.
+type downloadRate struct {
+	times  []time.Time
+	loaded []int64
+}
+
+// add records that loaded bytes were done at time at.
+func (r *downloadRate) add(at time.Time, loaded int64) {
+	r.times = append(r.times, at)
+	r.loaded = append(r.loaded, loaded)
+	if len(r.times) > rateWindow+1 {
+		r.times = r.times[1:]
+		r.loaded = r.loaded[1:]
+	}
+}
+
+// bytesPerSec returns the average speed across the window, 0 before two samples.
+func (r *downloadRate) bytesPerSec() float64 {
+	n := len(r.times)
+	if n < 2 {
+		return 0
+	}
+	elapsed := r.times[n-1].Sub(r.times[0]).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(r.loaded[n-1]-r.loaded[0]) / elapsed
+}
+
+// downloadETA returns the seconds left at rate bytes/s, 0 if unknown.
+func downloadETA(loaded, total int64, rate float64) int {
+	if total <= 0 || rate <= 0 || loaded >= total {
+		return 0
+	}
+	return int(math.Ceil(float64(total-loaded) / rate))
+}
+
+// DownloadQueue lists model downloads in flight and waiting for a slot.
+type DownloadQueue struct {
+	Active []string `json:"active"`
+	Queued []string `json:"queued"`
 }
 
 type modelCatalogEntry struct {
@@ -154,10 +214,106 @@ func isValidModelName(name string) bool {
 	return false
 }
 
+// smallerVariants returns the catalog models of name's family and language
+// scope (multilingual or .en) that are smaller than name, largest first: the
+// candidates to fall back to when name doesn't fit in memory. nil for custom
+// models and for the smallest variant.
+func smallerVariants(name string) []string {
+	var cur *modelCatalogEntry
+	for i := range catalog {
+		if catalog[i].Name == name {
+			cur = &catalog[i]
+			break
+		}
+	}
+	if cur == nil {
+		return nil
+	}
+	var smaller []modelCatalogEntry
+	for _, c := range catalog {
+		if c.Family == cur.Family && c.EnglishOnly == cur.EnglishOnly && c.SizeBytes < cur.SizeBytes {
+			smaller = append(smaller, c)
+		}
+	}
+	sort.SliceStable(smaller, func(i, j int) bool { return smaller[i].SizeBytes > smaller[j].SizeBytes })
+	names := make([]string, 0, len(smaller))
+	for _, c := range smaller {
+		names = append(names, c.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// ModelsForLanguage returns the models to offer for a preset transcribing
+// langCode: for English every model, English-only (.en) variants first as they
+// are more accurate on English; for any other language, or "auto", only
+// multilingual models. Catalog order is kept otherwise.
+func (s *ModelService) ModelsForLanguage(langCode string) []ModelInfo {
+	return modelsForLanguage(s.GetAvailableModels(), langCode)
+}
+
+func modelsForLanguage(models []ModelInfo, langCode string) []ModelInfo {
+	out := make([]ModelInfo, 0, len(models))
+	if strings.EqualFold(langCode, "en") {
+		out = append(out, models...)
+		sort.SliceStable(out, func(i, j int) bool { return englishOnly(out[i]) && !englishOnly(out[j]) })
+		return out
+	}
+	for _, m := range models {
+		if !englishOnly(m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// englishOnly reports whether m transcribes English only: flagged in the
+// catalog, or an imported model named like one ("distil-small.en").
+func englishOnly(m ModelInfo) bool {
+	return m.EnglishOnly || isEnglishOnlyModel(m.Name)
+}
+
+// isCustomModelName reports whether name is usable for an imported model
+// outside the catalog: a plain file-name stem, never a path.
+func isCustomModelName(name string) bool {
+	if name == "" || name == "." || name == ".." || isValidModelName(name) {
+		return false
+	}
+	for _, c := range name {
+		if !isModelNameRune(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isModelNameRune reports whether c may appear in a model name.
+func isModelNameRune(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.'
+}
+
+// importedModelName derives the model name for an imported file: the stem of
+// "ggml-<name>.bin", or the whole base name without ".bin" for other files,
+// with anything but letters, digits, '-', '_' and '.' replaced by '-'.
+func importedModelName(fileName string) string {
+	stem := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	stem = strings.TrimPrefix(stem, "ggml-")
+	name := []rune(stem)
+	for i, c := range name {
+		if !isModelNameRune(c) {
+			name[i] = '-'
+		}
+	}
+	return strings.Trim(string(name), "-.")
+}
+
 // ModelService manages whisper model files.
 type ModelService struct {
 	mu          sync.Mutex
-	downloading map[string]context.CancelFunc
+	downloading map[string]context.CancelFunc // active downloads
+	queue       []string                      // waiting for a free slot (FIFO)
 }
 
 func NewModelService() *ModelService {
@@ -166,7 +322,8 @@ func NewModelService() *ModelService {
 	}
 }
 
-// GetAvailableModels returns the full catalog with download status.
+// GetAvailableModels returns the full catalog with download status, followed by
+// imported models (ggml-*.bin files in the models dir that aren't in the catalog).
 func (s *ModelService) GetAvailableModels() []ModelInfo {
 	dir := s.ResolveModelsDir()
 
@@ -192,6 +349,85 @@ func (s *ModelService) GetAvailableModels() []ModelInfo {
 			Category:    c.Category,
 		})
 	}
+	return append(models, customModels(dir)...)
+}
+
+// GetDownloadedModels returns only the models present in the models dir —
+// catalog models and imported ones — with their actual on-disk sizes.
+// Meant for the preset model picker, which should offer usable models.
+func (s *ModelService) GetDownloadedModels() []ModelInfo {
+	return downloadedModels(s.ResolveModelsDir())
+}
+
+// downloadedModels lists the catalog models with a non-empty file in dir, in
+// catalog order, followed by customModels(dir). Partial downloads live in
+// .tmp files and are not listed.
+func downloadedModels(dir string) []ModelInfo {
+	models := []ModelInfo{}
+	for _, c := range catalog {
+		fileName := "ggml-" + c.Name + ".bin"
+		info, err := os.Stat(filepath.Join(dir, fileName))
+		if err != nil || info.IsDir() || info.Size() == 0 {
+			continue
+		}
+		models = append(models, ModelInfo{
+			Name:        c.Name,
+			FileName:    fileName,
+			Size:        modelSizeLabel(info.Size()),
+			SizeBytes:   info.Size(),
+			Downloaded:  true,
+			Description: modelDescription(c),
+			Languages:   c.Languages,
+			Speed:       c.Speed,
+			Quality:     c.Quality,
+			EnglishOnly: c.EnglishOnly,
+			Translation: c.Translation,
+			Category:    c.Category,
+		})
+	}
+	return append(models, customModels(dir)...)
+}
+
+// modelSizeLabel formats a file size like the catalog labels ("148 MB", "1.6 GB").
+func modelSizeLabel(size int64) string {
+	if size >= 1_000_000_000 {
+		return fmt.Sprintf("%.1f GB", float64(size)/1_000_000_000)
+	}
+	return fmt.Sprintf("%d MB", size/1_000_000)
+}
+
+// customModels lists ggml-*.bin files in dir whose names aren't in the catalog.
+func customModels(dir string) []ModelInfo {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var models []ModelInfo
+	for _, e := range entries {
+		fileName := e.Name()
+		if e.IsDir() || !strings.HasPrefix(fileName, "ggml-") || !strings.HasSuffix(fileName, ".bin") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(fileName, "ggml-"), ".bin")
+		if !isCustomModelName(name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		models = append(models, ModelInfo{
+			Name:        name,
+			FileName:    fileName,
+			Size:        modelSizeLabel(info.Size()),
+			SizeBytes:   info.Size(),
+			Downloaded:  true,
+			Description: "Imported model",
+			Languages:   99,
+			Translation: true,
+			Category:    "custom",
+		})
+	}
 	return models
 }
 
@@ -246,18 +482,42 @@ func xdgModelsDir() string {
 }
 
 // DownloadModel downloads a model from HuggingFace with progress events.
+// At most maxDownloads() run at once; extra requests are queued and reported
+// with a "queued" stage until a slot frees up.
 func (s *ModelService) DownloadModel(name string) error {
 	if !isValidModelName(name) {
 		return fmt.Errorf("unknown model name: %s", name)
 	}
+	limit := maxDownloads()
+
 	s.mu.Lock()
 	if _, exists := s.downloading[name]; exists {
 		s.mu.Unlock()
 		return fmt.Errorf("already downloading %s", name)
 	}
+	if s.queuedIndex(name) >= 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("already queued %s", name)
+	}
+
+	if len(s.downloading) >= limit {
+		s.queue = append(s.queue, name)
+		log.Printf("Model %s: queued (%d active, limit %d)", name, len(s.downloading), limit)
+		s.mu.Unlock()
+		// Emitted without s.mu: a listener may call back into ModelService.
+		emitDownloadProgress(DownloadProgress{ModelName: name, Stage: "queued"})
+		return nil
+	}
+	s.startDownload(name)
+	s.mu.Unlock()
+	return nil
+}
+
+// startDownload launches the download worker for name.
+// Must be called with s.mu held.
+func (s *ModelService) startDownload(name string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	s.downloading[name] = cancel
-	s.mu.Unlock()
 
 	go func() {
 		defer func() {
@@ -267,28 +527,74 @@ func (s *ModelService) DownloadModel(name string) error {
 		}()
 		s.downloadWorker(ctx, name)
 	}()
-	return nil
+}
+
+// startQueued starts queued downloads while there are free slots.
+// Must be called with s.mu held.
+func (s *ModelService) startQueued(limit int) {
+	for len(s.queue) > 0 && len(s.downloading) < limit {
+		name := s.queue[0]
+		s.queue = s.queue[1:]
+		s.startDownload(name)
+	}
+}
+
+func (s *ModelService) queuedIndex(name string) int {
+	for i, q := range s.queue {
+		if q == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetDownloadQueue returns the models currently downloading and waiting in the queue.
+func (s *ModelService) GetDownloadQueue() DownloadQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := DownloadQueue{
+		Active: make([]string, 0, len(s.downloading)),
+		Queued: append([]string{}, s.queue...),
+	}
+	for name := range s.downloading {
+		q.Active = append(q.Active, name)
+	}
+	sort.Strings(q.Active)
+	return q
+}
+
+// maxDownloads returns the configured download concurrency limit.
+func maxDownloads() int {
+	cfg, err := config.Load()
+	if err != nil || cfg.MaxDownloads <= 0 {
+		return defaultMaxDownloads
+	}
+	return cfg.MaxDownloads
+}
+
+func emitDownloadProgress(p DownloadProgress) {
+	if app := application.Get(); app != nil {
+		app.Event.Emit("model:download:progress", p)
+	}
 }
 
 func (s *ModelService) downloadWorker(ctx context.Context, name string) {
 	defer func() {
+		limit := maxDownloads()
 		s.mu.Lock()
 		delete(s.downloading, name)
+		s.startQueued(limit)
 		s.mu.Unlock()
 	}()
 
 	fileName := "ggml-" + name + ".bin"
-	url := baseURL + fileName
+	url := modelBaseURL() + fileName
 	dir := s.ResolveModelsDir()
 	destPath := filepath.Join(dir, fileName)
 	tmpPath := destPath + ".tmp"
 
-	emit := func(p DownloadProgress) {
-		app := application.Get()
-		if app != nil {
-			app.Event.Emit("model:download:progress", p)
-		}
-	}
+	emit := emitDownloadProgress
 
 	// Resume support: check if a partial temp file exists.
 	var resumeOffset int64
@@ -349,6 +655,8 @@ func (s *ModelService) downloadWorker(ctx context.Context, name string) {
 	loaded := resumeOffset
 	buf := make([]byte, 64*1024)
 	lastEmit := int64(0)
+	var rate downloadRate
+	rate.add(time.Now(), loaded)
 
 	// Emit initial progress immediately so the frontend knows the download started.
 	emit(DownloadProgress{
@@ -385,11 +693,15 @@ func (s *ModelService) downloadWorker(ctx context.Context, name string) {
 				if total > 0 {
 					pct = float64(loaded) / float64(total) * 100
 				}
+				rate.add(time.Now(), loaded)
+				bps := rate.bytesPerSec()
 				emit(DownloadProgress{
 					ModelName:   name,
 					BytesLoaded: loaded,
 					BytesTotal:  total,
 					Percent:     pct,
+					BytesPerSec: bps,
+					ETASeconds:  downloadETA(loaded, total, bps),
 				})
 				lastEmit = loaded
 			}
@@ -424,18 +736,67 @@ func (s *ModelService) downloadWorker(ctx context.Context, name string) {
 	})
 }
 
-// CancelDownload cancels an in-progress download.
+// modelBaseURL returns the download base for model files: the user-configured
+// mirror if set, otherwise HuggingFace. Always ends with a slash.
+func modelBaseURL() string {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return baseURL
+	}
+	return normalizeBaseURL(cfg.ModelBaseURL)
+}
+
+func normalizeBaseURL(u string) string {
+	u = strings.TrimSpace(u)
+	if u == "" {
+		return baseURL
+	}
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	return u
+}
+
+// CancelDownload cancels an in-progress download or drops it from the queue.
 func (s *ModelService) CancelDownload(name string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	if cancel, ok := s.downloading[name]; ok {
 		cancel()
+		s.mu.Unlock()
+		return
+	}
+	i := s.queuedIndex(name)
+	if i >= 0 {
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	}
+	s.mu.Unlock()
+	if i >= 0 {
+		emitDownloadProgress(DownloadProgress{ModelName: name, Done: true, Error: "cancelled"})
+	}
+}
+
+// CancelAllDownloads cancels every active download and empties the queue.
+// The queue is cleared first so finishing workers don't start queued downloads;
+// each active worker still emits its own final "cancelled" progress event.
+func (s *ModelService) CancelAllDownloads() {
+	s.mu.Lock()
+	queued := s.queue
+	s.queue = nil
+	for name, cancel := range s.downloading {
+		log.Printf("Model %s: cancelling download", name)
+		cancel()
+	}
+	s.mu.Unlock()
+
+	for _, name := range queued {
+		emitDownloadProgress(DownloadProgress{ModelName: name, Done: true, Error: "cancelled"})
 	}
 }
 
-// DeleteModel removes a downloaded model file.
+// DeleteModel removes a downloaded or imported model file.
 func (s *ModelService) DeleteModel(name string) error {
-	if !isValidModelName(name) {
+	if !isValidModelName(name) && !isCustomModelName(name) {
 		return fmt.Errorf("unknown model name: %s", name)
 	}
 	dir := s.ResolveModelsDir()
@@ -444,8 +805,113 @@ func (s *ModelService) DeleteModel(name string) error {
 	return os.Remove(path)
 }
 
+// ImportLocalModel copies an existing ggml-*.bin file into the models directory
+// under the catalog name, for offline installs. The file must be a GGML model.
+func (s *ModelService) ImportLocalModel(srcPath, name string) error {
+	if !isValidModelName(name) {
+		return fmt.Errorf("unknown model name: %s", name)
+	}
+	if err := checkGGMLFile(srcPath); err != nil {
+		return err
+	}
+
+	return copyModelFile(srcPath, filepath.Join(s.ResolveModelsDir(), "ggml-"+name+".bin"))
+}
+
+// ImportModel copies a GGML model that isn't in the catalog (fine-tunes, other
+// quantizations) into the models directory as ggml-<name>.bin, where name comes
+// from the file name. With an empty srcPath a native file picker is opened.
+// The model then shows up in GetAvailableModels and can be picked by presets.
+func (s *ModelService) ImportModel(srcPath string) error {
+	if srcPath == "" {
+		app := application.Get()
+		if app == nil {
+			return fmt.Errorf("no application window for the file picker")
+		}
+		picked, err := app.Dialog.OpenFile().
+			CanChooseFiles(true).
+			CanChooseDirectories(false).
+			AddFilter("Whisper GGML model (*.bin)", "*.bin").
+			SetTitle("Import Whisper Model").
+			PromptForSingleSelection()
+		if err != nil {
+			return err
+		}
+		if picked == "" {
+			return nil // cancelled
+		}
+		srcPath = picked
+	}
+
+	name := importedModelName(srcPath)
+	if isValidModelName(name) {
+		return s.ImportLocalModel(srcPath, name)
+	}
+	if !isCustomModelName(name) {
+		return fmt.Errorf("cannot derive a model name from %s", filepath.Base(srcPath))
+	}
+	if err := checkGGMLFile(srcPath); err != nil {
+		return err
+	}
+	return copyModelFile(srcPath, filepath.Join(s.ResolveModelsDir(), "ggml-"+name+".bin"))
+}
+
+// copyModelFile copies srcPath to destPath through a temporary file, so a
+// failed copy never leaves a truncated model behind.
+func copyModelFile(srcPath, destPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("open model: %w", err)
+	}
+	defer src.Close()
+
+	tmpPath := destPath + ".import"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("create model file: %w", err)
+	}
+	n, err := io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("copy model: %w", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("install model: %w", err)
+	}
+
+	log.Printf("Model imported: %s -> %s (%d bytes)", srcPath, destPath, n)
+	return nil
+}
+
+// checkGGMLFile verifies that path starts with the GGML magic bytes.
+func checkGGMLFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open model: %w", err)
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(ggmlMagic))
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, ggmlMagic) {
+		return fmt.Errorf("%s is not a GGML model file", filepath.Base(path))
+	}
+	return nil
+}
+
 // SetModelsDir changes the models directory and optionally moves existing models.
 func (s *ModelService) SetModelsDir(newDir string, moveModels bool) error {
+	// Held for the whole move so no download can start (or finish renaming its
+	// .tmp) in the old directory while files are being relocated.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.downloading) + len(s.queue); n > 0 {
+		return fmt.Errorf("cannot change models directory while %d download(s) are in progress — cancel them first", n)
+	}
+
 	oldDir := s.ResolveModelsDir()
 
 	if err := os.MkdirAll(newDir, 0o755); err != nil {
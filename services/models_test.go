@@ -0,0 +1,343 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", baseURL},
+		{"   ", baseURL},
+		{"https://mirror.local/whisper", "https://mirror.local/whisper/"},
+		{"https://mirror.local/whisper/", "https://mirror.local/whisper/"},
+		{" http://10.0.0.5:8080/models ", "http://10.0.0.5:8080/models/"},
+	}
+	for _, tt := range tests {
+		if got := normalizeBaseURL(tt.in); got != tt.want {
+			t.Errorf("normalizeBaseURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckGGMLFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"valid", append([]byte("lmgg"), 0, 1, 2, 3), false},
+		{"gguf", []byte("GGUF\x03\x00\x00\x00"), true},
+		{"too short", []byte("lm"), true},
+		{"empty", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkGGMLFile(write(tt.name+".bin", tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkGGMLFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := checkGGMLFile(filepath.Join(dir, "missing.bin")); err == nil {
+		t.Error("checkGGMLFile(missing) = nil, want error")
+	}
+}
+
+func TestImportedModelName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"/tmp/ggml-my-finetune.bin", "my-finetune"},
+		{"ggml-small-q4_k.bin", "small-q4_k"},
+		{"/home/me/whisper ru (tuned).bin", "whisper-ru--tuned"},
+		{"model.bin", "model"},
+		{"ggml-tiny.bin", "tiny"},
+	}
+	for _, tt := range tests {
+		if got := importedModelName(filepath.FromSlash(tt.file)); got != tt.want {
+			t.Errorf("importedModelName(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+
+	for name, want := range map[string]bool{
+		"my-finetune": true,
+		"tiny":        false, // catalog name
+		"../evil":     false,
+		"a/b":         false,
+		"":            false,
+	} {
+		if got := isCustomModelName(name); got != want {
+			t.Errorf("isCustomModelName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestCustomModels(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"ggml-tiny.bin":        "lmgg-catalog",
+		"ggml-my-finetune.bin": "lmgg-custom",
+		"ggml-empty.bin":       "",
+		"notes.txt":            "x",
+		"other.bin":            "lmgg",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := customModels(dir)
+	if len(got) != 1 || got[0].Name != "my-finetune" || !got[0].Downloaded || got[0].Category != "custom" {
+		t.Errorf("customModels = %+v, want only my-finetune", got)
+	}
+}
+
+func TestDownloadedModels(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"ggml-base.bin":        "lmgg-base-model",
+		"ggml-tiny.bin":        "lmgg-tiny",
+		"ggml-small.bin":       "", // empty: failed copy
+		"ggml-medium.bin.tmp":  "lmgg-partial",
+		"ggml-my-finetune.bin": "lmgg-custom",
+		"notes.txt":            "x",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "ggml-large-v3.bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := downloadedModels(dir)
+	var names []string
+	for _, m := range got {
+		names = append(names, m.Name)
+		if !m.Downloaded {
+			t.Errorf("%s: Downloaded = false", m.Name)
+		}
+	}
+	// Catalog order (tiny before base), then imported models.
+	if want := "tiny,base,my-finetune"; strings.Join(names, ",") != want {
+		t.Fatalf("downloadedModels = %v, want %s", names, want)
+	}
+	if got[1].SizeBytes != int64(len("lmgg-base-model")) {
+		t.Errorf("base SizeBytes = %d, want on-disk size %d", got[1].SizeBytes, len("lmgg-base-model"))
+	}
+
+	if got := downloadedModels(filepath.Join(dir, "missing")); got == nil || len(got) != 0 {
+		t.Errorf("downloadedModels(missing dir) = %#v, want empty slice", got)
+	}
+}
+
+func TestModelSizeLabel(t *testing.T) {
+	tests := map[int64]string{
+		57_400_000:    "57 MB",
+		1_623_000_000: "1.6 GB",
+		500:           "0 MB",
+	}
+	for size, want := range tests {
+		if got := modelSizeLabel(size); got != want {
+			t.Errorf("modelSizeLabel(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestDownloadQueue(t *testing.T) {
+	s := NewModelService()
+
+	// Fill both default slots with fake in-flight downloads so nothing hits the network.
+	cancelled := map[string]bool{}
+	for _, name := range []string{"tiny", "base"} {
+		name := name
+		s.downloading[name] = func() { cancelled[name] = true }
+	}
+
+	if err := s.DownloadModel("small"); err != nil {
+		t.Fatalf("DownloadModel(small) = %v", err)
+	}
+	if err := s.DownloadModel("medium"); err != nil {
+		t.Fatalf("DownloadModel(medium) = %v", err)
+	}
+	if err := s.DownloadModel("small"); err == nil {
+		t.Error("DownloadModel(small) twice = nil, want already queued error")
+	}
+	if err := s.DownloadModel("tiny"); err == nil {
+		t.Error("DownloadModel(tiny) while active = nil, want error")
+	}
+
+	q := s.GetDownloadQueue()
+	if strings.Join(q.Active, ",") != "base,tiny" {
+		t.Errorf("Active = %v, want [base tiny]", q.Active)
+	}
+	if strings.Join(q.Queued, ",") != "small,medium" {
+		t.Errorf("Queued = %v, want [small medium]", q.Queued)
+	}
+
+	s.CancelDownload("small")
+	if q := s.GetDownloadQueue(); strings.Join(q.Queued, ",") != "medium" {
+		t.Errorf("Queued after cancel = %v, want [medium]", q.Queued)
+	}
+
+	s.CancelDownload("tiny")
+	if !cancelled["tiny"] {
+		t.Error("CancelDownload(tiny) did not cancel the active download")
+	}
+}
+
+func TestCancelAllDownloads(t *testing.T) {
+	s := NewModelService()
+
+	cancelled := map[string]bool{}
+	for _, name := range []string{"tiny", "base"} {
+		name := name
+		s.downloading[name] = func() { cancelled[name] = true }
+	}
+	s.queue = []string{"small", "medium"}
+
+	s.CancelAllDownloads()
+
+	if !cancelled["tiny"] || !cancelled["base"] {
+		t.Errorf("cancelled = %v, want both active downloads cancelled", cancelled)
+	}
+	q := s.GetDownloadQueue()
+	if len(q.Queued) != 0 {
+		t.Errorf("Queued = %v, want empty", q.Queued)
+	}
+	// Active entries are removed by their workers once they observe the cancel.
+	if strings.Join(q.Active, ",") != "base,tiny" {
+		t.Errorf("Active = %v, want [base tiny]", q.Active)
+	}
+}
+
+func TestSetModelsDirRefusedDuringDownload(t *testing.T) {
+	s := NewModelService()
+	s.downloading["tiny"] = func() {}
+
+	newDir := t.TempDir()
+	if err := s.SetModelsDir(newDir, true); err == nil {
+		t.Fatal("SetModelsDir during a download = nil, want error")
+	}
+
+	delete(s.downloading, "tiny")
+	s.queue = []string{"base"}
+	if err := s.SetModelsDir(newDir, true); err == nil {
+		t.Fatal("SetModelsDir with a queued download = nil, want error")
+	}
+}
+
+func TestDownloadRate(t *testing.T) {
+	var r downloadRate
+	start := time.Unix(0, 0)
+	r.add(start, 0)
+	if bps := r.bytesPerSec(); bps != 0 {
+		t.Errorf("rate after one sample = %v, want 0", bps)
+	}
+	// 1 MB/s for a while, then 2 MB/s: once the slow samples leave the window
+	// the average is the recent speed.
+	loaded := int64(0)
+	for i := 1; i <= 10; i++ {
+		loaded += 1 << 20
+		r.add(start.Add(time.Duration(i)*time.Second), loaded)
+	}
+	if bps := r.bytesPerSec(); bps != 1<<20 {
+		t.Errorf("steady rate = %v, want %v", bps, 1<<20)
+	}
+	for i := 11; i <= 10+rateWindow; i++ {
+		loaded += 2 << 20
+		r.add(start.Add(time.Duration(i)*time.Second), loaded)
+	}
+	if bps := r.bytesPerSec(); bps != 2<<20 {
+		t.Errorf("rate after speed-up = %v, want %v", bps, 2<<20)
+	}
+
+	tests := []struct {
+		loaded, total int64
+		rate          float64
+		want          int
+	}{
+		{0, 100, 10, 10},
+		{95, 100, 10, 1},
+		{50, 0, 10, 0},    // unknown size
+		{50, 100, 0, 0},   // no rate yet
+		{100, 100, 10, 0}, // done
+	}
+	for _, tt := range tests {
+		if got := downloadETA(tt.loaded, tt.total, tt.rate); got != tt.want {
+			t.Errorf("downloadETA(%d, %d, %v) = %d, want %d", tt.loaded, tt.total, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestSmallerVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"large-v3", "large-v3-q5_0"},
+		{"large-v3-turbo", "large-v3-turbo-q8_0 large-v3-turbo-q5_0"},
+		{"large-v3-turbo-q8_0", "large-v3-turbo-q5_0"},
+		{"medium", "medium-q8_0 medium-q5_0"},
+		{"base.en", "base.en-q8_0 base.en-q5_1"},
+		{"small-q5_1", ""},
+		{"my-finetune", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(smallerVariants(tt.name), " "); got != tt.want {
+			t.Errorf("smallerVariants(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModelsForLanguage(t *testing.T) {
+	models := []ModelInfo{
+		{Name: "tiny"},
+		{Name: "tiny.en", EnglishOnly: true},
+		{Name: "base"},
+		{Name: "base.en-q5_1", EnglishOnly: true},
+		{Name: "distil-small.en"}, // imported
+		{Name: "my-finetune"},
+	}
+	names := func(ms []ModelInfo) string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.Name)
+		}
+		return strings.Join(out, " ")
+	}
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", "tiny.en base.en-q5_1 distil-small.en tiny base my-finetune"},
+		{"ru", "tiny base my-finetune"},
+		{"auto", "tiny base my-finetune"},
+	}
+	for _, tt := range tests {
+		if got := names(modelsForLanguage(models, tt.lang)); got != tt.want {
+			t.Errorf("modelsForLanguage(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+	if names(models) != "tiny tiny.en base base.en-q5_1 distil-small.en my-finetune" {
+		t.Errorf("input reordered: %q", names(models))
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numKind is the grammatical role of a number word.
+type numKind int
+
+const (
+	numZero     numKind = iota + 1
+	numUnit             // 1-9
+	numTeen             // 10-19
+	numTen              // 20, 30 ... 90
+	numHundreds         // ru: сто ... девятьсот
+	numHundred          // en: "hundred", multiplies what precedes it
+	numScale            // thousand, million, billion
+)
+
+type numWord struct {
+	value int64
+	kind  numKind
+}
+
+var enNumberWords = map[string]numWord{
+	"zero": {0, numZero},
+	"one":  {1, numUnit}, "two": {2, numUnit}, "three": {3, numUnit}, "four": {4, numUnit},
+	"five": {5, numUnit}, "six": {6, numUnit}, "seven": {7, numUnit}, "eight": {8, numUnit}, "nine": {9, numUnit},
+	"ten": {10, numTeen}, "eleven": {11, numTeen}, "twelve": {12, numTeen}, "thirteen": {13, numTeen},
+	"fourteen": {14, numTeen}, "fifteen": {15, numTeen}, "sixteen": {16, numTeen},
+	"seventeen": {17, numTeen}, "eighteen": {18, numTeen}, "nineteen": {19, numTeen},
+	"twenty": {20, numTen}, "thirty": {30, numTen}, "forty": {40, numTen}, "fifty": {50, numTen},
+	"sixty": {60, numTen}, "seventy": {70, numTen}, "eighty": {80, numTen}, "ninety": {90, numTen},
+	"hundred":  {100, numHundred},
+	"thousand": {1_000, numScale}, "million": {1_000_000, numScale}, "billion": {1_000_000_000, numScale},
+}
+
+var ruNumberWords = map[string]numWord{
+	"ноль": {0, numZero}, "нуль": {0, numZero},
+	"один": {1, numUnit}, "одна": {1, numUnit}, "одно": {1, numUnit},
+	"два": {2, numUnit}, "две": {2, numUnit}, "три": {3, numUnit}, "четыре": {4, numUnit},
+	"пять": {5, numUnit}, "шесть": {6, numUnit}, "семь": {7, numUnit}, "восемь": {8, numUnit}, "девять": {9, numUnit},
+	"десять": {10, numTeen}, "одиннадцать": {11, numTeen}, "двенадцать": {12, numTeen},
+	"тринадцать": {13, numTeen}, "четырнадцать": {14, numTeen}, "пятнадцать": {15, numTeen},
+	"шестнадцать": {16, numTeen}, "семнадцать": {17, numTeen}, "восемнадцать": {18, numTeen},
+	"девятнадцать": {19, numTeen},
+	"двадцать":     {20, numTen}, "тридцать": {30, numTen}, "сорок": {40, numTen}, "пятьдесят": {50, numTen},
+	"шестьдесят": {60, numTen}, "семьдесят": {70, numTen}, "восемьдесят": {80, numTen}, "девяносто": {90, numTen},
+	"сто": {100, numHundreds}, "двести": {200, numHundreds}, "триста": {300, numHundreds},
+	"четыреста": {400, numHundreds}, "пятьсот": {500, numHundreds}, "шестьсот": {600, numHundreds},
+	"семьсот": {700, numHundreds}, "восемьсот": {800, numHundreds}, "девятьсот": {900, numHundreds},
+	"тысяча": {1_000, numScale}, "тысячи": {1_000, numScale}, "тысяч": {1_000, numScale},
+	"миллион": {1_000_000, numScale}, "миллиона": {1_000_000, numScale}, "миллионов": {1_000_000, numScale},
+	"миллиард": {1_000_000_000, numScale}, "миллиарда": {1_000_000_000, numScale}, "миллиардов": {1_000_000_000, numScale},
+}
+
+// spokenNumber accumulates the words of one number.
+type spokenNumber struct {
+	total    int64 // value of the completed scale groups
+	current  int64 // value below the last scale word
+	last     numKind
+	scale    int64 // last scale word used, 0 = none
+	compound bool  // has a hundred or a scale word
+}
+
+// add appends w if it continues the number ("twenty" + "five"), and reports
+// false if w must start a new one ("twenty" + "twenty").
+func (n *spokenNumber) add(w numWord) bool {
+	if n.last == 0 {
+		n.last = w.kind
+		switch w.kind {
+		case numScale:
+			n.total, n.scale, n.compound = w.value, w.value, true
+		case numHundred, numHundreds:
+			n.current, n.compound = w.value, true
+		default:
+			n.current = w.value
+		}
+		return true
+	}
+	switch w.kind {
+	case numUnit:
+		if n.last != numTen && !n.afterGroup() {
+			return false
+		}
+		n.current += w.value
+	case numTeen, numTen:
+		if !n.afterGroup() {
+			return false
+		}
+		n.current += w.value
+	case numHundred:
+		// "three hundred", "nineteen hundred"
+		if n.last != numUnit && n.last != numTeen || n.current >= 100 {
+			return false
+		}
+		n.current *= 100
+	case numHundreds:
+		if n.last != numScale {
+			return false
+		}
+		n.current = w.value
+	case numScale:
+		if n.last == numScale || n.scale != 0 && w.value >= n.scale {
+			return false
+		}
+		n.total += n.current * w.value
+		n.current, n.scale = 0, w.value
+	default:
+		return false // zero only stands alone
+	}
+	if w.kind == numHundred || w.kind == numHundreds || w.kind == numScale {
+		n.compound = true
+	}
+	n.last = w.kind
+	return true
+}
+
+// afterGroup reports whether the number ends in a hundred or scale word, so
+// tens and units may follow.
+func (n *spokenNumber) afterGroup() bool {
+	return n.last == numHundred || n.last == numHundreds || n.last == numScale
+}
+
+func (n *spokenNumber) value() int64 { return n.total + n.current }
+
+// numberWordRe matches a word; numberGapRe the separators allowed between
+// number words: spaces, or a hyphen within one number ("twenty-five");
+// hyphenWordRe a hyphen joining a word to the next ("three-year-old").
+var (
+	numberWordRe = regexp.MustCompile(`\p{L}+`)
+	numberGapRe  = regexp.MustCompile(`^(?:[ \t]+|-)$`)
+	hyphenWordRe = regexp.MustCompile(`^-\p{L}`)
+)
+
+// loneOneWords are left as words when they are a whole number on their own:
+// "no one knows", "at one point", "одна из них" are rarely about counting.
+var loneOneWords = map[string]bool{"one": true, "один": true, "одна": true, "одно": true}
+
+// numberWordsFor returns the number vocabulary for lang: English, Russian, or
+// both for "auto". Other languages have none.
+func numberWordsFor(lang string) []map[string]numWord {
+	switch lang {
+	case "en":
+		return []map[string]numWord{enNumberWords}
+	case "ru":
+		return []map[string]numWord{ruNumberWords}
+	case "", "auto":
+		return []map[string]numWord{enNumberWords, ruNumberWords}
+	}
+	return nil
+}
+
+// wordsToNumbers replaces spoken numbers in text with digits, for English and
+// Russian (lang "auto" tries both): "one hundred and five" → "105",
+// "сто двадцать" → "120". Runs of separate small numbers are dictated digit
+// groups and are joined: "twenty twenty five" → "2025", "five five five" →
+// "555", but not into a hyphenated compound: "two three-year-olds" → "2
+// 3-year-olds". A lone "one" (see loneOneWords) and everything else,
+// including inflected forms ("двух"), is left alone.
+func wordsToNumbers(text, lang string) string {
+	vocab := numberWordsFor(lang)
+	if vocab == nil {
+		return text
+	}
+	lookup := func(word string) (numWord, bool) {
+		word = strings.ToLower(word)
+		for _, words := range vocab {
+			if w, ok := words[word]; ok {
+				return w, true
+			}
+		}
+		return numWord{}, false
+	}
+
+	words := numberWordRe.FindAllStringIndex(text, -1)
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(words); {
+		if _, ok := lookup(text[words[i][0]:words[i][1]]); !ok {
+			i++
+			continue
+		}
+		// Collect the run of number words starting at i.
+		var numbers []*spokenNumber
+		starts := []int{i} // first word of each number
+		cur := &spokenNumber{}
+		end := i
+		for j := i; j < len(words); j++ {
+			gap := ""
+			if j > i {
+				if gap = text[words[j-1][1]:words[j][0]]; !numberGapRe.MatchString(gap) {
+					break
+				}
+			}
+			word := text[words[j][0]:words[j][1]]
+			if strings.EqualFold(word, "and") && cur.afterGroup() && j+1 < len(words) &&
+				numberGapRe.MatchString(text[words[j][1]:words[j+1][0]]) {
+				// "one hundred and five": "and" only joins a following ten or unit.
+				if next, ok := lookup(text[words[j+1][0]:words[j+1][1]]); ok && next.kind >= numUnit && next.kind <= numTen {
+					continue
+				}
+				break
+			}
+			w, ok := lookup(word)
+			if !ok {
+				break
+			}
+			if next := *cur; next.add(w) {
+				*cur = next
+			} else if gap == "-" {
+				break // "five-six" is a range, not 56
+			} else {
+				numbers = append(numbers, cur)
+				starts = append(starts, j)
+				cur = &spokenNumber{}
+				cur.add(w)
+			}
+			end = j
+		}
+		numbers = append(numbers, cur)
+		if last := len(numbers) - 1; last > 0 && hyphenWordRe.MatchString(text[words[end][1]:]) {
+			// The last number belongs to the compound after it; it is
+			// converted on its own next.
+			numbers, end = numbers[:last], starts[last]-1
+		}
+		if end == i && loneOneWords[strings.ToLower(text[words[i][0]:words[i][1]])] {
+			i++
+			continue
+		}
+
+		b.WriteString(text[pos:words[i][0]])
+		b.WriteString(formatNumbers(numbers))
+		pos = words[end][1]
+		i = end + 1
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// formatNumbers writes a run of numbers: digit groups joined ("2025"), or
+// space-separated if any is a full number with hundreds or scales.
+func formatNumbers(numbers []*spokenNumber) string {
+	sep := ""
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.FormatInt(n.value(), 10)
+		if n.compound {
+			sep = " "
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,62 @@
+package services
+
+import "testing"
+
+func TestWordsToNumbers(t *testing.T) {
+	tests := []struct {
+		text, lang, want string
+	}{
+		// English
+		{"one hundred and five", "en", "105"},
+		{"twenty twenty five", "en", "2025"},
+		{"twenty-five", "en", "25"},
+		{"nineteen eighty four", "en", "1984"},
+		{"nineteen hundred", "en", "1900"},
+		{"two thousand twenty five", "en", "2025"},
+		{"one hundred twenty three thousand four hundred fifty six", "en", "123456"},
+		{"three million", "en", "3000000"},
+		{"call five five five one two three four now", "en", "call 5551234 now"},
+		{"zero seven", "en", "07"},
+		{"Twenty apples, three pears.", "en", "20 apples, 3 pears."},
+		{"one hundred, two hundred", "en", "100, 200"},
+		{"one hundred two", "en", "102"},
+		{"five hundred twenty twenty", "en", "520 20"},
+		{"pages five-six", "en", "pages 5-6"},
+		{"rock and roll", "en", "rock and roll"},
+		{"one hundred and", "en", "100 and"},
+		{"one hundred and counting", "en", "100 and counting"},
+		{"no numbers here", "en", "no numbers here"},
+		{"сто", "en", "сто"},
+		{"no one knows", "en", "no one knows"},
+		{"one of them left", "en", "one of them left"},
+		{"at one point", "en", "at one point"},
+		{"twenty one", "en", "21"},
+		{"one one two", "en", "112"},
+		{"I have two three-year-olds", "en", "I have 2 3-year-olds"},
+		{"twenty three-year-olds", "en", "23-year-olds"},
+		{"one-on-one", "en", "one-on-one"},
+
+		// Russian
+		{"сто двадцать", "ru", "120"},
+		{"две тысячи двадцать пять", "ru", "2025"},
+		{"триста тысяч", "ru", "300000"},
+		{"тысяча девятьсот восемьдесят четыре", "ru", "1984"},
+		{"пять миллионов двести", "ru", "5000200"},
+		{"Купи три яблока", "ru", "Купи 3 яблока"},
+		{"двадцать двадцать пять", "ru", "2025"},
+		{"до двух часов", "ru", "до двух часов"},
+		{"одна из них", "ru", "одна из них"},
+		{"одно и то же", "ru", "одно и то же"},
+		{"тридцать одна", "ru", "31"},
+		{"twenty", "ru", "twenty"},
+
+		// Languages
+		{"twenty и двадцать", "auto", "20 и 20"},
+		{"twenty", "de", "twenty"},
+	}
+	for _, tt := range tests {
+		if got := wordsToNumbers(tt.text, tt.lang); got != tt.want {
+			t.Errorf("wordsToNumbers(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
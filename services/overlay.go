@@ -1,27 +1,68 @@
 package services
 
 import (
+	"log"
+	"net/url"
+	"regexp"
 	"runtime"
+	"sync/atomic"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
+
+	"github.com/UberMorgott/transcribation/internal/config"
 )
 
+// overlayFailed is set when creating the overlay window panicked (e.g. the
+// compositor rejected the window); the overlay is not retried after that.
+var overlayFailed atomic.Bool
+
+// overlayColorRe matches the preset colors the overlay accepts: "#rgb" or "#rrggbb".
+var overlayColorRe = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether c is empty (default color) or a hex CSS color.
+func validColor(c string) bool {
+	return c == "" || overlayColorRe.MatchString(c)
+}
+
+// overlaySupported reports whether the overlay is shown on this platform.
+// On Linux/Wayland the compositor may ignore the always-on-top and centering
+// hints (there is no layer-shell support in the webview window), so the overlay
+// can end up behind other windows or wherever the compositor places it — it is
+// still shown, as some feedback beats none.
+func overlaySupported() bool {
+	return (runtime.GOOS == "windows" || runtime.GOOS == "linux") && !overlayFailed.Load()
+}
+
 // showOverlay creates (if needed) and shows the recording/processing overlay window.
-func showOverlay(state string) {
-	if runtime.GOOS != "windows" {
+// The preset's name, color and language are passed along so the overlay can show
+// which preset is active.
+func showOverlay(state string, p config.Preset, lang string) {
+	if !overlaySupported() {
 		return
 	}
 	app := application.Get()
 	if app == nil {
 		return
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("overlay disabled: window creation failed: %v", r)
+			overlayFailed.Store(true)
+		}
+	}()
 
 	// Save the foreground window so we can restore focus after showing the overlay.
 	saved := saveForegroundWindow()
 
 	// If overlay already exists, emit state event and show it.
 	if w, exists := app.Window.GetByName("overlay"); exists {
-		app.Event.Emit("overlay:state", map[string]any{"state": state})
+		app.Event.Emit("overlay:state", map[string]any{
+			"state":    state,
+			"presetId": p.ID,
+			"name":     p.Name,
+			"color":    p.Color,
+			"language": lang,
+		})
 		if !w.IsVisible() {
 			w.Show()
 		}
@@ -29,8 +70,15 @@ func showOverlay(state string) {
 		return
 	}
 
-	// First time: pass initial state via URL param so the page reads it on mount
+	// First time: pass initial state via URL params so the page reads it on mount
 	// (event would be missed because webview hasn't loaded yet).
+	params := url.Values{}
+	params.Set("window", "overlay")
+	params.Set("state", state)
+	params.Set("presetId", p.ID)
+	params.Set("name", p.Name)
+	params.Set("color", p.Color)
+	params.Set("language", lang)
 	w := app.Window.NewWithOptions(application.WebviewWindowOptions{
 		Name:              "overlay",
 		Width:             220,
@@ -41,11 +89,14 @@ func showOverlay(state string) {
 		IgnoreMouseEvents: true,
 		Hidden:            true,
 		DisableResize:     true,
-		URL:               "/?window=overlay&state=" + state,
+		URL:               "/?" + params.Encode(),
 		Windows: application.WindowsWindow{
 			HiddenOnTaskbar:                  true,
 			DisableFramelessWindowDecorations: true,
 		},
+		Linux: application.LinuxWindow{
+			WindowIsTranslucent: true,
+		},
 	})
 	w.Center()
 	w.Show()
@@ -0,0 +1,35 @@
+//go:build linux
+
+package services
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// saveForegroundWindow returns the focused X11 window ID (via xdotool) so focus
+// can be handed back after the overlay is shown. Returns 0 on Wayland sessions,
+// where clients can neither query nor set focus; the compositor returns focus
+// to the previous window when the overlay hides.
+func saveForegroundWindow() uintptr {
+	if os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") == "" {
+		return 0
+	}
+	out, err := exec.Command("xdotool", "getactivewindow").Output()
+	if err != nil {
+		return 0
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uintptr(id)
+}
+
+func restoreForegroundWindow(hwnd uintptr) {
+	if hwnd != 0 {
+		_ = exec.Command("xdotool", "windowactivate", strconv.FormatUint(uint64(hwnd), 10)).Run()
+	}
+}
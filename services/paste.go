@@ -6,7 +6,10 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
 )
 
 // pasteText inserts text into the currently focused input.
@@ -22,6 +25,9 @@ import (
 //   - All GUI apps (Firefox, Chrome, Kate, LibreOffice, etc.)
 //   - TUI apps inside terminals (Claude Code, vim, etc.)
 //   - On macOS/Windows: Cmd+V / Ctrl+V are universal, so no issue there.
+//
+// Some terminals paste the primary selection on Shift+Insert, so the Linux
+// shortcut is configurable (LinuxPasteKey: "ctrl+v", "ctrl+shift+v").
 func pasteText(text string) error {
 	if text == "" {
 		return nil
@@ -38,9 +44,85 @@ func pasteText(text string) error {
 	return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 }
 
+// clipboardRestoreDelay is how long the pasted text stays on the clipboard
+// before the user's clipboard is put back (the target app must read it first).
+const clipboardRestoreDelay = 500 * time.Millisecond
+
+// clipboardRestore is the single pending clipboard restore. Rapid dictations
+// would otherwise race: an older restore could land after a newer paste wrote
+// its text, and the newer paste would save the older transcription as the
+// "original" clipboard.
+var clipboardRestore struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	saved string // the user's clipboard, to be restored
+	gen   int    // bumped on every schedule/cancel; a stale timer does nothing
+}
+
+// cancelClipboardRestore stops the pending restore, if any, and returns the
+// clipboard content it would have restored — the user's real clipboard, which
+// the next paste must restore instead of re-reading the clipboard.
+func cancelClipboardRestore() (string, bool) {
+	clipboardRestore.mu.Lock()
+	defer clipboardRestore.mu.Unlock()
+	if clipboardRestore.timer == nil {
+		return "", false
+	}
+	clipboardRestore.timer.Stop()
+	clipboardRestore.timer = nil
+	clipboardRestore.gen++
+	return clipboardRestore.saved, true
+}
+
+// scheduleClipboardRestore writes saved back to the clipboard after delay,
+// replacing any pending restore. The write runs with the lock held, so a paste
+// starting meanwhile waits for it and then sees the restored clipboard.
+func scheduleClipboardRestore(saved string, delay time.Duration, write func(string) error) {
+	clipboardRestore.mu.Lock()
+	defer clipboardRestore.mu.Unlock()
+	if clipboardRestore.timer != nil {
+		clipboardRestore.timer.Stop()
+	}
+	clipboardRestore.gen++
+	gen := clipboardRestore.gen
+	clipboardRestore.saved = saved
+	clipboardRestore.timer = time.AfterFunc(delay, func() {
+		clipboardRestore.mu.Lock()
+		defer clipboardRestore.mu.Unlock()
+		if clipboardRestore.gen != gen {
+			return // cancelled or superseded by a newer paste
+		}
+		clipboardRestore.timer = nil
+		if err := write(saved); err != nil {
+			log.Printf("Clipboard restore failed: %v", err)
+		}
+	})
+}
+
+// saveClipboard returns the clipboard to restore after a paste: the content a
+// still-pending restore holds, or else what read returns.
+func saveClipboard(read func() (string, bool)) (string, bool) {
+	if saved, ok := cancelClipboardRestore(); ok {
+		return saved, true
+	}
+	return read()
+}
+
+// clipboardToRestore returns the clipboard to put back after this paste, or
+// false if there is nothing to restore: the clipboard couldn't be read, or
+// RestoreClipboard is off. In that case a pending restore is dropped as well,
+// so the transcription stays on the clipboard for a manual re-paste.
+func clipboardToRestore(read func() (string, bool)) (string, bool) {
+	if !restoreClipboard() {
+		cancelClipboardRestore()
+		return "", false
+	}
+	return saveClipboard(read)
+}
+
 func pasteTextLinux(text string) error {
 	// 1. Save current clipboard
-	saved, hadClipboard := saveClipboardLinux()
+	saved, hadClipboard := clipboardToRestore(saveClipboardLinux)
 
 	// 2. Write text to clipboard via wl-copy (Wayland) or xclip (X11)
 	if err := writeClipboardLinux(text); err != nil {
@@ -50,8 +132,8 @@ func pasteTextLinux(text string) error {
 	// Small delay to ensure clipboard is ready
 	time.Sleep(30 * time.Millisecond)
 
-	// 3. Simulate Shift+Insert (universal paste shortcut)
-	if err := simulateShiftInsertLinux(); err != nil {
+	// 3. Simulate the paste shortcut (Shift+Insert unless LinuxPasteKey says otherwise)
+	if err := simulatePasteLinux(linuxPasteShortcut(linuxPasteKey())); err != nil {
 		return fmt.Errorf("failed to simulate paste: %w", err)
 	}
 
@@ -59,23 +141,51 @@ func pasteTextLinux(text string) error {
 
 	// 4. Restore original clipboard after delay (in background)
 	if hadClipboard {
-		go func() {
-			time.Sleep(500 * time.Millisecond)
-			_ = writeClipboardLinux(saved)
-		}()
+		scheduleClipboardRestore(saved, clipboardRestoreDelay, writeClipboardLinux)
 	}
 
 	return nil
 }
 
+// outputMode returns the configured text output mode ("" = clipboard paste).
+func outputMode() string {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return ""
+	}
+	return cfg.OutputMode
+}
+
+// restoreClipboard reports whether the user's clipboard is put back after a
+// paste (RestoreClipboard, default on).
+func restoreClipboard() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return true
+	}
+	return cfg.RestoreClipboard == nil || *cfg.RestoreClipboard
+}
+
 func pasteTextDarwin(text string) error {
+	// Accessibility mode: insert into the focused element directly, leaving the
+	// clipboard untouched. Falls back to clipboard paste if AX is not permitted
+	// or the focused element does not accept the text.
+	if outputMode() == "accessibility" {
+		err := axInsertText(text)
+		if err == nil {
+			log.Printf("Text inserted via Accessibility API (%d chars)", len(text))
+			return nil
+		}
+		log.Printf("Accessibility insert failed, falling back to clipboard: %v", err)
+	}
+
 	// Save clipboard
-	saved, hadClipboard := saveClipboardDarwin()
+	saved, hadClipboard := clipboardToRestore(saveClipboardDarwin)
 
 	// Write to clipboard via pbcopy
-	cmd := exec.Command("pbcopy")
-	cmd.Stdin = strings.NewReader(text)
-	if err := cmd.Run(); err != nil {
+	if err := writeClipboardDarwin(text); err != nil {
 		return fmt.Errorf("pbcopy failed: %w", err)
 	}
 
@@ -90,18 +200,24 @@ func pasteTextDarwin(text string) error {
 	log.Printf("Text pasted via clipboard (%d chars)", len(text))
 
 	if hadClipboard {
-		go func() {
-			time.Sleep(500 * time.Millisecond)
-			cmd := exec.Command("pbcopy")
-			cmd.Stdin = strings.NewReader(saved)
-			_ = cmd.Run()
-		}()
+		scheduleClipboardRestore(saved, clipboardRestoreDelay, writeClipboardDarwin)
 	}
 
 	return nil
 }
 
 func pasteTextWindows(text string) error {
+	// UIA mode: write into the focused control via UI Automation. Controls without
+	// a writable ValuePattern (or with an unresolvable caret) fall through to SendInput.
+	if outputMode() == "uia" {
+		err := winUIAInsert(text)
+		if err == nil {
+			log.Printf("Text inserted via UI Automation (%d chars)", len(text))
+			return nil
+		}
+		log.Printf("UIA insert failed, falling back to SendInput: %v", err)
+	}
+
 	// Type text directly via SendInput with KEYEVENTF_UNICODE.
 	// This bypasses clipboard and keyboard layout, working in all apps
 	// including Windows Terminal and PowerShell.
@@ -121,57 +237,121 @@ func pasteTextWindows(text string) error {
 
 // --- Clipboard save/restore helpers ---
 
+// Clipboard and key tools come from linuxPasteTools: only installed ones,
+// in the order that suits the session (Wayland or X11).
+
 func saveClipboardLinux() (string, bool) {
-	// Try wl-paste (Wayland)
-	if out, err := exec.Command("wl-paste", "--no-newline").Output(); err == nil {
-		return string(out), true
-	}
-	// Try xclip (X11)
-	if out, err := exec.Command("xclip", "-selection", "clipboard", "-o").Output(); err == nil {
-		return string(out), true
+	for _, tool := range linuxPasteTools().clipboard {
+		var cmd *exec.Cmd
+		switch tool {
+		case "wl-copy":
+			cmd = exec.Command("wl-paste", "--no-newline") // same wl-clipboard package
+		case "xclip":
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-o")
+		}
+		if out, err := cmd.Output(); err == nil {
+			return string(out), true
+		}
 	}
 	return "", false
 }
 
 func writeClipboardLinux(text string) error {
-	// Try wl-copy (Wayland)
-	if path, err := exec.LookPath("wl-copy"); err == nil {
-		cmd := exec.Command(path)
-		cmd.Stdin = strings.NewReader(text)
-		if err := cmd.Run(); err == nil {
-			return nil
+	tc := linuxPasteTools()
+	for _, tool := range tc.clipboard {
+		cmd := exec.Command(tool)
+		if tool == "xclip" {
+			cmd.Args = append(cmd.Args, "-selection", "clipboard")
 		}
-	}
-	// Try xclip (X11)
-	if path, err := exec.LookPath("xclip"); err == nil {
-		cmd := exec.Command(path, "-selection", "clipboard")
 		cmd.Stdin = strings.NewReader(text)
 		if err := cmd.Run(); err == nil {
 			return nil
 		}
 	}
-	return fmt.Errorf("no clipboard tool found (install wl-clipboard or xclip)")
+	if len(tc.clipboard) == 0 {
+		return fmt.Errorf("no clipboard tool found (%s)", tc.hint())
+	}
+	return fmt.Errorf("clipboard tools %v failed", tc.clipboard)
 }
 
-func simulateShiftInsertLinux() error {
-	// 1. ydotool — kernel-level uinput, works everywhere (Wayland, X11, TUI, terminals)
-	//    Shift=42, Insert=110 (scancodes)
-	if path, err := exec.LookPath("ydotool"); err == nil {
-		if err := exec.Command(path, "key", "42:1", "110:1", "110:0", "42:0").Run(); err == nil {
-			return nil
-		}
+// pasteShortcut is a Linux paste key combination in each tool's syntax.
+type pasteShortcut struct {
+	ydotool []string // "key" args: <scancode>:1 press, <scancode>:0 release
+	wtype   []string
+	xdotool string
+}
+
+// linuxPasteShortcuts maps the LinuxPasteKey setting to key events.
+// Scancodes: LeftCtrl=29, LeftShift=42, V=47, Insert=110.
+var linuxPasteShortcuts = map[string]pasteShortcut{
+	"shift+insert": {
+		ydotool: []string{"42:1", "110:1", "110:0", "42:0"},
+		wtype:   []string{"-M", "shift", "-k", "Insert"},
+		xdotool: "shift+Insert",
+	},
+	"ctrl+v": {
+		ydotool: []string{"29:1", "47:1", "47:0", "29:0"},
+		wtype:   []string{"-M", "ctrl", "-k", "v"},
+		xdotool: "ctrl+v",
+	},
+	"ctrl+shift+v": {
+		ydotool: []string{"29:1", "42:1", "47:1", "47:0", "42:0", "29:0"},
+		wtype:   []string{"-M", "ctrl", "-M", "shift", "-k", "v"},
+		xdotool: "ctrl+shift+v",
+	},
+}
+
+// defaultLinuxPasteKey is used when LinuxPasteKey is unset or unknown.
+const defaultLinuxPasteKey = "shift+insert"
+
+// linuxPasteShortcut resolves a LinuxPasteKey value.
+func linuxPasteShortcut(key string) pasteShortcut {
+	if sc, ok := linuxPasteShortcuts[strings.ToLower(strings.TrimSpace(key))]; ok {
+		return sc
+	}
+	return linuxPasteShortcuts[defaultLinuxPasteKey]
+}
+
+// linuxPasteKey returns the configured Linux paste shortcut.
+func linuxPasteKey() string {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+		return defaultLinuxPasteKey
+	}
+	return cfg.LinuxPasteKey
+}
+
+func simulatePasteLinux(sc pasteShortcut) error {
+	tc := linuxPasteTools()
+	if len(tc.keys) == 0 {
+		return fmt.Errorf("no key simulation tool found (%s)", tc.hint())
 	}
-	// 2. wtype — Wayland virtual keyboard (works in GUI apps, may have issues in TUI)
-	if path, err := exec.LookPath("wtype"); err == nil {
-		if err := exec.Command(path, "-M", "shift", "-k", "Insert").Run(); err == nil {
+	// A tool can be installed and still fail (ydotool without its daemon), so
+	// the next one is tried.
+	var err error
+	for _, tool := range tc.keys {
+		var cmd *exec.Cmd
+		switch tool {
+		case "ydotool": // kernel-level uinput, works everywhere (Wayland, X11, TUI, terminals)
+			cmd = exec.Command(tool, append([]string{"key"}, sc.ydotool...)...)
+		case "wtype": // Wayland virtual keyboard (works in GUI apps, may have issues in TUI)
+			cmd = exec.Command(tool, sc.wtype...)
+		case "xdotool":
+			cmd = exec.Command(tool, "key", "--clearmodifiers", sc.xdotool)
+		}
+		if err = cmd.Run(); err == nil {
 			return nil
 		}
+		log.Printf("Paste: %s failed: %v", tool, err)
 	}
-	// 3. xdotool — X11 fallback
-	if path, err := exec.LookPath("xdotool"); err == nil {
-		return exec.Command(path, "key", "--clearmodifiers", "shift+Insert").Run()
-	}
-	return fmt.Errorf("no key simulation tool found (install ydotool, wtype, or xdotool)")
+	return err
+}
+
+func writeClipboardDarwin(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
 }
 
 func saveClipboardDarwin() (string, bool) {
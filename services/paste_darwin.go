@@ -0,0 +1,121 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices -framework CoreFoundation
+#include <stdlib.h>
+#include <ApplicationServices/ApplicationServices.h>
+
+static int ax_trusted(void) {
+	return AXIsProcessTrusted() ? 1 : 0;
+}
+
+// ax_insert_text replaces the selection (or inserts at the caret) of the
+// focused UI element. Returns an AXError code, 0 on success.
+static int ax_insert_text(const char *utf8) {
+	AXUIElementRef sys = AXUIElementCreateSystemWide();
+	CFTypeRef focused = NULL;
+	AXError err = AXUIElementCopyAttributeValue(sys, kAXFocusedUIElementAttribute, &focused);
+	CFRelease(sys);
+	if (err != kAXErrorSuccess) {
+		return (int)err;
+	}
+	if (focused == NULL) {
+		return (int)kAXErrorNoValue;
+	}
+
+	CFStringRef str = CFStringCreateWithCString(kCFAllocatorDefault, utf8, kCFStringEncodingUTF8);
+	err = AXUIElementSetAttributeValue((AXUIElementRef)focused, kAXSelectedTextAttribute, str);
+	CFRelease(str);
+	CFRelease(focused);
+	return (int)err;
+}
+
+// ax_preceding_text copies up to max_chars characters before the caret of the
+// focused UI element into a malloc'd UTF-8 string (*out, caller frees).
+// Returns an AXError code, 0 on success.
+static int ax_preceding_text(long max_chars, char **out) {
+	*out = NULL;
+	AXUIElementRef sys = AXUIElementCreateSystemWide();
+	CFTypeRef focused = NULL;
+	AXError err = AXUIElementCopyAttributeValue(sys, kAXFocusedUIElementAttribute, &focused);
+	CFRelease(sys);
+	if (err != kAXErrorSuccess) {
+		return (int)err;
+	}
+	if (focused == NULL) {
+		return (int)kAXErrorNoValue;
+	}
+
+	CFTypeRef rangeVal = NULL;
+	CFTypeRef value = NULL;
+	err = AXUIElementCopyAttributeValue((AXUIElementRef)focused, kAXSelectedTextRangeAttribute, &rangeVal);
+	if (err == kAXErrorSuccess) {
+		err = AXUIElementCopyAttributeValue((AXUIElementRef)focused, kAXValueAttribute, &value);
+	}
+	CFRelease(focused);
+	if (err != kAXErrorSuccess) {
+		if (rangeVal) CFRelease(rangeVal);
+		return (int)err;
+	}
+
+	CFRange sel;
+	int ok = AXValueGetValue((AXValueRef)rangeVal, kAXValueCFRangeType, &sel);
+	CFRelease(rangeVal);
+	if (!ok || value == NULL || CFGetTypeID(value) != CFStringGetTypeID()) {
+		if (value) CFRelease(value);
+		return (int)kAXErrorNoValue;
+	}
+
+	CFIndex end = sel.location;
+	if (end > CFStringGetLength((CFStringRef)value)) {
+		end = CFStringGetLength((CFStringRef)value);
+	}
+	CFIndex start = end > max_chars ? end - max_chars : 0;
+	CFStringRef sub = CFStringCreateWithSubstring(kCFAllocatorDefault, (CFStringRef)value, CFRangeMake(start, end - start));
+	CFRelease(value);
+
+	CFIndex size = CFStringGetMaximumSizeForEncoding(CFStringGetLength(sub), kCFStringEncodingUTF8) + 1;
+	*out = malloc(size);
+	if (!CFStringGetCString(sub, *out, size, kCFStringEncodingUTF8)) {
+		(*out)[0] = 0;
+	}
+	CFRelease(sub);
+	return 0;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// axInsertText inserts text into the focused element via the Accessibility API.
+// Requires the app to be granted Accessibility permission in System Settings.
+func axInsertText(text string) error {
+	if C.ax_trusted() == 0 {
+		return fmt.Errorf("accessibility permission not granted")
+	}
+	cs := C.CString(text)
+	defer C.free(unsafe.Pointer(cs))
+	if rc := C.ax_insert_text(cs); rc != 0 {
+		return fmt.Errorf("AX insert failed (AXError %d)", int(rc))
+	}
+	return nil
+}
+
+// axPrecedingText returns up to maxChars characters before the caret of the
+// focused element via the Accessibility API.
+func axPrecedingText(maxChars int) (string, error) {
+	if C.ax_trusted() == 0 {
+		return "", fmt.Errorf("accessibility permission not granted")
+	}
+	var out *C.char
+	if rc := C.ax_preceding_text(C.long(maxChars), &out); rc != 0 {
+		return "", fmt.Errorf("AX read failed (AXError %d)", int(rc))
+	}
+	defer C.free(unsafe.Pointer(out))
+	return C.GoString(out), nil
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package services
+
+import "fmt"
+
+func axInsertText(_ string) error           { return fmt.Errorf("not darwin") }
+func axPrecedingText(_ int) (string, error) { return "", fmt.Errorf("not darwin") }
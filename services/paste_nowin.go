@@ -4,6 +4,10 @@ package services
 
 import "fmt"
 
-func winClipWrite(_ string) error  { return fmt.Errorf("not windows") }
-func winClipRead() (string, bool)  { return "", false }
-func winSendCtrlV() error          { return fmt.Errorf("not windows") }
+func winClipWrite(_ string) error            { return fmt.Errorf("not windows") }
+func winClipRead() (string, bool)            { return "", false }
+func winSendCtrlV() error                    { return fmt.Errorf("not windows") }
+func winTypeUnicode(_ string) error          { return fmt.Errorf("not windows") }
+func winUIAInsert(_ string) error            { return fmt.Errorf("not windows") }
+func winPrecedingText(_ int) (string, error) { return "", fmt.Errorf("not windows") }
+func winSendKey(_ uint16, _ bool) error      { return fmt.Errorf("not windows") }
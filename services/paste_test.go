@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClipboardRestore(t *testing.T) {
+	t.Cleanup(func() { cancelClipboardRestore() })
+
+	restored := make(chan string, 4)
+	write := func(s string) error {
+		restored <- s
+		return nil
+	}
+	read := func() (string, bool) { return "pasted text", true }
+
+	// A paste starting while a restore is pending cancels it and inherits the
+	// user's original clipboard instead of reading the pasted text back.
+	scheduleClipboardRestore("original", time.Hour, write)
+	saved, ok := saveClipboard(read)
+	if !ok || saved != "original" {
+		t.Fatalf("saveClipboard with pending restore = %q, %v; want original", saved, ok)
+	}
+	if _, ok := cancelClipboardRestore(); ok {
+		t.Error("restore still pending after saveClipboard")
+	}
+
+	// With nothing pending the clipboard is read.
+	if saved, _ := saveClipboard(read); saved != "pasted text" {
+		t.Errorf("saveClipboard without pending restore = %q, want pasted text", saved)
+	}
+
+	// Only the latest scheduled restore runs.
+	scheduleClipboardRestore("stale", 10*time.Millisecond, write)
+	scheduleClipboardRestore("latest", 10*time.Millisecond, write)
+	select {
+	case got := <-restored:
+		if got != "latest" {
+			t.Errorf("restored %q, want latest", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("restore did not run")
+	}
+	select {
+	case got := <-restored:
+		t.Errorf("superseded restore also ran: %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLinuxPasteShortcut(t *testing.T) {
+	tests := []struct {
+		key     string
+		xdotool string
+		ydotool string
+	}{
+		{"", "shift+Insert", "42:1 110:1 110:0 42:0"},
+		{"bogus", "shift+Insert", "42:1 110:1 110:0 42:0"},
+		{"ctrl+v", "ctrl+v", "29:1 47:1 47:0 29:0"},
+		{" Ctrl+Shift+V ", "ctrl+shift+v", "29:1 42:1 47:1 47:0 42:0 29:0"},
+	}
+	for _, tt := range tests {
+		sc := linuxPasteShortcut(tt.key)
+		if sc.xdotool != tt.xdotool {
+			t.Errorf("linuxPasteShortcut(%q).xdotool = %q, want %q", tt.key, sc.xdotool, tt.xdotool)
+		}
+		if got := strings.Join(sc.ydotool, " "); got != tt.ydotool {
+			t.Errorf("linuxPasteShortcut(%q).ydotool = %q, want %q", tt.key, got, tt.ydotool)
+		}
+	}
+}
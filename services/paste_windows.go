@@ -163,3 +163,31 @@ func winTypeUnicode(text string) error {
 	}
 	return nil
 }
+
+// winSendKey presses and releases a single virtual key via SendInput.
+// extended marks keys from the extended set (arrows, Delete, Home, End).
+func winSendKey(vk uint16, extended bool) error {
+	const (
+		inputKeyboard        = 1
+		keyeventfExtendedKey = 0x0001
+		keyeventfKeyUp       = 0x0002
+	)
+	var flags uint32
+	if extended {
+		flags = keyeventfExtendedKey
+	}
+	scan := vkToScan(vk)
+	inputs := []keyInput{
+		{inputType: inputKeyboard, wVk: vk, wScan: scan, dwFlags: flags},
+		{inputType: inputKeyboard, wVk: vk, wScan: scan, dwFlags: flags | keyeventfKeyUp},
+	}
+	ret, _, err := pSendInput.Call(
+		uintptr(len(inputs)),
+		uintptr(unsafe.Pointer(&inputs[0])),
+		uintptr(unsafe.Sizeof(inputs[0])),
+	)
+	if ret != uintptr(len(inputs)) {
+		return fmt.Errorf("SendInput: sent %d/%d key events: %v", ret, len(inputs), err)
+	}
+	return nil
+}
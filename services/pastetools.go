@@ -0,0 +1,140 @@
+package services
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// pasteToolchain is the set of Linux clipboard and key simulation tools the
+// paste path uses, chosen for the session type once instead of probing every
+// tool on every paste.
+type pasteToolchain struct {
+	session   string   // "wayland", "x11" or "" (unknown, e.g. a tty)
+	clipboard []string // installed clipboard tools, in the order tried: "wl-copy", "xclip"
+	keys      []string // installed key simulators, in the order tried: "ydotool", "wtype", "xdotool"
+}
+
+// linuxSession returns the display server type from XDG_SESSION_TYPE and
+// WAYLAND_DISPLAY / DISPLAY. XWayland sets DISPLAY too, so Wayland wins.
+func linuxSession(getenv func(string) string) string {
+	switch strings.ToLower(getenv("XDG_SESSION_TYPE")) {
+	case "wayland":
+		return "wayland"
+	case "x11":
+		return "x11"
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		return "wayland"
+	}
+	if getenv("DISPLAY") != "" {
+		return "x11"
+	}
+	return ""
+}
+
+// pasteToolPreference returns the clipboard and key tools that can work in
+// session, best first. On Wayland, ydotool (uinput) reaches terminals and TUI
+// apps that wtype's virtual keyboard may miss, and xdotool only reaches
+// XWayland windows. On X11, wl-copy and wtype don't work at all.
+func pasteToolPreference(session string) (clipboard, keys []string) {
+	switch session {
+	case "wayland":
+		return []string{"wl-copy", "xclip"}, []string{"ydotool", "wtype", "xdotool"}
+	case "x11":
+		return []string{"xclip"}, []string{"xdotool", "ydotool"}
+	}
+	return []string{"wl-copy", "xclip"}, []string{"ydotool", "wtype", "xdotool"}
+}
+
+// detectPasteTools picks the installed tools for the current session.
+func detectPasteTools(getenv func(string) string, lookPath func(string) (string, error)) pasteToolchain {
+	tc := pasteToolchain{session: linuxSession(getenv)}
+	clipboard, keys := pasteToolPreference(tc.session)
+	for _, tool := range clipboard {
+		if _, err := lookPath(tool); err == nil {
+			tc.clipboard = append(tc.clipboard, tool)
+		}
+	}
+	for _, tool := range keys {
+		if _, err := lookPath(tool); err == nil {
+			tc.keys = append(tc.keys, tool)
+		}
+	}
+	return tc
+}
+
+// hint tells the user what to install when part of the toolchain is
+// missing; "" if pasting should work.
+func (tc pasteToolchain) hint() string {
+	var missing []string
+	if len(tc.clipboard) == 0 {
+		switch tc.session {
+		case "wayland":
+			missing = append(missing, "wl-clipboard for Wayland")
+		case "x11":
+			missing = append(missing, "xclip")
+		default:
+			missing = append(missing, "wl-clipboard (Wayland) or xclip (X11)")
+		}
+	}
+	if len(tc.keys) == 0 {
+		switch tc.session {
+		case "wayland":
+			missing = append(missing, "ydotool or wtype")
+		case "x11":
+			missing = append(missing, "xdotool")
+		default:
+			missing = append(missing, "ydotool, wtype or xdotool")
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return "install " + strings.Join(missing, " and ")
+}
+
+var (
+	pasteToolsMu sync.Mutex
+	pasteTools   *pasteToolchain // cached by linuxPasteTools
+)
+
+// linuxPasteTools returns the cached toolchain, detecting it on first use.
+func linuxPasteTools() pasteToolchain {
+	pasteToolsMu.Lock()
+	tc := pasteTools
+	pasteToolsMu.Unlock()
+	if tc != nil {
+		return *tc
+	}
+	return refreshPasteTools()
+}
+
+// refreshPasteTools detects the toolchain again (a tool may have been
+// installed since), caches and logs it.
+func refreshPasteTools() pasteToolchain {
+	tc := detectPasteTools(os.Getenv, exec.LookPath)
+	pasteToolsMu.Lock()
+	pasteTools = &tc
+	pasteToolsMu.Unlock()
+
+	session := tc.session
+	if session == "" {
+		session = "unknown"
+	}
+	log.Printf("Paste tools (%s session): clipboard %v, key simulation %v", session, tc.clipboard, tc.keys)
+	if h := tc.hint(); h != "" {
+		log.Printf("Paste will fail: %s", h)
+	}
+	return tc
+}
+
+// PasteToolsStatus reports the Linux paste toolchain (CheckPasteTools).
+type PasteToolsStatus struct {
+	Session   string   `json:"session"`   // "wayland", "x11" or "" (unknown)
+	Clipboard []string `json:"clipboard"` // installed clipboard tools, in the order used
+	Keys      []string `json:"keys"`      // installed key simulation tools, in the order used
+	Hint      string   `json:"hint"`      // what to install, e.g. "install wl-clipboard for Wayland"; "" if paste should work
+}
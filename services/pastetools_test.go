@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDetectPasteTools(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		installed     []string
+		wantSession   string
+		wantClipboard []string
+		wantKeys      []string
+		wantHint      string
+	}{
+		{
+			name:          "wayland",
+			env:           map[string]string{"XDG_SESSION_TYPE": "wayland", "WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed:     []string{"xdotool", "wtype", "wl-copy", "xclip"},
+			wantSession:   "wayland",
+			wantClipboard: []string{"wl-copy", "xclip"},
+			wantKeys:      []string{"wtype", "xdotool"},
+		},
+		{
+			name:          "wayland by display only",
+			env:           map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed:     []string{"xclip", "xdotool"},
+			wantSession:   "wayland",
+			wantClipboard: []string{"xclip"},
+			wantKeys:      []string{"xdotool"},
+		},
+		{
+			name:          "x11 skips wayland tools",
+			env:           map[string]string{"XDG_SESSION_TYPE": "x11", "DISPLAY": ":0"},
+			installed:     []string{"wl-copy", "wtype", "ydotool", "xdotool", "xclip"},
+			wantSession:   "x11",
+			wantClipboard: []string{"xclip"},
+			wantKeys:      []string{"xdotool", "ydotool"},
+		},
+		{
+			name:        "wayland without tools",
+			env:         map[string]string{"XDG_SESSION_TYPE": "wayland"},
+			wantSession: "wayland",
+			wantHint:    "install wl-clipboard for Wayland and ydotool or wtype",
+		},
+		{
+			name:          "x11 without key tool",
+			env:           map[string]string{"DISPLAY": ":1"},
+			installed:     []string{"xclip"},
+			wantSession:   "x11",
+			wantClipboard: []string{"xclip"},
+			wantHint:      "install xdotool",
+		},
+		{
+			name:      "unknown session",
+			installed: []string{"ydotool"},
+			wantKeys:  []string{"ydotool"},
+			wantHint:  "install wl-clipboard (Wayland) or xclip (X11)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			lookPath := func(tool string) (string, error) {
+				if slices.Contains(tt.installed, tool) {
+					return "/usr/bin/" + tool, nil
+				}
+				return "", errors.New("not found")
+			}
+			tc := detectPasteTools(getenv, lookPath)
+			if tc.session != tt.wantSession || !slices.Equal(tc.clipboard, tt.wantClipboard) || !slices.Equal(tc.keys, tt.wantKeys) {
+				t.Errorf("detectPasteTools = %+v, want session %q, clipboard %v, keys %v",
+					tc, tt.wantSession, tt.wantClipboard, tt.wantKeys)
+			}
+			if got := tc.hint(); got != tt.wantHint {
+				t.Errorf("hint() = %q, want %q", got, tt.wantHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// postCommandTimeout bounds the PostCommand hook, so a hung script can't
+// freeze the recording pipeline.
+const postCommandTimeout = 15 * time.Second
+
+// postCommandEnv is the environment variable that carries the transcription
+// to the PostCommand hook; {text} expands to a quoted reference to it.
+const postCommandEnv = "MORGOTTALK_TEXT"
+
+// applyPostCommand runs the PostCommand hook, if one is set, on a finished
+// transcription and returns the text to paste: the hook's output when
+// PostCommandReplacesText is on and the hook succeeded, else text unchanged.
+func applyPostCommand(text string, trace traceLog) string {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+	}
+	command := strings.TrimSpace(cfg.PostCommand)
+	if command == "" {
+		return text
+	}
+	out, err := runPostCommand(command, text, postCommandTimeout)
+	if err != nil {
+		log.Printf("Post command failed: %v", err)
+		trace.printf("post command failed: %v", err)
+		return text
+	}
+	trace.printf("post command output: %q", out)
+	if !cfg.PostCommandReplacesText || out == "" {
+		return text
+	}
+	return out
+}
+
+// runPostCommand runs command through the system shell (sh -c, PowerShell on
+// Windows) with text on stdin and in $MORGOTTALK_TEXT. {text} in command is
+// replaced by a quoted reference to that variable, never by the text itself,
+// so dictated quotes or "; rm" can't inject shell code. It returns stdout
+// without the trailing newline; stderr is logged.
+func runPostCommand(command, text string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = strings.ReplaceAll(command, "{text}", "$env:"+postCommandEnv)
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
+		hideWindow(cmd)
+	} else {
+		command = strings.ReplaceAll(command, "{text}", `"$`+postCommandEnv+`"`)
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), postCommandEnv+"="+text)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children that keep the output pipes open must not outlive the timeout.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		log.Printf("Post command stderr: %s", msg)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out after %v", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
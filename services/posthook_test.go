@@ -0,0 +1,42 @@
+package services
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunPostCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	// Shell metacharacters in the text reach the script as data.
+	text := `it's "$(echo pwned)"; exit 3`
+	tests := []struct {
+		name, command, want string
+	}{
+		{"stdin", "tr a-z A-Z", `IT'S "$(ECHO PWNED)"; EXIT 3`},
+		{"placeholder", "printf '<%s>' {text}", "<" + text + ">"},
+		{"env", `printf %s "$MORGOTTALK_TEXT" | wc -c | tr -d ' '`, "28"},
+		{"trailing newline trimmed", "echo done", "done"},
+	}
+	for _, tt := range tests {
+		got, err := runPostCommand(tt.command, text, 5*time.Second)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: runPostCommand = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := runPostCommand("echo oops >&2; exit 2", text, 5*time.Second); err == nil {
+		t.Error("failing command = nil error")
+	}
+	start := time.Now()
+	_, err := runPostCommand("sleep 10", text, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("hung command error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("hung command took %v, want it killed at the timeout", elapsed)
+	}
+}
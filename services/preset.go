@@ -1,14 +1,22 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v3/pkg/application"
@@ -16,18 +24,59 @@ import (
 	"github.com/UberMorgott/transcribation/internal/config"
 )
 
-const maxRecordDuration = 3 * time.Minute
+const (
+	defaultMaxRecordSeconds = 180
+
+	// Minimum recording duration (MinRecordMs): default 0.5s, and never below
+	// whisper.cpp's own 100ms floor.
+	defaultMinRecordMs = 500
+	minWhisperMs       = 100
+
+	// silentPeak is the peak level (about -50 dBFS) below which a recording is
+	// treated as no input at all: a muted or wrong microphone. Quiet speech
+	// still peaks well above it.
+	silentPeak = 0.003
+
+	// normalizeTargetPeak is the peak (-1 dBFS) NormalizeAudio presets boost
+	// quiet recordings to; maxNormalizeGain (+30 dB) caps the boost so a
+	// near-silent recording isn't turned into loud noise.
+	normalizeTargetPeak = 0.891
+	maxNormalizeGain    = 31.6
+
+	// Each preset may hold a global hotkey and a preloaded model, so the
+	// number of presets is capped to keep a runaway import from exhausting resources.
+	defaultMaxPresets = 50
+
+	// Recording buffers 16kHz mono float32 PCM, i.e. ~64KB per second in memory
+	// (~3.8MB/min). Past this length a memory estimate is logged.
+	recordMemoryWarnAfter = 30 * time.Minute
+)
 
 // PresetState represents the recording state of a preset.
 type PresetState struct {
 	ID    string `json:"id"`
-	State string `json:"state"` // "idle", "recording", "processing"
+	State string `json:"state"` // "idle", "recording", "processing", "queued"
 }
 
 // TranscriptionResult represents the result of a transcription operation.
 type TranscriptionResult struct {
-	Text  string `json:"text"`
-	Error string `json:"error"` // empty if successful
+	Text      string `json:"text"`
+	Error     string `json:"error"`               // empty if successful
+	NoiseOnly bool   `json:"noiseOnly,omitempty"` // nothing but noise markers ([MUSIC], [coughing]) was heard
+	TooShort  bool   `json:"tooShort,omitempty"`  // shorter than MinRecordMs, not transcribed
+
+	// MissingModel is set, together with Error, when the preset's model isn't
+	// downloaded; the UI can offer to download it instead of a generic failure.
+	MissingModel string `json:"missingModel,omitempty"`
+
+	metrics *transcriptionMetrics // set by transcribeBuffer when whisper ran
+}
+
+// transcriptionMetrics describes one inference, for transcription:metrics.
+type transcriptionMetrics struct {
+	model  string        // the model used, after any OOM downgrade
+	infer  time.Duration // whisper inference, including post-processing
+	chunks int           // chunks the audio was split into
 }
 
 // PresetService manages presets, recording, and transcription.
@@ -36,15 +85,43 @@ type PresetService struct {
 	cfg            *config.AppConfig
 	engines        map[string]*WhisperEngine // preset ID → loaded engine
 	engineLoading  map[string]bool           // preset ID → true if model load in progress
+	engineUsed     map[string]time.Time      // preset ID → last getOrLoadEngine, for LRU eviction
 	audio          *AudioCapture
 	history        *HistoryService
 	models         *ModelService
 	hotkeys        *HotkeyManager
-	states         map[string]string // preset ID → "idle"/"recording"/"processing"
+	states         map[string]string  // preset ID → "idle"/"recording"
+	pending        map[string]int     // preset ID → transcriptions queued or running ("processing")
+	jobs           []transcriptionJob // transcription queue, oldest first (transcribequeue.go)
+	jobsRunning    bool               // the transcription worker is draining jobs
+	shutDown       bool               // Shutdown ran: queued jobs are dropped, new ones refused
 	lastText       string
-	recordTimer    *time.Timer // auto-stop after maxRecordDuration
+	lastPasteEnd   rune        // last character of the last paste, 0 = nothing pasted yet (AutoSpace)
+	lastSamples    []float32   // audio of the last recording, for RetryLastTranscription
+	recordTimer    *time.Timer // auto-stop after maxRecordDuration()
 	recordingID    string      // preset ID being recorded (for auto-stop)
-	shutdownOnce   sync.Once
+	queuedID       string      // preset ID waiting to record once transcription finishes (BusyBehavior "queue")
+	queueGen       int         // bumped per queueRecording; an outdated waiter gives up
+	paused         bool        // hotkeys suspended (SetPaused); a recording in progress still finishes
+	verboseNext    bool        // log the next StopRecording step by step (SetVerboseNext)
+	onPauseChanged func(paused bool)
+	stopWatch      func() // stops the config.json watcher started by Init
+
+	// Presets sharing a hotkey: the winner of each press, by sibling group
+	// (pickSibling). pickMu is held across the focused-app lookup.
+	pickMu       sync.Mutex
+	siblingPicks map[string]siblingPick
+
+	// onPresetsChanged runs after presets are added, removed, edited, reordered
+	// or switched on/off (SetOnPresetsChanged), without s.mu held.
+	onPresetsChanged func()
+
+	// Re-armed hold recordings (Preset.RearmHold): audio or text of the
+	// segments cut at auto-stop while the key was still held.
+	heldAudio    []float32       // "join": earlier segments' samples
+	heldTexts    []*string       // "split": per-segment text, filled in as transcription finishes
+	heldSegments *sync.WaitGroup // "split": segment transcriptions in flight, one per recording
+	shutdownOnce sync.Once
 }
 
 func NewPresetService(history *HistoryService, models *ModelService) *PresetService {
@@ -56,9 +133,11 @@ func NewPresetService(history *HistoryService, models *ModelService) *PresetServ
 		cfg:           cfg,
 		engines:       make(map[string]*WhisperEngine),
 		engineLoading: make(map[string]bool),
+		engineUsed:    make(map[string]time.Time),
 		history:       history,
 		models:        models,
 		states:        make(map[string]string),
+		pending:       make(map[string]int),
 	}
 }
 
@@ -79,6 +158,10 @@ func (s *PresetService) Init() error {
 		func(presetID string) { s.onHotkeyPress(presetID) },
 		func(presetID string) { s.onHotkeyRelease(presetID) },
 	)
+	s.hotkeys.SetOnCancel(func(presetID string) {
+		log.Printf("onHotkeyCancel: preset=%s", presetID)
+		s.CancelRecording(presetID)
+	})
 	s.hotkeys.SetOnHookStatus(func(ok bool, msg string) {
 		if !ok {
 			log.Printf("PresetService: hook status FAILED: %s", msg)
@@ -89,15 +172,38 @@ func (s *PresetService) Init() error {
 	})
 	s.hotkeys.Start()
 
-	// Register hotkeys for enabled presets and preload models if keepModelLoaded
+	// Pick (and log) the paste tools for this session before the first paste.
+	if runtime.GOOS == "linux" {
+		refreshPasteTools()
+	}
+
+	// Register hotkeys for enabled presets and preload models if keepModelLoaded.
+	// A config saved over the preset cap (by hand, or by an older version) keeps
+	// its extra presets, but they get no hotkey or model.
+	limit := presetLimit(s.cfg)
 	log.Printf("PresetService.Init: activating %d presets...", len(s.cfg.Presets))
+	if len(s.cfg.Presets) > limit {
+		log.Printf("PresetService.Init: %d presets exceed maxPresets (%d); presets after #%d are not activated", len(s.cfg.Presets), limit, limit)
+	}
 	for i := range s.cfg.Presets {
 		p := &s.cfg.Presets[i]
 		s.states[p.ID] = "idle"
-		if p.Enabled {
+		if p.Enabled && i < limit {
 			s.activatePreset(p)
 		}
 	}
+	if s.cfg.PauseHotkey != "" {
+		if err := s.hotkeys.Register(pauseHotkeyID, s.cfg.PauseHotkey, "toggle"); err != nil {
+			log.Printf("Failed to register pause hotkey: %v", err)
+		}
+	}
+	if s.cfg.RepeatHotkey != "" {
+		if err := s.hotkeys.Register(repeatHotkeyID, s.cfg.RepeatHotkey, "toggle"); err != nil {
+			log.Printf("Failed to register repeat hotkey: %v", err)
+		}
+	}
+
+	s.stopWatch = config.Watch(s.onConfigChanged)
 
 	log.Println("PresetService.Init: completed successfully")
 	return nil
@@ -109,6 +215,8 @@ func (s *PresetService) activatePreset(p *config.Preset) {
 	if p.Hotkey != "" && s.hotkeys != nil {
 		if err := s.hotkeys.Register(p.ID, p.Hotkey, p.InputMode); err != nil {
 			log.Printf("Failed to register hotkey for preset %q: %v", p.Name, err)
+		} else if p.DoublePressCancel {
+			s.hotkeys.SetDoublePressCancel(p.ID, true)
 		}
 	}
 	if p.KeepModelLoaded {
@@ -132,7 +240,183 @@ func (s *PresetService) deactivatePreset(presetID string) {
 	s.mu.Unlock()
 }
 
+// HotkeyManager bindings of the global hotkeys: AppConfig.PauseHotkey and
+// AppConfig.RepeatHotkey.
+const (
+	pauseHotkeyID  = "pause-hotkeys"
+	repeatHotkeyID = "repeat-last"
+)
+
+// SetPaused suspends (true) or resumes all preset hotkeys, e.g. during a call.
+// A recording in progress still finishes: its stop press or hold release goes
+// through. Emits hotkeys:paused {paused}.
+func (s *PresetService) SetPaused(paused bool) {
+	s.mu.Lock()
+	if s.paused == paused {
+		s.mu.Unlock()
+		return
+	}
+	s.paused = paused
+	dropped := ""
+	if paused {
+		dropped, s.queuedID = s.queuedID, "" // a queued recording would start after the pause
+	}
+	fn := s.onPauseChanged
+	s.mu.Unlock()
+	if dropped != "" {
+		emitUnqueued(dropped)
+	}
+
+	log.Printf("Hotkeys paused: %v", paused)
+	if fn != nil {
+		fn(paused)
+	}
+	if app := application.Get(); app != nil {
+		app.Event.Emit("hotkeys:paused", map[string]any{"paused": paused})
+	}
+}
+
+// IsPaused reports whether hotkeys are suspended by SetPaused.
+func (s *PresetService) IsPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// SetOnPauseChanged registers a callback invoked after SetPaused changes the
+// state (used by the tray menu). Not exposed to the frontend.
+func (s *PresetService) SetOnPauseChanged(fn func(paused bool)) {
+	s.mu.Lock()
+	s.onPauseChanged = fn
+	s.mu.Unlock()
+}
+
+// SetOnPresetsChanged registers a callback invoked after the preset list or a
+// preset's name or enabled state changes (used by the tray menu). Not exposed
+// to the frontend.
+func (s *PresetService) SetOnPresetsChanged(fn func()) {
+	s.mu.Lock()
+	s.onPresetsChanged = fn
+	s.mu.Unlock()
+}
+
+// presetsChanged calls the SetOnPresetsChanged callback. Must be called
+// without s.mu held.
+func (s *PresetService) presetsChanged() {
+	s.mu.Lock()
+	fn := s.onPresetsChanged
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// SetPauseHotkey binds hotkey to toggle SetPaused and saves it; "" unbinds.
+// The pause hotkey itself keeps working while paused.
+func (s *PresetService) SetPauseHotkey(hotkey string) error {
+	return s.setGlobalHotkey(pauseHotkeyID, hotkey, func(cfg *config.AppConfig, hotkey string) {
+		cfg.PauseHotkey = hotkey
+	})
+}
+
+// SetRepeatHotkey binds the global hotkey that pastes the last transcription
+// again, e.g. after it landed in the wrong window; "" unbinds it.
+func (s *PresetService) SetRepeatHotkey(hotkey string) error {
+	return s.setGlobalHotkey(repeatHotkeyID, hotkey, func(cfg *config.AppConfig, hotkey string) {
+		cfg.RepeatHotkey = hotkey
+	})
+}
+
+// setGlobalHotkey checks hotkey against the preset hotkeys, stores it in the
+// config with set and (re)binds it as id.
+func (s *PresetService) setGlobalHotkey(id, hotkey string, set func(cfg *config.AppConfig, hotkey string)) error {
+	hotkey = strings.TrimSpace(hotkey)
+	if hotkey != "" {
+		keys, anySide, err := parseHotkey(hotkey)
+		if err != nil {
+			return fmt.Errorf("parse hotkey %q: %w", hotkey, err)
+		}
+		if s.hotkeys != nil {
+			if c := s.hotkeys.FindConflicts(id, keys, anySide); len(c) > 0 {
+				name := c[0].PresetID
+				s.mu.Lock()
+				if other := s.findPresetByID(name); other != nil {
+					name = other.Name
+				}
+				s.mu.Unlock()
+				return fmt.Errorf("hotkey %q is already used by preset %q", hotkey, name)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	set(s.cfg, hotkey)
+	err := config.Save(s.cfg)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if s.hotkeys != nil {
+		s.hotkeys.Unregister(id)
+		if hotkey != "" {
+			return s.hotkeys.Register(id, hotkey, "toggle")
+		}
+	}
+	return nil
+}
+
+// repeatLast pastes the last transcription again (RepeatHotkey).
+func (s *PresetService) repeatLast() {
+	text, ok := s.repeatText()
+	if !ok {
+		log.Printf("Repeat hotkey: nothing to paste")
+		return
+	}
+	if err := pasteText(text); err != nil {
+		log.Printf("Repeat paste failed: %v", err)
+	}
+}
+
+// repeatText returns the text for repeatLast: the last transcription, unless
+// there is none yet or a recording is in progress (the paste would land in
+// the middle of dictation).
+func (s *PresetService) repeatText() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastText == "" {
+		return "", false
+	}
+	for _, state := range s.states {
+		if state == "recording" {
+			return "", false
+		}
+	}
+	return s.lastText, true
+}
+
+// ignoredWhilePaused reports whether a hotkey event for presetID is dropped
+// because hotkeys are paused: everything except stopping a recording.
+func (s *PresetService) ignoredWhilePaused(presetID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused && s.states[presetID] != "recording"
+}
+
 func (s *PresetService) onHotkeyPress(presetID string) {
+	if presetID == pauseHotkeyID {
+		s.SetPaused(!s.IsPaused())
+		return
+	}
+	if s.ignoredWhilePaused(presetID) {
+		log.Printf("onHotkeyPress: preset=%s ignored, hotkeys paused", presetID)
+		return
+	}
+	if presetID == repeatHotkeyID {
+		s.repeatLast()
+		return
+	}
+
 	s.mu.Lock()
 	p := s.findPresetByID(presetID)
 	if p == nil {
@@ -141,16 +425,23 @@ func (s *PresetService) onHotkeyPress(presetID string) {
 		return
 	}
 	mode := p.InputMode
+	siblings := s.hotkeySiblings(p)
 	s.mu.Unlock()
 
+	// Presets sharing this hotkey all get the press; only the one matching the
+	// focused app (or the catch-all without AppMatch) handles it.
+	if len(siblings) > 1 {
+		if winner := s.pickSibling(presetID, siblings); winner != presetID {
+			return
+		}
+	}
+
 	log.Printf("onHotkeyPress: preset=%s mode=%s", presetID, mode)
 
 	switch mode {
 	case "hold":
-		if err := s.StartRecording(presetID); err != nil {
-			log.Printf("StartRecording failed: %v", err)
-		}
-	case "toggle":
+		s.startOrQueue(presetID)
+	case "toggle", "tap":
 		s.mu.Lock()
 		state := s.states[presetID]
 		s.mu.Unlock()
@@ -159,21 +450,109 @@ func (s *PresetService) onHotkeyPress(presetID string) {
 			if err != nil {
 				log.Printf("StopRecording failed: %v", err)
 			}
-			if result.Error != "" {
-				log.Printf("Transcription error: %s", result.Error)
-				if app := application.Get(); app != nil {
-					app.Event.Emit("transcription:error", map[string]string{"error": result.Error, "presetId": presetID})
-				}
-			}
+			emitTranscriptionError(presetID, result)
+		} else if s.unqueueRecording(presetID) {
+			log.Printf("Queued recording for preset %s cancelled", presetID)
 		} else {
-			if err := s.StartRecording(presetID); err != nil {
-				log.Printf("StartRecording failed: %v", err)
+			s.startOrQueue(presetID)
+		}
+	}
+}
+
+// busyPollInterval is how often a queued recording checks whether the
+// transcription queue has room again.
+var busyPollInterval = 50 * time.Millisecond
+
+// startOrQueue starts recording, or with BusyBehavior "queue" and the
+// transcription queue full, queues it to start when a transcription finishes.
+func (s *PresetService) startOrQueue(presetID string) {
+	err := s.StartRecording(presetID)
+	if errors.Is(err, errBusy) && busyBehavior() == "queue" {
+		s.queueRecording(presetID)
+		return
+	}
+	if err != nil {
+		log.Printf("StartRecording failed: %v", err)
+	}
+}
+
+// queueRecording starts presetID's recording as soon as no preset is recording
+// and the transcription queue has room. Only one recording is queued: a newer
+// press replaces it.
+func (s *PresetService) queueRecording(presetID string) {
+	s.mu.Lock()
+	replaced := s.queuedID
+	s.queuedID = presetID
+	s.queueGen++
+	gen := s.queueGen
+	s.mu.Unlock()
+	if replaced != "" && replaced != presetID {
+		emitUnqueued(replaced)
+	}
+	log.Printf("Preset %s queued until the current transcription finishes", presetID)
+	if app := application.Get(); app != nil {
+		app.Event.Emit("recording:queued", map[string]any{"presetId": presetID})
+	}
+
+	go func() {
+		for {
+			time.Sleep(busyPollInterval)
+			s.mu.Lock()
+			if s.queueGen != gen || s.queuedID == "" {
+				s.mu.Unlock()
+				return // cancelled or replaced
+			}
+			if !s.anyActive() {
+				s.queuedID = ""
+				s.mu.Unlock()
+				break
 			}
+			s.mu.Unlock()
+		}
+		if err := s.StartRecording(presetID); err != nil {
+			log.Printf("Queued StartRecording failed: %v", err)
+		}
+	}()
+}
+
+// unqueueRecording drops presetID's queued recording and reports whether
+// there was one.
+func (s *PresetService) unqueueRecording(presetID string) bool {
+	s.mu.Lock()
+	if s.queuedID != presetID {
+		s.mu.Unlock()
+		return false
+	}
+	s.queuedID = ""
+	s.mu.Unlock()
+	emitUnqueued(presetID)
+	return true
+}
+
+// emitUnqueued tells the UI that presetID's queued recording was dropped
+// before it started.
+func emitUnqueued(presetID string) {
+	if app := application.Get(); app != nil {
+		app.Event.Emit("recording:unqueued", map[string]any{"presetId": presetID})
+	}
+}
+
+// anyActive reports whether a preset is recording or the transcription
+// queue is full, i.e. StartRecording would fail. Must be called with s.mu held.
+func (s *PresetService) anyActive() bool {
+	for _, st := range s.states {
+		if st == "recording" {
+			return true
 		}
 	}
+	return s.pendingTranscriptions() >= maxPendingTranscriptions
 }
 
 func (s *PresetService) onHotkeyRelease(presetID string) {
+	if presetID == pauseHotkeyID || presetID == repeatHotkeyID || s.ignoredWhilePaused(presetID) {
+		return
+	}
+
 	s.mu.Lock()
 	p := s.findPresetByID(presetID)
 	if p == nil {
@@ -202,17 +581,33 @@ func (s *PresetService) onHotkeyRelease(presetID string) {
 
 	log.Printf("onHotkeyRelease: preset=%s state=%s", presetID, state)
 
+	// Released before a queued recording could start: nothing to record.
+	if state != "recording" && s.unqueueRecording(presetID) {
+		log.Printf("Queued recording for preset %s dropped on release", presetID)
+	}
+
 	if state == "recording" {
 		result, err := s.StopRecording(presetID)
 		if err != nil {
 			log.Printf("StopRecording failed: %v", err)
 		}
-		if result.Error != "" {
-			log.Printf("Transcription error: %s", result.Error)
-			if app := application.Get(); app != nil {
-				app.Event.Emit("transcription:error", map[string]string{"error": result.Error, "presetId": presetID})
-			}
-		}
+		emitTranscriptionError(presetID, result)
+	}
+}
+
+// emitTranscriptionError reports a failed StopRecording to the frontend.
+// missingModel is set when the failure is a model that isn't downloaded.
+func emitTranscriptionError(presetID string, result TranscriptionResult) {
+	if result.Error == "" {
+		return
+	}
+	log.Printf("Transcription error: %s", result.Error)
+	if app := application.Get(); app != nil {
+		app.Event.Emit("transcription:error", map[string]string{
+			"error":        result.Error,
+			"presetId":     presetID,
+			"missingModel": result.MissingModel,
+		})
 	}
 }
 
@@ -224,8 +619,22 @@ func (s *PresetService) GetPresets() []config.Preset {
 }
 
 // CreatePreset adds a new preset, saves config, and registers hotkey if enabled.
-func (s *PresetService) CreatePreset(p config.Preset) config.Preset {
+// Fails if the preset limit is reached or the hotkey collides with another enabled preset.
+func (s *PresetService) CreatePreset(p config.Preset) (config.Preset, error) {
+	if !validColor(p.Color) {
+		return config.Preset{}, fmt.Errorf("invalid color %q: expected #rgb or #rrggbb", p.Color)
+	}
+	if err := s.checkHotkeyConflict(p); err != nil {
+		return config.Preset{}, err
+	}
+	warnIneffectiveTranslate(p)
+	limit := maxPresets()
+
 	s.mu.Lock()
+	if len(s.cfg.Presets) >= limit {
+		s.mu.Unlock()
+		return config.Preset{}, fmt.Errorf("preset limit reached (%d): delete unused presets or raise maxPresets in settings", limit)
+	}
 	p.ID = uuid.New().String()
 	if p.InputMode == "" {
 		p.InputMode = "hold"
@@ -239,6 +648,7 @@ func (s *PresetService) CreatePreset(p config.Preset) config.Preset {
 		log.Printf("config save failed: %v", err)
 	}
 	s.mu.Unlock() // release before activatePreset (must be called without lock)
+	s.presetsChanged()
 
 	if p.Enabled {
 		go func() {
@@ -250,11 +660,42 @@ func (s *PresetService) CreatePreset(p config.Preset) config.Preset {
 			s.activatePreset(&p)
 		}()
 	}
-	return p
+	return p, nil
+}
+
+// DuplicatePreset adds a copy of preset id named "<name> (copy)". The copy has
+// no hotkey and is disabled, so it neither conflicts with the original's
+// binding nor loads a model until the user enables it.
+func (s *PresetService) DuplicatePreset(id string) (config.Preset, error) {
+	s.mu.Lock()
+	p := s.findPresetByID(id)
+	if p == nil {
+		s.mu.Unlock()
+		return config.Preset{}, fmt.Errorf("preset not found: %s", id)
+	}
+	dup := *p // copy
+	s.mu.Unlock()
+
+	// The slice and map would otherwise be shared with the original.
+	dup.AppMatch = slices.Clone(dup.AppMatch)
+	dup.CommandMap = maps.Clone(dup.CommandMap)
+	dup.Name += " (copy)"
+	dup.Hotkey = ""
+	dup.Enabled = false
+	return s.CreatePreset(dup)
 }
 
 // UpdatePreset updates a preset and re-registers hotkeys/models only when needed.
+// Fails if the hotkey collides with another enabled preset.
 func (s *PresetService) UpdatePreset(p config.Preset) error {
+	if !validColor(p.Color) {
+		return fmt.Errorf("invalid color %q: expected #rgb or #rrggbb", p.Color)
+	}
+	if err := s.checkHotkeyConflict(p); err != nil {
+		return err
+	}
+	warnIneffectiveTranslate(p)
+
 	s.mu.Lock()
 	idx := s.findPresetIndex(p.ID)
 	if idx < 0 {
@@ -268,12 +709,10 @@ func (s *PresetService) UpdatePreset(p config.Preset) error {
 		log.Printf("config save failed: %v", err)
 	}
 	s.mu.Unlock()
+	s.presetsChanged()
 
 	// Only re-register if hotkey-related or model-related fields changed
-	hotkeyChanged := old.Hotkey != p.Hotkey || old.InputMode != p.InputMode || old.Enabled != p.Enabled
-	modelChanged := old.ModelName != p.ModelName || old.KeepModelLoaded != p.KeepModelLoaded
-
-	if hotkeyChanged || modelChanged {
+	if needsReactivation(old, p) {
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
@@ -292,6 +731,16 @@ func (s *PresetService) UpdatePreset(p config.Preset) error {
 	return nil
 }
 
+// needsReactivation reports whether replacing preset old by p changes its
+// hotkey registration or model preloading, so it must be deactivated and
+// activated again.
+func needsReactivation(old, p config.Preset) bool {
+	hotkeyChanged := old.Hotkey != p.Hotkey || old.InputMode != p.InputMode || old.Enabled != p.Enabled ||
+		old.DoublePressCancel != p.DoublePressCancel
+	modelChanged := old.ModelName != p.ModelName || old.KeepModelLoaded != p.KeepModelLoaded
+	return hotkeyChanged || modelChanged
+}
+
 // DeletePreset removes a preset.
 func (s *PresetService) DeletePreset(id string) error {
 	s.mu.Lock()
@@ -307,6 +756,7 @@ func (s *PresetService) DeletePreset(id string) error {
 		log.Printf("config save failed: %v", err)
 	}
 	s.mu.Unlock()
+	s.presetsChanged()
 
 	go func() {
 		defer func() {
@@ -321,6 +771,19 @@ func (s *PresetService) DeletePreset(id string) error {
 
 // SetPresetEnabled enables or disables a preset (hotkey + model preloading).
 func (s *PresetService) SetPresetEnabled(id string, enabled bool) error {
+	if enabled {
+		s.mu.Lock()
+		var candidate config.Preset
+		if found := s.findPresetByID(id); found != nil {
+			candidate = *found
+			candidate.Enabled = true
+		}
+		s.mu.Unlock()
+		if err := s.checkHotkeyConflict(candidate); err != nil {
+			return err
+		}
+	}
+
 	s.mu.Lock()
 	idx := s.findPresetIndex(id)
 	if idx < 0 {
@@ -334,6 +797,7 @@ func (s *PresetService) SetPresetEnabled(id string, enabled bool) error {
 		log.Printf("config save failed: %v", err)
 	}
 	s.mu.Unlock()
+	s.presetsChanged()
 
 	// Run in background — hotkey.Register and model loading can block for seconds
 	go func() {
@@ -354,6 +818,7 @@ func (s *PresetService) SetPresetEnabled(id string, enabled bool) error {
 
 // ReorderPresets reorders presets to match the given ID order.
 func (s *PresetService) ReorderPresets(ids []string) error {
+	defer s.presetsChanged() // deferred first, so it runs after the unlock
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -379,20 +844,27 @@ func (s *PresetService) ReorderPresets(ids []string) error {
 	return config.Save(s.cfg)
 }
 
+// errBusy is returned by StartRecording while the transcription queue is full
+// (maxPendingTranscriptions).
+var errBusy = errors.New("too many recordings are still transcribing")
+
 // StartRecording begins audio capture for a preset.
 func (s *PresetService) StartRecording(presetID string) error {
 	s.mu.Lock()
 
-	// Check if any preset is already recording or processing.
-	// This also prevents re-entering recording for the same preset while it
-	// is still active, avoiding state corruption when StopRecording from a
-	// concurrent goroutine sets state back to "idle" mid-transcription.
+	// Only one preset records at a time. Earlier recordings still being
+	// transcribed don't block a new one (they wait in the transcription
+	// queue), unless the queue is full.
 	for _, st := range s.states {
-		if st == "recording" || st == "processing" {
+		if st == "recording" {
 			s.mu.Unlock()
 			return fmt.Errorf("a preset is already active")
 		}
 	}
+	if s.pendingTranscriptions() >= maxPendingTranscriptions {
+		s.mu.Unlock()
+		return errBusy
+	}
 
 	p := s.findPresetByID(presetID)
 	if p == nil {
@@ -409,8 +881,28 @@ func (s *PresetService) StartRecording(presetID string) error {
 	// even if audio.Start() takes time to open the device.
 	s.states[presetID] = "recording"
 	s.recordingID = presetID
+	preset := *p // copy
 	s.mu.Unlock()
 
+	// Tap presets stop by themselves after a pause in speech.
+	var silence *silenceDetector
+	if preset.InputMode == "tap" {
+		silence = &silenceDetector{window: tapSilenceWindow(preset)}
+	}
+
+	// Live input level for the overlay/main window VU meter.
+	s.audio.SetOnLevel(func(level float32) {
+		if app := application.Get(); app != nil {
+			app.Event.Emit("audio:level", map[string]any{"presetId": presetID, "level": level})
+		}
+		if silence != nil && silence.update(level, time.Now()) {
+			go s.tapAutoStop(presetID)
+		}
+	})
+
+	// Let the start cue finish first so it doesn't end up in the recording.
+	<-playCue(cueStart)
+
 	// Start audio outside lock — can block on device open
 	if err := s.audio.Start(); err != nil {
 		s.mu.Lock()
@@ -420,87 +912,511 @@ func (s *PresetService) StartRecording(presetID string) error {
 		return err
 	}
 
-	showOverlay("recording")
+	showOverlay("recording", preset, presetLanguage(preset))
+
+	limit := maxRecordDuration()
+	if preset.InputMode == "tap" {
+		limit = tapRecordLimit(preset, limit)
+	}
+	if limit == 0 || limit > recordMemoryWarnAfter {
+		log.Printf("Recording limit for preset %s is %s — PCM buffer grows ~64KB/s (~%dMB after %v)",
+			presetID, limitString(limit), pcmBytes(recordMemoryWarnAfter)>>20, recordMemoryWarnAfter)
+	}
+
+	// Auto-stop after the configured limit; unlimited recordings arm no timer.
+	if limit > 0 {
+		s.mu.Lock()
+		s.recordTimer = time.AfterFunc(limit, func() { s.autoStop(presetID, limit) })
+		s.mu.Unlock()
+	}
+
+	return nil
+}
 
-	// Auto-stop after maxRecordDuration
+// autoStop runs when a recording hits its duration limit. Normally it stops
+// and transcribes; for hold presets with RearmHold whose key is still held it
+// cuts a segment instead, keeps the device running and re-arms the timer, so
+// the user doesn't lose what they say after the limit.
+func (s *PresetService) autoStop(presetID string, limit time.Duration) {
 	s.mu.Lock()
-	s.recordTimer = time.AfterFunc(maxRecordDuration, func() {
-		log.Printf("Auto-stopping recording for preset %s (max %v reached)", presetID, maxRecordDuration)
+	p := s.findPresetByID(presetID)
+	rearm := p != nil && p.InputMode == "hold" && p.RearmHold != "" &&
+		s.states[presetID] == "recording" && s.hotkeys != nil && s.hotkeys.IsPressed(presetID)
+	if !rearm {
+		s.mu.Unlock()
+		log.Printf("Auto-stopping recording for preset %s (max %v reached)", presetID, limit)
 		if _, err := s.StopRecording(presetID); err != nil {
 			log.Printf("Auto-stop failed: %v", err)
 		}
-	})
-	s.mu.Unlock()
+		return
+	}
 
-	return nil
+	preset := *p // copy
+	segment := s.audio.Drain()
+	// SRT timings only line up within one transcription, so subtitles always join.
+	if preset.RearmHold == "join" || preset.OutputFormat == "srt" {
+		s.heldAudio = append(s.heldAudio, segment...)
+	} else {
+		slot := new(string)
+		s.heldTexts = append(s.heldTexts, slot)
+		if s.heldSegments == nil {
+			s.heldSegments = new(sync.WaitGroup)
+		}
+		s.heldSegments.Add(1)
+		go s.transcribeHeldSegment(preset, segment, slot, s.heldSegments)
+	}
+	s.recordTimer = time.AfterFunc(limit, func() { s.autoStop(presetID, limit) })
+	s.mu.Unlock()
+	log.Printf("Recording limit reached for preset %s with key held — continuing (%s, %.1fs segment)",
+		presetID, preset.RearmHold, float64(len(segment))/sampleRate)
 }
 
-// StopRecording stops capture and returns transcribed text.
-func (s *PresetService) StopRecording(presetID string) (TranscriptionResult, error) {
-	s.mu.Lock()
-	if s.states[presetID] != "recording" {
-		s.mu.Unlock()
-		return TranscriptionResult{}, nil
+// transcribeHeldSegment transcribes a "split" segment cut by autoStop and
+// stores its text in slot for StopRecording to join.
+func (s *PresetService) transcribeHeldSegment(preset config.Preset, samples []float32, slot *string, segments *sync.WaitGroup) {
+	defer segments.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("recovered panic in transcribeHeldSegment: %v", r)
+		}
+	}()
+	res, err := s.transcribeBuffer(preset, presetLanguage(preset), samples, nil, nil, nil)
+	if err != nil || res.Error != "" {
+		log.Printf("Segment transcription failed: %v %s", err, res.Error)
+		return
 	}
+	s.mu.Lock()
+	*slot = res.Text
+	s.mu.Unlock()
+}
 
-	// Cancel auto-stop timer
-	if s.recordTimer != nil {
-		s.recordTimer.Stop()
-		s.recordTimer = nil
+// takeHeld returns and clears the re-armed segments of the current recording;
+// segments is nil unless texts has some. Must be called with s.mu held.
+func (s *PresetService) takeHeld() (audio []float32, texts []*string, segments *sync.WaitGroup) {
+	audio, texts, segments = s.heldAudio, s.heldTexts, s.heldSegments
+	s.heldAudio, s.heldTexts, s.heldSegments = nil, nil, nil
+	return audio, texts, segments
+}
+
+// joinSegmentTexts joins the texts of consecutive segments of one recording,
+// skipping empty ones, and applies the preset's leading-space rule once.
+func joinSegmentTexts(parts []string, preserveLeading bool) string {
+	var nonEmpty []string
+	lead := ""
+	for _, part := range parts {
+		t := strings.TrimSpace(part)
+		if t == "" {
+			continue
+		}
+		if len(nonEmpty) == 0 && strings.HasPrefix(part, " ") {
+			lead = " "
+		}
+		nonEmpty = append(nonEmpty, t)
 	}
+	return normalizeSpacing(lead+strings.Join(nonEmpty, " "), preserveLeading)
+}
 
-	samples := s.audio.Stop()
-	s.states[presetID] = "processing"
-	s.recordingID = ""
+// TranscribeBuffer transcribes 16 kHz mono float32 samples with a preset's model
+// and language settings and returns the cleaned text. It has no output side
+// effects: nothing is pasted, saved to history or shown in the overlay.
+func (s *PresetService) TranscribeBuffer(presetID string, samples []float32) (TranscriptionResult, error) {
+	s.mu.Lock()
 	p := s.findPresetByID(presetID)
 	if p == nil {
-		s.states[presetID] = "idle"
 		s.mu.Unlock()
-		hideOverlay()
-		return TranscriptionResult{}, fmt.Errorf("preset not found")
+		return TranscriptionResult{}, fmt.Errorf("preset not found: %s", presetID)
 	}
 	preset := *p // copy
 	s.mu.Unlock()
 
-	showOverlay("processing")
-
-	// Minimum recording duration: 0.5s at 16kHz = 8000 samples.
-	// Short accidental presses produce silence that whisper hallucinates on.
-	const minSamples = 8000
-	if len(samples) < minSamples {
-		log.Printf("Recording too short (%d samples, need %d), discarding", len(samples), minSamples)
-		s.mu.Lock()
-		s.states[presetID] = "idle"
-		s.mu.Unlock()
-		hideOverlay()
-		return TranscriptionResult{}, nil
-	}
+	res, err := s.transcribeBuffer(preset, presetLanguage(preset), samples, nil, nil, nil)
 
-	durationSec := len(samples) / 16000
-	log.Printf("Recording stopped: %d samples (%.1fs)", len(samples), float64(len(samples))/16000)
-
-	engine, err := s.getOrLoadEngine(&preset)
+	// Same engine lifetime as a recording, unless a recording is using it right now.
+	s.mu.Lock()
+	if !preset.KeepModelLoaded && s.states[presetID] != "recording" && s.pending[presetID] == 0 {
+		if e, ok := s.engines[presetID]; ok {
+			e.Close()
+			delete(s.engines, presetID)
+		}
+	}
+	s.mu.Unlock()
+	return res, err
+}
+
+// errNoAudio is the TranscriptionResult error for a silent recording.
+const errNoAudio = "No audio detected — check your microphone"
+
+// transcribeBuffer loads the preset's engine and runs transcribeSamples.
+// Must be called WITHOUT s.mu held (model loading can take seconds).
+func (s *PresetService) transcribeBuffer(preset config.Preset, lang string, samples []float32, onProgress func(current, total int), onPartial func(current, total int, text string), trace traceLog) (TranscriptionResult, error) {
+	if need := minRecordSamples(); len(samples) < need {
+		log.Printf("Recording too short (%d samples, need %d), discarding", len(samples), need)
+		return TranscriptionResult{TooShort: true}, nil
+	}
+	// Whisper hallucinates on digital silence and the filter drops it, so
+	// without this check a muted mic would look like nothing happened.
+	if peak := peakLevel(samples); peak < silentPeak {
+		log.Printf("Recording is silent (peak %.4f), skipping transcription", peak)
+		return TranscriptionResult{Error: errNoAudio}, nil
+	}
+	// Gate first: the threshold is meant for the level the microphone delivers.
+	samples = applyNoiseGate(samples, preset.NoiseGateDb)
+	if preset.NormalizeAudio {
+		samples = normalizePCM(samples, normalizeTargetPeak)
+	}
+	engine, err := s.getOrLoadEngine(&preset)
+	downgraded := false
+	if isOOMError(err) {
+		engine, err = s.loadDowngraded(&preset, err)
+		downgraded = err == nil
+	}
 	if err != nil {
-		s.mu.Lock()
-		s.states[presetID] = "idle"
-		s.mu.Unlock()
-		hideOverlay()
+		var missing *ModelNotDownloadedError
+		if errors.As(err, &missing) {
+			return TranscriptionResult{Error: "Model not downloaded: " + missing.Model, MissingModel: missing.Model}, nil
+		}
 		return TranscriptionResult{Error: "Model load failed: " + err.Error()}, nil
 	}
+	engine.SetThreads(inferenceThreadSetting())
+	start := time.Now()
+	res := transcribeSamples(engine, preset, lang, samples, onProgress, onPartial, trace)
+	res.metrics = &transcriptionMetrics{
+		model:  preset.ModelName,
+		infer:  time.Since(start),
+		chunks: transcriptionChunks(preset, len(samples)),
+	}
+	if trace != nil && lang == "auto" {
+		trace.printf("detected language: %s", engine.DetectedLanguage())
+	}
+	if downgraded {
+		s.unloadEngine(preset.ID, engine)
+	}
+	return res, nil
+}
+
+// loadDowngraded retries a model load that failed with loadErr (out of memory)
+// once with the largest downloaded smaller variant of the same family, e.g.
+// large-v3 → large-v3-q5_0. On success p.ModelName is switched for this
+// transcription only (the saved preset is unchanged; the caller unloads the
+// engine afterwards, so it isn't reused from the cache) and model:downgraded
+// {presetId, from, to} is emitted. Without a downloaded variant loadErr is
+// returned as is.
+func (s *PresetService) loadDowngraded(p *config.Preset, loadErr error) (*WhisperEngine, error) {
+	if s.models == nil {
+		return nil, loadErr
+	}
+	dir := s.models.ResolveModelsDir()
+	for _, name := range smallerVariants(p.ModelName) {
+		if _, err := findModelFile(dir, name); err != nil {
+			continue
+		}
+		from := p.ModelName
+		log.Printf("Model %s failed to load (%v), retrying with %s", from, loadErr, name)
+		p.ModelName = name
+		engine, err := s.getOrLoadEngine(p)
+		if err != nil {
+			p.ModelName = from
+			return nil, err
+		}
+		if app := application.Get(); app != nil {
+			app.Event.Emit("model:downgraded", map[string]any{
+				"presetId": p.ID,
+				"from":     from,
+				"to":       name,
+			})
+		}
+		return engine, nil
+	}
+	return nil, loadErr
+}
+
+// unloadEngine closes engine and removes it from the cache if it is still the
+// one cached for presetID (otherwise whoever replaced it has closed it).
+func (s *PresetService) unloadEngine(presetID string, engine *WhisperEngine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.engines[presetID] == engine {
+		engine.Close()
+		delete(s.engines, presetID)
+		delete(s.engineUsed, presetID)
+	}
+}
+
+// transcriber is the part of WhisperEngine used for dictation (faked in tests).
+type transcriber interface {
+	Transcribe(samples []float32, lang string, opts DecodeOptions) (string, error)
+	TranscribeLong(samples []float32, lang string, opts DecodeOptions, onProgress func(current, total int), onPartial func(current, total int, text string)) (string, error)
+	TranscribeSegmentsLong(samples []float32, lang string, opts DecodeOptions, onProgress func(current, total int)) ([]Segment, error)
+}
+
+// transcribeSamples is the pure transcription core shared by recordings and
+// TranscribeBuffer: minimum-length check, inference, noise-marker cleanup,
+// spacing rules, hallucination filter and output formatting. trace, if set,
+// gets the raw whisper output and each post-processing step.
+func transcribeSamples(eng transcriber, preset config.Preset, lang string, samples []float32, onProgress func(current, total int), onPartial func(current, total int, text string), trace traceLog) TranscriptionResult {
+	// whisper.cpp refuses input this short; callers enforce MinRecordMs.
+	if len(samples) < minWhisperMs*sampleRate/1000 {
+		return TranscriptionResult{}
+	}
+
+	if preset.LowLatency {
+		return transcribeFast(eng, preset, lang, samples, trace)
+	}
 
+	// SRT output needs segment timings; plain text uses the cheaper path.
+	opts := presetDecodeOptions(preset)
+	var text string
+	var segments []Segment
+	var err error
+	if preset.OutputFormat == "srt" {
+		segments, err = eng.TranscribeSegmentsLong(samples, lang, opts, onProgress)
+		text = segmentsText(segments)
+	} else {
+		text, err = eng.TranscribeLong(samples, lang, opts, onProgress, onPartial)
+	}
+	if err != nil {
+		trace.printf("whisper failed: %v", err)
+		return TranscriptionResult{Error: "Transcription failed: " + err.Error()}
+	}
+	trace.printf("whisper output: %q", text)
+
+	// Translated output is English whatever was spoken.
+	textLang := lang
+	if opts.Translate {
+		textLang = "en"
+	}
+	markers := opts.noiseMarkers()
+	result := postProcess(preset, textLang, text, markers, trace)
+	if result == "" {
+		return TranscriptionResult{NoiseOnly: isNoiseOnly(text, markers)}
+	}
+	if segments != nil {
+		segments = postProcessSegments(preset, textLang, segments, markers, trace)
+		if len(segments) == 0 {
+			return TranscriptionResult{}
+		}
+		result = formatSRT(segments)
+		trace.printf("formatted as SRT: %d segments", len(segments))
+	}
+	return TranscriptionResult{Text: result}
+}
+
+// postProcessSegments runs each SRT segment's text through postProcess, so
+// subtitles get the same cleanup, filters and formatting as plain text.
+// Segments left empty (noise, hallucinations) are dropped.
+func postProcessSegments(preset config.Preset, lang string, segs []Segment, markers *regexp.Regexp, trace traceLog) []Segment {
+	var out []Segment
+	for _, seg := range segs {
+		seg.Text = strings.TrimSpace(postProcess(preset, lang, seg.Text, markers, trace))
+		if seg.Text != "" {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+// transcriptionChunks returns how many whisper passes transcribeSamples makes
+// over n samples: one for a LowLatency preset, else one per chunk.
+func transcriptionChunks(preset config.Preset, n int) int {
+	if preset.LowLatency {
+		return 1
+	}
+	return len(chunkBounds(n))
+}
+
+// transcribeFast is transcribeSamples for a LowLatency preset: a single
+// greedy whisper pass over the whole recording (no chunking, so no progress
+// events, and no SRT), then only noise-marker removal and trimming. The
+// hallucination filter and Capitalize / EndPunctuation are skipped.
+func transcribeFast(eng transcriber, preset config.Preset, lang string, samples []float32, trace traceLog) TranscriptionResult {
+	opts := presetDecodeOptions(preset)
+	text, err := eng.Transcribe(samples, lang, opts)
+	if err != nil {
+		trace.printf("whisper failed: %v", err)
+		return TranscriptionResult{Error: "Transcription failed: " + err.Error()}
+	}
+	trace.printf("whisper output (low latency): %q", text)
+
+	markers := opts.noiseMarkers()
+	result := cleanWhisperOutput(text, markers)
+	if result == "" {
+		return TranscriptionResult{NoiseOnly: isNoiseOnly(text, markers)}
+	}
+	return TranscriptionResult{Text: result}
+}
+
+// postProcess runs raw whisper output through the preset's text pipeline, in
+// order: noise-marker cleanup (markers), spacing rules, hallucination filter (phrases of
+// lang, every language's for "auto"), ConvertNumbers, Capitalize /
+// EndPunctuation. It returns "" when nothing should be pasted. Case matching against the text before the
+// caret (AutoCapitalize) needs the target app and happens at paste time.
+func postProcess(preset config.Preset, lang, text string, markers *regexp.Regexp, trace traceLog) string {
+	result := stripNoiseMarkers(text, markers)
+	trace.printf("noise markers stripped: %q", result)
+	result = normalizeSpacing(result, preset.PreserveLeadingSpace)
+	trace.printf("spacing normalized: %q", result)
+
+	// Filter out whisper hallucinations on silence/short audio
+	if !preset.DisableHallucinationFilter && loadHallucinationFilter().match(result, lang) {
+		log.Printf("Filtered hallucination: %q", result)
+		return ""
+	}
+	if preset.ConvertNumbers {
+		result = wordsToNumbers(result, lang)
+		trace.printf("numbers converted: %q", result)
+	}
+	if f := presetTextFormat(preset); f != (textFormat{}) {
+		result = applyTextFormatting(result, f)
+		trace.printf("formatted: %q", result)
+	}
+	return result
+}
+
+// PreviewPostProcess shows what a preset's post-processing does to sample text,
+// without recording: the postProcess pipeline, then AutoCapitalize as if the
+// caret were at the start of an empty field, then the paste affixes. Nothing is
+// pasted or saved.
+func (s *PresetService) PreviewPostProcess(presetID, sample string) (string, error) {
+	s.mu.Lock()
+	p := s.findPresetByID(presetID)
+	if p == nil {
+		s.mu.Unlock()
+		return "", fmt.Errorf("preset not found: %s", presetID)
+	}
+	preset := *p // copy
+	s.mu.Unlock()
+
+	result := postProcess(preset, presetLanguage(preset), sample, presetDecodeOptions(preset).noiseMarkers(), nil)
+	if result == "" {
+		return "", nil
+	}
+	if preset.AutoCapitalize {
+		result = contextCapitalize(result, "")
+	}
+	return applyAffixes(result, preset), nil
+}
+
+// presetDecodeOptions returns the whisper decoding options for a preset, with
+// the stripNoiseMarkers global setting read once for the transcription.
+// A LowLatency preset always decodes greedily with whisper's default
+// fallback. Translate is dropped for English-only models, which can't
+// translate.
+func presetDecodeOptions(preset config.Preset) DecodeOptions {
+	translate := preset.Translate && !isEnglishOnlyModel(preset.ModelName)
+	keepUnknown := !stripNoiseMarkersSetting()
+	if preset.LowLatency {
+		return DecodeOptions{Translate: translate, KeepUnknownMarkers: keepUnknown}
+	}
+	return DecodeOptions{
+		Translate:          translate,
+		RobustDecode:       preset.RobustDecode,
+		BeamSize:           preset.BeamSize,
+		EntropyThreshold:   preset.EntropyThreshold,
+		LogProbThreshold:   preset.LogProbThreshold,
+		KeepUnknownMarkers: keepUnknown,
+		UseContext:         preset.UseContext,
+	}
+}
+
+// presetLanguage resolves the transcription language for a preset, following
+// the keyboard layout when UseKBLayout is set.
+func presetLanguage(preset config.Preset) string {
 	lang := preset.Language
-	translate := false
 	if lang == "" {
 		lang = "auto"
 	}
-
-	// Override language with keyboard layout if enabled
 	if preset.UseKBLayout {
 		if detected := detectKeyboardLanguage(); detected != "" {
 			log.Printf("KB layout detected language: %s", detected)
 			lang = detected
 		}
 	}
+	return lang
+}
+
+// SetVerboseNext makes the next StopRecording log every step — audio levels,
+// detected language, raw whisper output, each post-processing stage and the
+// paste — for debugging one bad transcription without noisy logs otherwise.
+// The flag resets after that recording.
+func (s *PresetService) SetVerboseNext(on bool) {
+	s.mu.Lock()
+	s.verboseNext = on
+	s.mu.Unlock()
+}
+
+// traceLog receives the detailed steps of a SetVerboseNext recording; a nil
+// traceLog discards them.
+type traceLog func(format string, args ...any)
+
+func (t traceLog) printf(format string, args ...any) {
+	if t != nil {
+		t(format, args...)
+	}
+}
+
+// verboseTrace writes SetVerboseNext steps to the regular log.
+func verboseTrace(format string, args ...any) {
+	log.Printf("[verbose] "+format, args...)
+}
+
+// StopRecording stops capture and returns transcribed text. The recording slot
+// is freed at once, so the next recording can start while this one waits in
+// the transcription queue behind earlier ones (enqueueTranscription).
+func (s *PresetService) StopRecording(presetID string) (TranscriptionResult, error) {
+	retryLimit := maxRecordDuration()
+
+	s.mu.Lock()
+	if s.states[presetID] != "recording" {
+		s.mu.Unlock()
+		return TranscriptionResult{}, nil
+	}
+
+	// Cancel auto-stop timer
+	if s.recordTimer != nil {
+		s.recordTimer.Stop()
+		s.recordTimer = nil
+	}
+
+	samples := s.audio.Stop()
+	heldAudio, heldTexts, heldSegments := s.takeHeld()
+	if len(heldAudio) > 0 {
+		samples = append(heldAudio, samples...)
+	}
+	s.lastSamples = retrySamples(samples, retryLimit)
+	s.states[presetID] = "idle"
+	s.recordingID = ""
+	var trace traceLog
+	if s.verboseNext {
+		s.verboseNext = false
+		trace = verboseTrace
+	}
+	p := s.findPresetByID(presetID)
+	if p == nil {
+		s.mu.Unlock()
+		hideOverlay()
+		return TranscriptionResult{}, fmt.Errorf("preset not found")
+	}
+	preset := *p // copy
+	s.mu.Unlock()
+
+	lang := presetLanguage(preset)
+	showOverlay("processing", preset, lang)
+	playCue(cueStop)
+	log.Printf("Recording stopped: %d samples (%.1fs)", len(samples), float64(len(samples))/sampleRate)
+
+	return s.transcribeQueued(presetID, func() (TranscriptionResult, error) {
+		return s.transcribeRecording(presetID, preset, lang, samples, heldTexts, heldSegments, trace)
+	})
+}
+
+// transcribeRecording is the transcription job of a stopped recording: it
+// transcribes samples, then pastes the text or runs its voice command.
+// heldTexts and heldSegments are the "split" segments cut while the key was
+// held (takeHeld).
+func (s *PresetService) transcribeRecording(presetID string, preset config.Preset, lang string, samples []float32, heldTexts []*string, heldSegments *sync.WaitGroup, trace traceLog) (TranscriptionResult, error) {
+	s.showOverlayUnlessRecording("processing", preset, lang)
+	durationSec := len(samples) / sampleRate
+	trace.printf("preset %q, language %s, %d samples, peak %.3f, RMS %.4f", preset.Name, lang, len(samples), peakLevel(samples), rmsLevel(samples))
 
 	// Emit transcription progress events for long recordings (>25s)
 	onProgress := func(current, total int) {
@@ -517,53 +1433,280 @@ func (s *PresetService) StopRecording(presetID string) (TranscriptionResult, err
 		}
 	}
 
-	text, err := engine.TranscribeLong(samples, lang, translate, onProgress)
-	if err != nil {
+	// Long recordings show their text chunk by chunk (transcription:partial);
+	// the paste still waits for the whole result.
+	onPartial := func(current, total int, text string) {
+		if app := application.Get(); app != nil {
+			app.Event.Emit("transcription:partial", map[string]any{
+				"presetId": presetID,
+				"chunk":    current,
+				"total":    total,
+				"text":     text,
+			})
+		}
+	}
+
+	res, err := s.transcribeBuffer(preset, lang, samples, onProgress, onPartial, trace)
+	// A silent last segment of a "split" recording just adds nothing.
+	if res.Error == errNoAudio && len(heldTexts) > 0 {
+		res = TranscriptionResult{}
+	}
+	if err != nil || res.Error != "" {
+		s.hideOverlayUnlessRecording()
+		return res, err
+	}
+	result := res.Text
+
+	// "split" re-armed recording: prepend the segments transcribed while the
+	// key was still held.
+	if len(heldTexts) > 0 {
+		heldSegments.Wait()
 		s.mu.Lock()
-		s.states[presetID] = "idle"
+		parts := make([]string, 0, len(heldTexts)+1)
+		for _, t := range heldTexts {
+			parts = append(parts, *t)
+		}
 		s.mu.Unlock()
-		hideOverlay()
-		return TranscriptionResult{Error: "Transcription failed: " + err.Error()}, nil
+		result = joinSegmentTexts(append(parts, result), preset.PreserveLeadingSpace)
+		trace.printf("joined with %d held segments: %q", len(heldTexts), result)
+	}
+	if result != "" {
+		result = applyPostCommand(result, trace)
+	}
+	if result == "" {
+		playCue(cueDiscard)
+		if res.TooShort {
+			if app := application.Get(); app != nil {
+				app.Event.Emit("transcription:discarded", map[string]any{
+					"presetId":   presetID,
+					"reason":     "too-short",
+					"durationMs": len(samples) * 1000 / sampleRate,
+					"minMs":      minRecordSamples() * 1000 / sampleRate,
+				})
+			}
+		}
+		if res.NoiseOnly {
+			log.Printf("Only background noise detected for preset %q", preset.Name)
+			if app := application.Get(); app != nil {
+				app.Event.Emit("transcription:noise-only", map[string]any{"presetId": presetID})
+			}
+		}
 	}
 
-	result := strings.TrimSpace(text)
-
-	// Filter out whisper hallucinations on silence/short audio
-	if isHallucination(result) {
-		log.Printf("Filtered hallucination: %q", result)
-		result = ""
+	// A newer recording may have started while this one was transcribing:
+	// pasting or running a command now would land in the middle of it.
+	if result != "" {
+		s.waitRecordingIdle()
 	}
 
 	// Hide overlay BEFORE pasting so the target app has focus.
-	hideOverlay()
+	s.hideOverlayUnlessRecording()
 	time.Sleep(100 * time.Millisecond) // let OS process focus change
 
-	if result != "" {
-		// Paste into active text field
-		if err := pasteText(result); err != nil {
-			log.Printf("Paste failed: %v", err)
+	// Command mode: a transcription matching a CommandMap phrase runs its action
+	// instead of being pasted; anything else falls through to normal paste.
+	command, isCommand := "", false
+	if result != "" && preset.CommandMode {
+		command, isCommand = matchCommand(result, preset.CommandMap)
+	}
+
+	trace.printf("command mode %v, matched command %q", preset.CommandMode, command)
+	if isCommand {
+		log.Printf("Voice command %q: %s", result, command)
+		if err := runCommand(command); err != nil {
+			log.Printf("Voice command failed: %v", err)
 		}
+	} else if result != "" {
+		result = s.pasteResult(preset, lang, result, trace)
+	}
+
+	s.finishTranscription(presetID, preset, result)
+	s.emitMetrics(presetID, len(samples), res.metrics)
+	return TranscriptionResult{Text: result, NoiseOnly: result == "" && res.NoiseOnly}, nil
+}
+
+// emitMetrics logs the speed of a recording's transcription and emits
+// transcription:metrics {presetId, audioMs, inferMs, backend, model, chunks},
+// so users can see e.g. that 5s of audio took 11s on the CPU. Nothing is sent
+// when whisper didn't run (m nil).
+func (s *PresetService) emitMetrics(presetID string, samples int, m *transcriptionMetrics) {
+	if m == nil {
+		return
+	}
+	s.mu.Lock()
+	backend := s.cfg.Backend
+	s.mu.Unlock()
+	if backend == "" {
+		backend = "auto"
+	}
+	audioMs := samples * 1000 / sampleRate
+	inferMs := m.infer.Milliseconds()
+	log.Printf("Transcribed %dms of audio in %dms (%d chunks, backend %s, model %s)", audioMs, inferMs, m.chunks, backend, m.model)
+	if app := application.Get(); app != nil {
+		app.Event.Emit("transcription:metrics", map[string]any{
+			"presetId": presetID,
+			"audioMs":  audioMs,
+			"inferMs":  inferMs,
+			"backend":  backend,
+			"model":    m.model,
+			"chunks":   m.chunks,
+		})
+	}
+}
+
+// pasteResult pastes a transcription into the focused app and records it in
+// history, and returns the text as pasted (case-matched, without affixes).
+func (s *PresetService) pasteResult(preset config.Preset, lang, result string, trace traceLog) string {
+	// Match the case of the first word to the text already before the caret.
+	// A LowLatency preset pastes at once instead of reading the caret first.
+	if preset.AutoCapitalize && !preset.LowLatency {
+		result = capitalizeForContext(result, caretReader, preset.Capitalize)
+		trace.printf("case matched to the caret: %q", result)
+	}
 
-		if preset.KeepHistory && s.history != nil {
-			_ = s.history.AddEntry(result, lang)
+	// Paste into active text field
+	pasted := applyAffixes(result, preset)
+	if preset.AutoSpace {
+		s.mu.Lock()
+		pasted = autoSpace(pasted, s.lastPasteEnd)
+		s.mu.Unlock()
+	}
+	if err := pasteText(pasted); err != nil {
+		log.Printf("Paste failed: %v", err)
+		trace.printf("paste of %q failed: %v", pasted, err)
+	} else {
+		trace.printf("pasted %q (output mode %q)", pasted, outputMode())
+		if r, _ := utf8.DecodeLastRuneInString(pasted); r != utf8.RuneError {
+			s.mu.Lock()
+			s.lastPasteEnd = r
+			s.mu.Unlock()
 		}
 	}
 
-	// Unload model if not keeping it loaded
+	if preset.KeepHistory && s.history != nil {
+		_ = s.history.AddEntry(result, lang)
+	}
+	return result
+}
+
+// finishTranscription unloads the model unless the preset keeps it loaded or
+// is recording or has another transcription queued, and stores result as the
+// last text. It runs inside the preset's transcription job.
+func (s *PresetService) finishTranscription(presetID string, preset config.Preset, result string) {
 	s.mu.Lock()
-	if !preset.KeepModelLoaded {
+	defer s.mu.Unlock()
+	if !preset.KeepModelLoaded && s.states[presetID] != "recording" && s.pending[presetID] <= 1 {
 		if e, ok := s.engines[presetID]; ok {
 			e.Close()
 			delete(s.engines, presetID)
 		}
 	}
-	s.states[presetID] = "idle"
 	s.lastText = result
+}
+
+// RetryLastTranscription transcribes the audio of the last recording again
+// with presetID's current settings — e.g. after fixing a wrongly detected
+// language or picking a bigger model — pastes the new text and makes it the
+// last text. It fails while any recording is in progress and otherwise runs
+// in the transcription queue like a recording.
+func (s *PresetService) RetryLastTranscription(presetID string) (TranscriptionResult, error) {
+	s.mu.Lock()
+	for _, state := range s.states {
+		if state == "recording" {
+			s.mu.Unlock()
+			return TranscriptionResult{}, fmt.Errorf("cannot retry while recording")
+		}
+	}
+	p := s.findPresetByID(presetID)
+	if p == nil {
+		s.mu.Unlock()
+		return TranscriptionResult{}, fmt.Errorf("preset not found: %s", presetID)
+	}
+	if s.pending[presetID] > 0 {
+		s.mu.Unlock()
+		return TranscriptionResult{}, fmt.Errorf("preset %q is still transcribing", p.Name)
+	}
+	if len(s.lastSamples) == 0 {
+		s.mu.Unlock()
+		return TranscriptionResult{}, fmt.Errorf("no recording to retry")
+	}
+	samples := s.lastSamples
+	preset := *p // copy
 	s.mu.Unlock()
-	return TranscriptionResult{Text: result}, nil
+
+	lang := presetLanguage(preset)
+	log.Printf("Retrying last recording (%.1fs) with preset %q", float64(len(samples))/sampleRate, preset.Name)
+	showOverlay("processing", preset, lang)
+	return s.transcribeQueued(presetID, func() (TranscriptionResult, error) {
+		return s.retryTranscription(presetID, preset, lang, samples)
+	})
 }
 
-// GetRecordingStates returns the state of all presets.
+// retryTranscription is RetryLastTranscription's transcription job.
+func (s *PresetService) retryTranscription(presetID string, preset config.Preset, lang string, samples []float32) (TranscriptionResult, error) {
+	s.showOverlayUnlessRecording("processing", preset, lang)
+	res, err := s.transcribeBuffer(preset, lang, samples, nil, nil, nil)
+	if err != nil || res.Error != "" {
+		s.hideOverlayUnlessRecording()
+		return res, err
+	}
+
+	result := res.Text
+	if result != "" {
+		s.waitRecordingIdle()
+	}
+
+	// Hide overlay BEFORE pasting so the target app has focus.
+	s.hideOverlayUnlessRecording()
+	time.Sleep(100 * time.Millisecond)
+
+	if result != "" {
+		result = s.pasteResult(preset, lang, result, nil)
+	} else {
+		playCue(cueDiscard)
+	}
+	s.finishTranscription(presetID, preset, result)
+	return TranscriptionResult{Text: result, NoiseOnly: result == "" && res.NoiseOnly}, nil
+}
+
+// retrySamples returns the samples to keep for RetryLastTranscription: all of
+// them, or nil if they are longer than limit (0 = no limit).
+func retrySamples(samples []float32, limit time.Duration) []float32 {
+	if limit > 0 && len(samples) > int(limit.Seconds()*sampleRate) {
+		return nil
+	}
+	return samples
+}
+
+// CancelRecording stops capture and discards the audio without transcribing.
+func (s *PresetService) CancelRecording(presetID string) {
+	s.mu.Lock()
+	if s.states[presetID] != "recording" {
+		s.mu.Unlock()
+		return
+	}
+	if s.recordTimer != nil {
+		s.recordTimer.Stop()
+		s.recordTimer = nil
+	}
+	samples := s.audio.Stop()
+	s.takeHeld()
+	s.states[presetID] = "idle"
+	s.recordingID = ""
+	s.mu.Unlock()
+
+	hideOverlay()
+	log.Printf("Recording cancelled for preset %s (%d samples discarded)", presetID, len(samples))
+	if app := application.Get(); app != nil {
+		app.Event.Emit("recording:cancelled", map[string]string{"presetId": presetID})
+	}
+}
+
+// GetRecordingStates returns the state of all presets: "idle", "recording",
+// "processing" while a transcription of theirs is queued or running, or
+// "queued" for a recording waiting for room in the transcription queue
+// (BusyBehavior "queue"). A preset recording again while its last recording
+// is still transcribing reports "recording".
 func (s *PresetService) GetRecordingStates() []PresetState {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -574,6 +1717,12 @@ func (s *PresetService) GetRecordingStates() []PresetState {
 		if state == "" {
 			state = "idle"
 		}
+		if state == "idle" && s.pending[p.ID] > 0 {
+			state = "processing"
+		}
+		if state == "idle" && p.ID == s.queuedID {
+			state = "queued"
+		}
 		result = append(result, PresetState{ID: p.ID, State: state})
 	}
 	return result
@@ -602,29 +1751,65 @@ func (s *PresetService) CancelCapture() {
 }
 
 // GetModelLanguages returns available languages for a specific model.
-// If the model is loaded in any engine, uses whisper_is_multilingual from the C API.
-// Otherwise falls back to checking the model name for ".en" suffix.
+// If the model is loaded in any engine, asks the engine which languages the model
+// vocabulary covers. Otherwise falls back to checking the model name for ".en" suffix.
 func (s *PresetService) GetModelLanguages(modelName string) []LanguageInfo {
-	multilingual := !isEnglishOnlyModel(modelName) // fallback by name
-
 	// Try to find a loaded engine for this model — use C API for accurate check
 	s.mu.Lock()
 	for _, p := range s.cfg.Presets {
 		if p.ModelName == modelName {
 			if eng, ok := s.engines[p.ID]; ok && eng != nil {
-				multilingual = eng.IsMultilingual()
-				break
+				langs := modelLanguages(eng, WhisperLanguages())
+				s.mu.Unlock()
+				return langs
 			}
 		}
 	}
 	s.mu.Unlock()
 
-	if !multilingual {
+	if isEnglishOnlyModel(modelName) { // fallback by name
 		return []LanguageInfo{{"en", "English"}}
 	}
 	return WhisperLanguages()
 }
 
+// languageSource reports which languages a loaded model supports.
+type languageSource interface {
+	IsMultilingual() bool
+	SupportedLanguages() []string
+}
+
+// modelLanguages filters the full whisper language list down to what src supports.
+// "auto" is kept for multilingual models; an empty supported list means no restriction.
+func modelLanguages(src languageSource, all []LanguageInfo) []LanguageInfo {
+	if !src.IsMultilingual() {
+		return []LanguageInfo{{"en", "English"}}
+	}
+	supported := src.SupportedLanguages()
+	if len(supported) == 0 {
+		return all
+	}
+	allowed := make(map[string]bool, len(supported))
+	for _, code := range supported {
+		allowed[code] = true
+	}
+	langs := make([]LanguageInfo, 0, len(supported)+1)
+	for _, l := range all {
+		if l.Code == "auto" || allowed[l.Code] {
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
+
+// warnIneffectiveTranslate logs when p asks for translation with a model that
+// can't translate; transcription then ignores the setting.
+func warnIneffectiveTranslate(p config.Preset) {
+	if p.Translate && isEnglishOnlyModel(p.ModelName) {
+		log.Printf("Preset %q: translate has no effect with the English-only model %s", p.Name, p.ModelName)
+	}
+}
+
 func isEnglishOnlyModel(name string) bool {
 	parts := strings.Split(name, "-")
 	for _, p := range parts {
@@ -667,16 +1852,29 @@ func (s *PresetService) Shutdown() {
 		s.mu.Lock()
 		defer s.mu.Unlock()
 
+		// No new recordings, then stop capture (the device is drained before
+		// its context is freed), then free the models.
+		if s.stopWatch != nil {
+			s.stopWatch()
+		}
 		if s.hotkeys != nil {
 			s.hotkeys.Stop()
 		}
-		for id, engine := range s.engines {
-			engine.Close()
-			delete(s.engines, id)
+		if s.recordTimer != nil {
+			s.recordTimer.Stop()
+			s.recordTimer = nil
 		}
+		s.queuedID = ""
+		s.shutDown = true
+		s.dropTranscriptions() // a running one finishes
 		if s.audio != nil {
 			s.audio.Close()
 		}
+		for id, engine := range s.engines {
+			engine.Close()
+			delete(s.engines, id)
+		}
+		s.lastSamples = nil
 	})
 }
 
@@ -686,8 +1884,11 @@ const modelInitTimeout = 60 * time.Second
 // getOrLoadEngine returns a cached engine or loads a new one.
 // Prevents concurrent loads for the same preset and has a timeout for model init.
 func (s *PresetService) getOrLoadEngine(p *config.Preset) (*WhisperEngine, error) {
+	limit := maxLoadedEngines()
+
 	s.mu.Lock()
 	if engine, ok := s.engines[p.ID]; ok {
+		s.engineUsed[p.ID] = time.Now()
 		s.mu.Unlock()
 		log.Printf("Using cached model for preset %q", p.Name)
 		return engine, nil
@@ -698,6 +1899,7 @@ func (s *PresetService) getOrLoadEngine(p *config.Preset) (*WhisperEngine, error
 		return nil, fmt.Errorf("model is already loading for preset %q", p.Name)
 	}
 	s.engineLoading[p.ID] = true
+	s.evictEngines(limit)
 	s.mu.Unlock()
 
 	defer func() {
@@ -716,6 +1918,11 @@ func (s *PresetService) getOrLoadEngine(p *config.Preset) (*WhisperEngine, error
 		backend = "auto"
 	}
 
+	gpuDevice := s.cfg.GPUDeviceIndex
+	if gpuDevice != 0 && backend != "cpu" {
+		gpuDevice = checkGPUDevice(gpuDevice, len(ggmlGPUDevices()))
+	}
+
 	log.Printf("Loading whisper model for preset %q: %s (backend: %s)", p.Name, modelPath, backend)
 
 	// Run model init in a goroutine with timeout to catch GPU backend hangs.
@@ -725,7 +1932,7 @@ func (s *PresetService) getOrLoadEngine(p *config.Preset) (*WhisperEngine, error
 	}
 	ch := make(chan initResult, 1)
 	go func() {
-		eng, initErr := NewWhisperEngine(modelPath, backend)
+		eng, initErr := NewWhisperEngine(modelPath, backend, gpuDevice)
 		ch <- initResult{eng, initErr}
 	}()
 
@@ -749,92 +1956,289 @@ func (s *PresetService) getOrLoadEngine(p *config.Preset) (*WhisperEngine, error
 		return existing, nil
 	}
 	s.engines[p.ID] = engine
+	s.engineUsed[p.ID] = time.Now()
 	s.mu.Unlock()
 
 	log.Printf("Model loaded for preset %q", p.Name)
 	return engine, nil
 }
 
+// evictEngines closes least recently used engines so that loaded engines plus
+// loads in flight fit in limit (0 = unlimited). Engines of presets that are
+// recording or processing are never closed; KeepModelLoaded presets are, and
+// reload on their next use. Must be called with s.mu held.
+func (s *PresetService) evictEngines(limit int) {
+	if limit <= 0 {
+		return
+	}
+	excess := len(s.engines) + len(s.engineLoading) - limit
+	if excess <= 0 {
+		return
+	}
+	lastUsed := make(map[string]time.Time, len(s.engines))
+	busy := make(map[string]bool)
+	for id := range s.engines {
+		lastUsed[id] = s.engineUsed[id]
+		if s.states[id] == "recording" || s.pending[id] > 0 {
+			busy[id] = true
+		}
+	}
+	for _, id := range engineEvictions(lastUsed, busy, excess) {
+		log.Printf("Unloading model for preset %s: over the limit of %d loaded engines", id, limit)
+		s.engines[id].Close()
+		delete(s.engines, id)
+		delete(s.engineUsed, id)
+	}
+}
+
+// engineEvictions picks up to n engines to close, least recently used first,
+// skipping busy ones. Ties are broken by preset ID for a stable order.
+func engineEvictions(lastUsed map[string]time.Time, busy map[string]bool, n int) []string {
+	ids := make([]string, 0, len(lastUsed))
+	for id := range lastUsed {
+		if !busy[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := lastUsed[ids[i]], lastUsed[ids[j]]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return ids[i] < ids[j]
+	})
+	if n < len(ids) {
+		ids = ids[:n]
+	}
+	return ids
+}
+
 func (s *PresetService) findModel(modelName string) (string, error) {
-	dir := s.models.ResolveModelsDir()
+	return findModelFile(s.models.ResolveModelsDir(), modelName)
+}
+
+// ErrModelNotDownloaded matches (errors.Is) a ModelNotDownloadedError.
+var ErrModelNotDownloaded = errors.New("model not downloaded")
+
+// ModelNotDownloadedError reports that a preset's model file is missing from
+// the models directory, so the UI can offer to download it.
+type ModelNotDownloadedError struct {
+	Model string
+}
 
-	fileName := "ggml-" + modelName + ".bin"
-	path := filepath.Join(dir, fileName)
-	if _, err := os.Stat(path); err == nil {
-		return path, nil
+func (e *ModelNotDownloadedError) Error() string {
+	return fmt.Sprintf("model %s is not downloaded", e.Model)
+}
+
+func (e *ModelNotDownloadedError) Is(target error) bool { return target == ErrModelNotDownloaded }
+
+// findModelFile returns the path of ggml-<modelName>.bin in dir. It never
+// substitutes another model: a preset set up for large-v3 silently running on
+// whatever .bin happens to be there gives confusing results.
+func findModelFile(dir, modelName string) (string, error) {
+	path := filepath.Join(dir, "ggml-"+modelName+".bin")
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", &ModelNotDownloadedError{Model: modelName}
+		}
+		return "", fmt.Errorf("cannot read model %s: %w", path, err)
 	}
+	return path, nil
+}
 
-	entries, err := os.ReadDir(dir)
+// maxPresets returns the configured preset cap.
+func maxPresets() int {
+	cfg, err := config.Load()
 	if err != nil {
-		return "", fmt.Errorf("cannot read models dir %s: %w", dir, err)
+		return defaultMaxPresets
 	}
+	return presetLimit(cfg)
+}
 
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".bin") {
-			return filepath.Join(dir, e.Name()), nil
-		}
+// presetLimit returns the preset cap of cfg (MaxPresets, or the default).
+func presetLimit(cfg *config.AppConfig) int {
+	if cfg.MaxPresets <= 0 {
+		return defaultMaxPresets
 	}
+	return cfg.MaxPresets
+}
 
-	return "", fmt.Errorf("no model found in %s (looking for %s)", dir, modelName)
+// maxLoadedEngines returns the configured cap on loaded engines (0 = unlimited).
+func maxLoadedEngines() int {
+	cfg, err := config.Load()
+	if err != nil || cfg.MaxLoadedEngines < 0 {
+		return 0
+	}
+	return cfg.MaxLoadedEngines
 }
 
-// isHallucination detects common whisper hallucinations produced on silence.
-func isHallucination(text string) bool {
-	if text == "" {
-		return false
+// inferenceThreadSetting returns the configured whisper thread count (0 = auto).
+func inferenceThreadSetting() int {
+	cfg, err := config.Load()
+	if err != nil {
+		return 0
 	}
-	lower := strings.ToLower(strings.TrimSpace(text))
+	return cfg.Threads
+}
 
-	// Pure punctuation / ellipsis / musical notes
-	cleaned := strings.Map(func(r rune) rune {
-		if r == '.' || r == ',' || r == '!' || r == '?' || r == '-' ||
-			r == '…' || r == ' ' || r == '\n' || r == '\t' ||
-			r == '♪' || r == '♫' || r == '🎵' || r == '*' {
-			return -1
-		}
-		return r
-	}, lower)
-	if cleaned == "" {
+// stripNoiseMarkersSetting returns the stripNoiseMarkers global setting
+// (default on: every [...] is stripped).
+func stripNoiseMarkersSetting() bool {
+	cfg, err := config.Load()
+	if err != nil || cfg.StripNoiseMarkers == nil {
 		return true
 	}
+	return *cfg.StripNoiseMarkers
+}
 
-	// Known hallucination phrases (whisper on silence)
-	hallucinations := []string{
-		"продолжение следует",
-		"субтитры сделал",
-		"субтитры делал",
-		"субтитры создан",
-		"спасибо за просмотр",
-		"спасибо за внимание",
-		"подписывайтесь на канал",
-		"до свидания",
-		"до новых встреч",
-		"благодарю за внимание",
-		"редактор субтитров",
-		"thank you",
-		"thanks for watching",
-		"subscribe",
-		"like and subscribe",
-		"please subscribe",
-		"the end",
-		"to be continued",
-		"subtitles by",
-		"translated by",
-		"you",
-		"bye",
-	}
-	for _, h := range hallucinations {
-		if strings.Contains(lower, h) {
-			return true
-		}
+// gpuDeviceSetting returns the configured GPU device index (0 = first GPU).
+func gpuDeviceSetting() int {
+	cfg, err := config.Load()
+	if err != nil {
+		return 0
 	}
+	return cfg.GPUDeviceIndex
+}
 
-	// Very short output (1-2 words) that's just filler
-	if len([]rune(cleaned)) <= 3 {
-		return true
+// checkGPUDevice validates the GPUDeviceIndex setting against the n GPU
+// devices of the loaded ggml backends (ggmlGPUDevices): an index out of range
+// (a card was removed) falls back to the first GPU. With no GPU device the
+// index is kept; whisper runs on the CPU then anyway.
+func checkGPUDevice(index, n int) int {
+	if index < 0 || n > 0 && index >= n {
+		log.Printf("GPU device %d not found (%d detected), using GPU 0", index, n)
+		return 0
+	}
+	return index
+}
+
+// busyBehavior returns what a hotkey press does while a recording is being
+// transcribed: "block" (ignored, the default) or "queue".
+func busyBehavior() string {
+	cfg, err := config.Load()
+	if err != nil || cfg.BusyBehavior != "queue" {
+		return "block"
+	}
+	return "queue"
+}
+
+// maxRecordDuration returns the configured recording limit (0 = unlimited).
+func maxRecordDuration() time.Duration {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+	}
+	return recordLimit(cfg)
+}
+
+// recordLimit resolves MaxRecordSeconds: unset → default, 0 or negative → unlimited.
+func recordLimit(cfg *config.AppConfig) time.Duration {
+	if cfg == nil || cfg.MaxRecordSeconds == nil {
+		return defaultMaxRecordSeconds * time.Second
 	}
+	if *cfg.MaxRecordSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(*cfg.MaxRecordSeconds) * time.Second
+}
 
-	return false
+// minRecordSamples is the shortest recording that gets transcribed, from
+// MinRecordMs. Shorter ones are accidental presses whisper would hallucinate on.
+func minRecordSamples() int {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("failed to load config: %v", err)
+	}
+	return minRecordMs(cfg) * sampleRate / 1000
+}
+
+// minRecordMs resolves MinRecordMs: unset → default, at least minWhisperMs.
+func minRecordMs(cfg *config.AppConfig) int {
+	if cfg == nil || cfg.MinRecordMs <= 0 {
+		return defaultMinRecordMs
+	}
+	return max(cfg.MinRecordMs, minWhisperMs)
+}
+
+// pcmBytes is the memory taken by d of recorded 16kHz mono float32 audio.
+func pcmBytes(d time.Duration) int64 {
+	return int64(d/time.Second) * sampleRate * 4
+}
+
+func limitString(d time.Duration) string {
+	if d == 0 {
+		return "unlimited"
+	}
+	return d.String()
+}
+
+// normalizeSpacing applies the spacing rules to raw whisper output:
+//   - trailing whitespace is always removed;
+//   - leading whitespace is removed, unless preserveLeading is set — then any
+//     leading run (whisper usually emits one space) is collapsed to a single
+//     space so consecutive dictations join as words;
+//   - whitespace-only output becomes "".
+func normalizeSpacing(text string, preserveLeading bool) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return ""
+	}
+	if preserveLeading && trimmed[0] != text[0] {
+		return " " + trimmed
+	}
+	return trimmed
+}
+
+// autoSpace prepends a space to text so consecutive dictations into the same
+// field don't run together. No space is added before the first paste (prevEnd
+// 0), after whitespace, or before text starting with whitespace or closing
+// punctuation (".", ",", ")" — but "(" and opening quotes get one).
+func autoSpace(text string, prevEnd rune) string {
+	if text == "" || prevEnd == 0 || unicode.IsSpace(prevEnd) {
+		return text
+	}
+	first, _ := utf8.DecodeRuneInString(text)
+	if unicode.IsSpace(first) {
+		return text
+	}
+	if unicode.IsPunct(first) && !unicode.In(first, unicode.Ps, unicode.Pi) {
+		return text
+	}
+	return " " + text
+}
+
+// applyAffixes wraps the pasted text in the preset's PrependText/AppendText.
+// Only the paste output gets them; history keeps the bare transcription.
+func applyAffixes(text string, preset config.Preset) string {
+	return unescapeAffix(preset.PrependText) + text + unescapeAffix(preset.AppendText)
+}
+
+// unescapeAffix expands the escapes allowed in affixes: \n, \t and \\.
+// Any other backslash is kept as is.
+func unescapeAffix(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
 }
 
 func (s *PresetService) findPresetByID(id string) *config.Preset {
@@ -846,6 +2250,66 @@ func (s *PresetService) findPresetByID(id string) *config.Preset {
 	return nil
 }
 
+// checkHotkeyConflict returns an error naming the enabled preset whose hotkey is
+// identical to, or a subset/superset of, p's hotkey. Disabled presets never conflict.
+// Identical hotkeys are allowed when the presets are app-scoped (AppMatch) and at
+// most one of them is the catch-all — onHotkeyPress then picks by focused app.
+// Must be called WITHOUT s.mu held.
+func (s *PresetService) checkHotkeyConflict(p config.Preset) error {
+	if !p.Enabled || p.Hotkey == "" || s.hotkeys == nil {
+		return nil
+	}
+	keys, anySide, err := parseHotkey(p.Hotkey)
+	if err != nil {
+		return nil // invalid hotkeys are reported by Register
+	}
+	conflicts := s.hotkeys.FindConflicts(p.ID, keys, anySide)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range conflicts {
+		if c.PresetID == pauseHotkeyID {
+			return fmt.Errorf("hotkey %q is already used to pause hotkeys", p.Hotkey)
+		}
+		if c.PresetID == repeatHotkeyID {
+			return fmt.Errorf("hotkey %q is already used to repeat the last transcription", p.Hotkey)
+		}
+		name, hotkey, scoped := c.PresetID, "", false
+		if other := s.findPresetByID(c.PresetID); other != nil {
+			name, hotkey, scoped = other.Name, other.Hotkey, len(other.AppMatch) > 0
+		}
+		if c.Subset {
+			return fmt.Errorf("hotkey %q overlaps with %q of preset %q — pressing one would trigger the other", p.Hotkey, hotkey, name)
+		}
+		if !scoped && len(p.AppMatch) == 0 {
+			return fmt.Errorf("hotkey %q is already used by preset %q", p.Hotkey, name)
+		}
+	}
+	return nil
+}
+
+// hotkeySiblings returns the enabled presets (including p) bound to the same key combo as p.
+// Must be called with s.mu held.
+func (s *PresetService) hotkeySiblings(p *config.Preset) []config.Preset {
+	keys, anySide, err := parseHotkey(p.Hotkey)
+	if err != nil {
+		return nil
+	}
+	var out []config.Preset
+	for _, other := range s.cfg.Presets {
+		if !other.Enabled {
+			continue
+		}
+		if otherKeys, otherAnySide, err := parseHotkey(other.Hotkey); err == nil && keySetEqual(keys, anySide, otherKeys, otherAnySide) {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
 func (s *PresetService) findPresetIndex(id string) int {
 	for i := range s.cfg.Presets {
 		if s.cfg.Presets[i].ID == id {
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// maxPresetFileSize bounds what ImportPreset reads; a shared preset is a few KB.
+const maxPresetFileSize = 1 << 20
+
+// ExportPreset writes a single preset as JSON for sharing. The ID and hotkey are
+// machine-specific and left out. With an empty path a save dialog is opened.
+func (s *PresetService) ExportPreset(id, path string) error {
+	s.mu.Lock()
+	p := s.findPresetByID(id)
+	if p == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("preset not found: %s", id)
+	}
+	preset := *p // copy
+	s.mu.Unlock()
+
+	if path == "" {
+		app := application.Get()
+		if app == nil {
+			return fmt.Errorf("no application window for the file dialog")
+		}
+		picked, err := app.Dialog.SaveFile().
+			AddFilter("Preset (*.json)", "*.json").
+			SetFilename(presetFileName(preset.Name)).
+			PromptForSingleSelection()
+		if err != nil {
+			return err
+		}
+		if picked == "" {
+			return nil // cancelled
+		}
+		path = picked
+	}
+
+	data, err := json.MarshalIndent(sharedPreset(preset), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write preset: %w", err)
+	}
+	log.Printf("Preset %q exported to %s", preset.Name, path)
+	return nil
+}
+
+// ImportPreset reads a preset exported by ExportPreset and adds it under a new
+// ID. It arrives without a hotkey and disabled, so it can't collide with an
+// existing binding until the user assigns one. A model outside the catalog is
+// rejected unless that file is in the models folder. With an empty path a file
+// picker is opened.
+func (s *PresetService) ImportPreset(path string) (config.Preset, error) {
+	if path == "" {
+		app := application.Get()
+		if app == nil {
+			return config.Preset{}, fmt.Errorf("no application window for the file picker")
+		}
+		picked, err := app.Dialog.OpenFile().
+			CanChooseFiles(true).
+			CanChooseDirectories(false).
+			AddFilter("Preset (*.json)", "*.json").
+			SetTitle("Import Preset").
+			PromptForSingleSelection()
+		if err != nil {
+			return config.Preset{}, err
+		}
+		if picked == "" {
+			return config.Preset{}, nil // cancelled
+		}
+		path = picked
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return config.Preset{}, fmt.Errorf("read preset: %w", err)
+	}
+	if info.Size() > maxPresetFileSize {
+		return config.Preset{}, fmt.Errorf("%s is too large for a preset file", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config.Preset{}, fmt.Errorf("read preset: %w", err)
+	}
+	p, err := parseSharedPreset(data)
+	if err != nil {
+		return config.Preset{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	// A catalog model can still be downloaded; a custom one must already be here.
+	if isCustomModelName(p.ModelName) && s.models != nil {
+		if _, err := findModelFile(s.models.ResolveModelsDir(), p.ModelName); err != nil {
+			return config.Preset{}, fmt.Errorf("%s: unknown model %q (not in the catalog or the models folder)", filepath.Base(path), p.ModelName)
+		}
+	}
+	return s.CreatePreset(p)
+}
+
+// sharedPreset strips the machine-specific fields from a preset for export.
+func sharedPreset(p config.Preset) config.Preset {
+	p.ID = ""
+	p.Hotkey = ""
+	p.Enabled = false
+	return p
+}
+
+// parseSharedPreset decodes and validates an exported preset.
+func parseSharedPreset(data []byte) (config.Preset, error) {
+	var p config.Preset
+	if err := json.Unmarshal(data, &p); err != nil {
+		return config.Preset{}, fmt.Errorf("not a preset file: %w", err)
+	}
+	p = sharedPreset(p)
+
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return config.Preset{}, fmt.Errorf("preset has no name")
+	}
+	switch p.InputMode {
+	case "", "hold", "toggle", "tap":
+	default:
+		return config.Preset{}, fmt.Errorf("invalid input mode %q", p.InputMode)
+	}
+	switch p.OutputFormat {
+	case "", "srt":
+	default:
+		return config.Preset{}, fmt.Errorf("invalid output format %q", p.OutputFormat)
+	}
+	switch p.RearmHold {
+	case "", "join", "split":
+	default:
+		return config.Preset{}, fmt.Errorf("invalid rearmHold %q", p.RearmHold)
+	}
+	if p.ModelName != "" && !isValidModelName(p.ModelName) && !isCustomModelName(p.ModelName) {
+		return config.Preset{}, fmt.Errorf("invalid model name %q", p.ModelName)
+	}
+	if !validColor(p.Color) {
+		return config.Preset{}, fmt.Errorf("invalid color %q", p.Color)
+	}
+	return p, nil
+}
+
+// presetFileName suggests a file name for an exported preset.
+func presetFileName(name string) string {
+	runes := []rune(name)
+	for i, c := range runes {
+		if !isModelNameRune(c) {
+			runes[i] = '-'
+		}
+	}
+	base := strings.Trim(string(runes), "-.")
+	if base == "" {
+		base = "preset"
+	}
+	return base + ".json"
+}
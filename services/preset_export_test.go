@@ -0,0 +1,118 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestExportImportPresetRoundtrip(t *testing.T) {
+	useTempConfig(t)
+
+	orig := config.Preset{
+		ID:             "orig",
+		Name:           "Chat (RU)",
+		ModelName:      "small",
+		InputMode:      "toggle",
+		Hotkey:         "ctrl+shift+f1",
+		Language:       "ru",
+		Enabled:        true,
+		AutoCapitalize: true,
+		AppendText:     " ",
+		Color:          "#ff8800",
+		CommandMode:    true,
+		CommandMap:     map[string]string{"new line": "key:enter"},
+	}
+	s := &PresetService{
+		cfg:    &config.AppConfig{Presets: []config.Preset{orig}},
+		states: make(map[string]string),
+	}
+
+	path := filepath.Join(t.TempDir(), presetFileName(orig.Name))
+	if err := s.ExportPreset("orig", path); err != nil {
+		t.Fatalf("ExportPreset: %v", err)
+	}
+	got, err := s.ImportPreset(path)
+	if err != nil {
+		t.Fatalf("ImportPreset: %v", err)
+	}
+
+	if got.ID == "" || got.ID == orig.ID {
+		t.Errorf("imported ID = %q, want a new ID", got.ID)
+	}
+	if got.Hotkey != "" || got.Enabled {
+		t.Errorf("imported hotkey/enabled = %q/%v, want empty/false", got.Hotkey, got.Enabled)
+	}
+	if got.Name != orig.Name || got.ModelName != orig.ModelName || got.InputMode != orig.InputMode ||
+		got.Language != orig.Language || !got.AutoCapitalize || got.AppendText != orig.AppendText ||
+		got.Color != orig.Color || got.CommandMap["new line"] != "key:enter" {
+		t.Errorf("imported preset %+v does not match exported %+v", got, orig)
+	}
+	if len(s.cfg.Presets) != 2 {
+		t.Errorf("preset count = %d, want 2", len(s.cfg.Presets))
+	}
+
+	if err := s.ExportPreset("missing", path); err == nil {
+		t.Error("ExportPreset(missing) = nil, want error")
+	}
+}
+
+func TestImportPresetUnknownModel(t *testing.T) {
+	useTempConfig(t)
+
+	s := &PresetService{
+		cfg:    &config.AppConfig{},
+		states: make(map[string]string),
+		models: NewModelService(),
+	}
+	path := filepath.Join(t.TempDir(), "custom.json")
+	if err := os.WriteFile(path, []byte(`{"name":"Custom","modelName":"my-finetune"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A custom model that isn't on disk can't be downloaded either.
+	if _, err := s.ImportPreset(path); err == nil {
+		t.Error("ImportPreset with a missing custom model = nil, want error")
+	}
+
+	modelFile := filepath.Join(s.models.ResolveModelsDir(), "ggml-my-finetune.bin")
+	if err := os.WriteFile(modelFile, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(modelFile) })
+	if _, err := s.ImportPreset(path); err != nil {
+		t.Errorf("ImportPreset with the custom model present: %v", err)
+	}
+}
+
+func TestParseSharedPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"minimal", `{"name":"Notes"}`, false},
+		{"machine fields stripped", `{"id":"x","name":"Notes","hotkey":"f9","enabled":true}`, false},
+		{"not json", `name: Notes`, true},
+		{"no name", `{"name":"  "}`, true},
+		{"bad input mode", `{"name":"N","inputMode":"push"}`, true},
+		{"bad output format", `{"name":"N","outputFormat":"docx"}`, true},
+		{"bad rearm", `{"name":"N","rearmHold":"always"}`, true},
+		{"path as model", `{"name":"N","modelName":"../../etc/passwd"}`, true},
+		{"custom model", `{"name":"N","modelName":"my-finetune"}`, false},
+		{"bad color", `{"name":"N","color":"red"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseSharedPreset([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSharedPreset(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			}
+			if err == nil && (p.ID != "" || p.Hotkey != "" || p.Enabled) {
+				t.Errorf("machine-specific fields kept: %+v", p)
+			}
+		})
+	}
+}
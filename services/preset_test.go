@@ -1,6 +1,16 @@
 package services
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
 
 func TestIsHallucination(t *testing.T) {
 	tests := []struct {
@@ -94,6 +104,193 @@ func TestIsHallucinationKnownFalsePositives(t *testing.T) {
 	}
 }
 
+func TestNormalizeSpacing(t *testing.T) {
+	tests := []struct {
+		text     string
+		preserve bool
+		want     string
+	}{
+		{" Hello world", false, "Hello world"},
+		{" Hello world", true, " Hello world"},
+		{"Hello world", true, "Hello world"},
+		{"\t\n  Hello", true, " Hello"},
+		{" Hello  \n", true, " Hello"},
+		{" Hello  \n", false, "Hello"},
+		{" line one\nline two ", false, "line one\nline two"},
+		{"   ", true, ""},
+		{"", false, ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeSpacing(tt.text, tt.preserve); got != tt.want {
+			t.Errorf("normalizeSpacing(%q, %v) = %q, want %q", tt.text, tt.preserve, got, tt.want)
+		}
+	}
+}
+
+func TestAutoSpace(t *testing.T) {
+	tests := []struct {
+		text    string
+		prevEnd rune
+		want    string
+	}{
+		{"hello", 0, "hello"},             // first paste
+		{"there", 'o', " there"},          // repeated dictation
+		{"there", '.', " there"},          // after a sentence
+		{"there", ' ', "there"},           // previous paste ended in a space
+		{"there", '\n', "there"},          // ...or a newline
+		{" there", 'o', " there"},         // already spaced (PreserveLeadingSpace)
+		{", and then", 'o', ", and then"}, // punctuation attaches
+		{"... maybe", 'o', "... maybe"},   // ellipsis attaches
+		{"(aside)", 'o', " (aside)"},      // opening bracket starts a word
+		{"«цитата»", 'о', " «цитата»"},    // opening quote starts a word
+		{"", 'o', ""},
+	}
+	for _, tt := range tests {
+		if got := autoSpace(tt.text, tt.prevEnd); got != tt.want {
+			t.Errorf("autoSpace(%q, %q) = %q, want %q", tt.text, tt.prevEnd, got, tt.want)
+		}
+	}
+}
+
+func TestRecordLimit(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	tests := []struct {
+		name string
+		cfg  *config.AppConfig
+		want time.Duration
+	}{
+		{"nil config", nil, 180 * time.Second},
+		{"unset", &config.AppConfig{}, 180 * time.Second},
+		{"custom", &config.AppConfig{MaxRecordSeconds: intp(600)}, 10 * time.Minute},
+		{"unlimited", &config.AppConfig{MaxRecordSeconds: intp(0)}, 0},
+		{"negative is unlimited", &config.AppConfig{MaxRecordSeconds: intp(-5)}, 0},
+	}
+	for _, tt := range tests {
+		if got := recordLimit(tt.cfg); got != tt.want {
+			t.Errorf("%s: recordLimit = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		cfg  *config.AppConfig
+		want int
+	}{
+		{nil, 500},
+		{&config.AppConfig{}, 500},
+		{&config.AppConfig{MinRecordMs: 250}, 250},
+		{&config.AppConfig{MinRecordMs: 20}, 100},
+	} {
+		if got := minRecordMs(tt.cfg); got != tt.want {
+			t.Errorf("minRecordMs(%+v) = %d, want %d", tt.cfg, got, tt.want)
+		}
+	}
+
+	if got := pcmBytes(time.Minute); got != 60*16000*4 {
+		t.Errorf("pcmBytes(1m) = %d, want %d", got, 60*16000*4)
+	}
+}
+
+// useTempConfig points the config package at a fresh temporary directory for
+// the test and returns the config.json path there.
+func useTempConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	config.Dir = dir
+	t.Cleanup(func() { config.Dir = "" })
+	return filepath.Join(dir, "config.json")
+}
+
+func TestCreatePresetLimit(t *testing.T) {
+	cfgFile := useTempConfig(t)
+
+	presets := make([]config.Preset, defaultMaxPresets)
+	for i := range presets {
+		presets[i] = config.Preset{ID: fmt.Sprintf("p%d", i), Name: fmt.Sprintf("Preset %d", i)}
+	}
+	s := &PresetService{
+		cfg:    &config.AppConfig{Presets: presets},
+		states: make(map[string]string),
+	}
+
+	if _, err := s.CreatePreset(config.Preset{Name: "one too many"}); err == nil {
+		t.Fatal("CreatePreset beyond the cap should fail")
+	}
+	if len(s.cfg.Presets) != defaultMaxPresets {
+		t.Errorf("preset count = %d, want %d", len(s.cfg.Presets), defaultMaxPresets)
+	}
+	if _, err := os.Stat(cfgFile); !os.IsNotExist(err) {
+		t.Errorf("config should not be saved when the cap is hit (stat err: %v)", err)
+	}
+
+	// Below the cap creation succeeds.
+	s.cfg.Presets = s.cfg.Presets[:defaultMaxPresets-1]
+	if _, err := s.CreatePreset(config.Preset{Name: "fits"}); err != nil {
+		t.Fatalf("CreatePreset under the cap: %v", err)
+	}
+	if len(s.cfg.Presets) != defaultMaxPresets {
+		t.Errorf("preset count = %d, want %d", len(s.cfg.Presets), defaultMaxPresets)
+	}
+}
+
+func TestDuplicatePreset(t *testing.T) {
+	useTempConfig(t)
+
+	orig := config.Preset{
+		ID:         "orig",
+		Name:       "Chat",
+		ModelName:  "small",
+		Hotkey:     "ctrl+f9",
+		Enabled:    true,
+		AppMatch:   []string{"*slack*"},
+		CommandMap: map[string]string{"send": "key:enter"},
+	}
+	s := &PresetService{
+		cfg:    &config.AppConfig{Presets: []config.Preset{orig}},
+		states: make(map[string]string),
+	}
+
+	dup, err := s.DuplicatePreset("orig")
+	if err != nil {
+		t.Fatalf("DuplicatePreset: %v", err)
+	}
+	if dup.ID == "" || dup.ID == orig.ID {
+		t.Errorf("copy ID = %q, want a new ID", dup.ID)
+	}
+	if dup.Hotkey != "" || dup.Enabled {
+		t.Errorf("copy hotkey/enabled = %q/%v, want empty/false", dup.Hotkey, dup.Enabled)
+	}
+	if dup.Name != "Chat (copy)" || dup.ModelName != "small" {
+		t.Errorf("copy = %+v, want name %q and the original's settings", dup, "Chat (copy)")
+	}
+	if len(s.cfg.Presets) != 2 {
+		t.Fatalf("preset count = %d, want 2", len(s.cfg.Presets))
+	}
+
+	// Editing the copy must not reach the original.
+	dup.AppMatch[0] = "code*"
+	dup.CommandMap["send"] = "key:tab"
+	if s.cfg.Presets[0].AppMatch[0] != "*slack*" || s.cfg.Presets[0].CommandMap["send"] != "key:enter" {
+		t.Errorf("original changed through the copy: %+v", s.cfg.Presets[0])
+	}
+
+	if _, err := s.DuplicatePreset("missing"); err == nil {
+		t.Error("DuplicatePreset(missing) = nil, want error")
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	for _, c := range []string{"", "#fff", "#FF8800", "#a1b2c3"} {
+		if !validColor(c) {
+			t.Errorf("validColor(%q) = false, want true", c)
+		}
+	}
+	for _, c := range []string{"red", "#ff", "#ff88001", "ff8800", "#ggg", "#fff;x"} {
+		if validColor(c) {
+			t.Errorf("validColor(%q) = true, want false", c)
+		}
+	}
+}
+
 func TestIsEnglishOnlyModel(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -125,3 +322,658 @@ func TestIsEnglishOnlyModel(t *testing.T) {
 		})
 	}
 }
+
+// fakeEngine implements languageSource without loading a whisper model.
+type fakeEngine struct {
+	multilingual bool
+	langs        []string
+
+	text     string    // Transcribe and TranscribeLong output
+	segments []Segment // TranscribeSegmentsLong output
+	err      error
+
+	gotOpts *DecodeOptions // if set, records the options of the last call
+	calls   *[]string      // if set, records the names of the methods called
+}
+
+func (f fakeEngine) record(method string, opts DecodeOptions) {
+	if f.gotOpts != nil {
+		*f.gotOpts = opts
+	}
+	if f.calls != nil {
+		*f.calls = append(*f.calls, method)
+	}
+}
+
+func (f fakeEngine) Transcribe(_ []float32, _ string, opts DecodeOptions) (string, error) {
+	f.record("Transcribe", opts)
+	return f.text, f.err
+}
+
+func (f fakeEngine) IsMultilingual() bool         { return f.multilingual }
+func (f fakeEngine) SupportedLanguages() []string { return f.langs }
+
+func (f fakeEngine) TranscribeLong(_ []float32, _ string, opts DecodeOptions, _ func(int, int), _ func(int, int, string)) (string, error) {
+	f.record("TranscribeLong", opts)
+	return f.text, f.err
+}
+
+func (f fakeEngine) TranscribeSegmentsLong(_ []float32, _ string, opts DecodeOptions, _ func(int, int)) ([]Segment, error) {
+	f.record("TranscribeSegmentsLong", opts)
+	return f.segments, f.err
+}
+
+func TestTranscribeSamples(t *testing.T) {
+	speech := make([]float32, sampleRate) // 1s
+	tests := []struct {
+		name    string
+		eng     fakeEngine
+		preset  config.Preset
+		samples []float32
+		want    TranscriptionResult
+	}{
+		{"plain text trimmed", fakeEngine{text: " Hello world. "}, config.Preset{}, speech,
+			TranscriptionResult{Text: "Hello world."}},
+		{"leading space preserved", fakeEngine{text: " Hello"}, config.Preset{PreserveLeadingSpace: true}, speech,
+			TranscriptionResult{Text: " Hello"}},
+		{"noise markers stripped", fakeEngine{text: " [MUSIC] Hello (laughter)"}, config.Preset{}, speech,
+			TranscriptionResult{Text: "Hello"}},
+		{"only silence", fakeEngine{text: "[BLANK_AUDIO]"}, config.Preset{}, speech,
+			TranscriptionResult{}},
+		{"only noise", fakeEngine{text: "[coughing]"}, config.Preset{}, speech,
+			TranscriptionResult{NoiseOnly: true}},
+		{"noise and silence chunks", fakeEngine{text: "[BLANK_AUDIO] (music)"}, config.Preset{}, speech,
+			TranscriptionResult{NoiseOnly: true}},
+		{"nothing", fakeEngine{text: ""}, config.Preset{}, speech,
+			TranscriptionResult{}},
+		{"hallucination filtered", fakeEngine{text: " Thanks for watching!"}, config.Preset{}, speech,
+			TranscriptionResult{}},
+		{"short word, filter disabled", fakeEngine{text: " Bye."}, config.Preset{DisableHallucinationFilter: true}, speech,
+			TranscriptionResult{Text: "Bye."}},
+		{"you, filter disabled", fakeEngine{text: " You"}, config.Preset{DisableHallucinationFilter: true}, speech,
+			TranscriptionResult{Text: "You"}},
+		{"too short", fakeEngine{text: "Hello"}, config.Preset{}, make([]float32, minWhisperMs*sampleRate/1000-1),
+			TranscriptionResult{}},
+		{"engine error", fakeEngine{err: errors.New("boom")}, config.Preset{}, speech,
+			TranscriptionResult{Error: "Transcription failed: boom"}},
+		{"srt", fakeEngine{segments: []Segment{{Start: 0, End: time.Second, Text: "Hello"}}},
+			config.Preset{OutputFormat: "srt"}, speech,
+			TranscriptionResult{Text: "1\n00:00:00,000 --> 00:00:01,000\nHello\n"}},
+		{"srt hallucination", fakeEngine{segments: []Segment{{Start: 0, End: time.Second, Text: "Subscribe!"}}},
+			config.Preset{OutputFormat: "srt"}, speech,
+			TranscriptionResult{}},
+		{"srt segments post-processed", fakeEngine{segments: []Segment{
+			{Start: 0, End: time.Second, Text: " hello there"},
+			{Start: time.Second, End: 2 * time.Second, Text: " [MUSIC]"},
+			{Start: 2 * time.Second, End: 3 * time.Second, Text: " good morning"},
+		}},
+			config.Preset{OutputFormat: "srt", Capitalize: true}, speech,
+			TranscriptionResult{Text: "1\n00:00:00,000 --> 00:00:01,000\nHello there\n\n2\n00:00:02,000 --> 00:00:03,000\nGood morning\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transcribeSamples(tt.eng, tt.preset, "en", tt.samples, nil, nil, nil); got != tt.want {
+				t.Errorf("transcribeSamples = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeSamplesLowLatency(t *testing.T) {
+	speech := make([]float32, 40*sampleRate) // long enough to be chunked normally
+	preset := config.Preset{
+		LowLatency:     true,
+		OutputFormat:   "srt",
+		BeamSize:       5,
+		RobustDecode:   true,
+		Capitalize:     true,
+		EndPunctuation: true,
+	}
+	var calls []string
+	opts := DecodeOptions{BeamSize: -1}
+	eng := fakeEngine{text: " thank you [MUSIC] ", calls: &calls, gotOpts: &opts}
+	progress := 0
+	res := transcribeSamples(eng, preset, "en", speech, func(int, int) { progress++ }, nil, nil)
+
+	// Trimmed and marker-free, but neither filtered as a hallucination nor formatted.
+	if res != (TranscriptionResult{Text: "thank you"}) {
+		t.Errorf("result = %+v, want raw trimmed text", res)
+	}
+	if len(calls) != 1 || calls[0] != "Transcribe" {
+		t.Errorf("engine calls = %v, want a single Transcribe", calls)
+	}
+	if opts != (DecodeOptions{}) {
+		t.Errorf("decode options = %+v, want greedy defaults", opts)
+	}
+	if progress != 0 {
+		t.Errorf("progress called %d times, want 0", progress)
+	}
+	if n := transcriptionChunks(preset, len(speech)); n != 1 {
+		t.Errorf("transcriptionChunks = %d, want 1", n)
+	}
+	if n := transcriptionChunks(config.Preset{}, len(speech)); n != len(chunkBounds(len(speech))) || n < 2 {
+		t.Errorf("transcriptionChunks without LowLatency = %d, want one per chunk", n)
+	}
+
+	eng = fakeEngine{text: "[coughing]"}
+	if res := transcribeSamples(eng, preset, "en", speech, nil, nil, nil); res != (TranscriptionResult{NoiseOnly: true}) {
+		t.Errorf("noise-only result = %+v, want NoiseOnly", res)
+	}
+}
+
+func TestTranscribeSamplesTrace(t *testing.T) {
+	var steps []string
+	trace := traceLog(func(format string, args ...any) {
+		steps = append(steps, fmt.Sprintf(format, args...))
+	})
+	speech := make([]float32, sampleRate)
+	got := transcribeSamples(fakeEngine{text: " [MUSIC] Hello world "}, config.Preset{}, "en", speech, nil, nil, trace)
+	if got.Text != "Hello world" {
+		t.Fatalf("transcribeSamples = %+v, want Hello world", got)
+	}
+	want := []string{
+		`whisper output: " [MUSIC] Hello world "`,
+		`noise markers stripped: "  Hello world "`,
+		`spacing normalized: "Hello world"`,
+	}
+	if strings.Join(steps, "\n") != strings.Join(want, "\n") {
+		t.Errorf("trace steps:\n%s\nwant:\n%s", strings.Join(steps, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A nil trace is a no-op.
+	traceLog(nil).printf("ignored %d", 1)
+}
+
+func TestDecodeFallback(t *testing.T) {
+	// whisper.cpp's own fallback: retries from 0 in steps of 0.2, entropy 2.4,
+	// log probability -1.
+	defaults := fallbackParams{Temperature: 0, TemperatureInc: 0.2, EntropyThold: 2.4, LogprobThold: -1}
+	if fb := fullFallback(DecodeOptions{}); fb != defaults {
+		t.Errorf("params without RobustDecode = %+v, want whisper defaults %+v", fb, defaults)
+	}
+	if fb := fullFallback(DecodeOptions{RobustDecode: true}); fb != defaults {
+		t.Errorf("params with RobustDecode and no thresholds = %+v, want whisper defaults %+v", fb, defaults)
+	}
+
+	// Thresholds override the defaults only with RobustDecode.
+	opts := DecodeOptions{RobustDecode: true, EntropyThreshold: 2.8, LogProbThreshold: -0.5}
+	want := fallbackParams{Temperature: 0, TemperatureInc: 0.2, EntropyThold: 2.8, LogprobThold: -0.5}
+	if fb := fullFallback(opts); fb != want {
+		t.Errorf("params with thresholds = %+v, want %+v", fb, want)
+	}
+	opts.RobustDecode = false
+	if fb := fullFallback(opts); fb != defaults {
+		t.Errorf("thresholds without RobustDecode = %+v, want whisper defaults %+v", fb, defaults)
+	}
+
+	var got DecodeOptions
+	speech := make([]float32, sampleRate)
+	preset := config.Preset{RobustDecode: true, EntropyThreshold: 2.8, LogProbThreshold: -0.5}
+	transcribeSamples(fakeEngine{text: "Hello", gotOpts: &got}, preset, "en", speech, nil, nil, nil)
+	if !got.RobustDecode || got.EntropyThreshold != 2.8 || got.LogProbThreshold != -0.5 {
+		t.Errorf("preset decode settings passed as %+v", got)
+	}
+}
+
+func TestDecodeContext(t *testing.T) {
+	// Without UseContext, and for any call outside a chunked run, whisper
+	// starts from a clean context.
+	for _, opts := range []DecodeOptions{{}, {UseContext: true}, (DecodeOptions{}).forChunk(1)} {
+		if !opts.noContext() {
+			t.Errorf("%+v: no_context = false, want true", opts)
+		}
+	}
+	opts := DecodeOptions{UseContext: true}
+	if !opts.forChunk(0).noContext() {
+		t.Error("UseContext, first chunk: no_context = false, want true (no context from the last recording)")
+	}
+	if opts.forChunk(1).noContext() || opts.forChunk(2).noContext() {
+		t.Error("UseContext, later chunk: no_context = true, want false")
+	}
+
+	var got DecodeOptions
+	transcribeSamples(fakeEngine{text: "Hello", gotOpts: &got}, config.Preset{UseContext: true}, "en", make([]float32, sampleRate), nil, nil, nil)
+	if !got.UseContext {
+		t.Errorf("preset useContext passed as %+v", got)
+	}
+}
+
+func TestDecodeTranslate(t *testing.T) {
+	speech := make([]float32, sampleRate)
+	for _, tt := range []struct {
+		model string
+		want  bool
+	}{
+		{"small", true},
+		{"large-v3-turbo-q5_0", true},
+		{"base.en", false}, // English-only models can't translate
+		{"distil-small.en", false},
+	} {
+		var got DecodeOptions
+		preset := config.Preset{ModelName: tt.model, Translate: true}
+		transcribeSamples(fakeEngine{text: "Hello", gotOpts: &got}, preset, "ru", speech, nil, nil, nil)
+		if got.Translate != tt.want {
+			t.Errorf("%s: Translate passed as %v, want %v", tt.model, got.Translate, tt.want)
+		}
+	}
+
+	// Translated output is English, so English hallucinations are filtered
+	// even for a Russian preset.
+	eng := fakeEngine{text: "Thanks for watching!"}
+	preset := config.Preset{ModelName: "small", Language: "ru", Translate: true}
+	if res := transcribeSamples(eng, preset, "ru", speech, nil, nil, nil); res.Text != "" {
+		t.Errorf("translated hallucination pasted: %q", res.Text)
+	}
+	preset.Translate = false
+	if res := transcribeSamples(eng, preset, "ru", speech, nil, nil, nil); res.Text == "" {
+		t.Error("without translate, a Russian preset should only filter Russian phrases")
+	}
+}
+
+func TestDecodeSampling(t *testing.T) {
+	tests := []struct {
+		beam int
+		want samplingParams
+	}{
+		{0, samplingParams{}},
+		{-1, samplingParams{}},
+		{1, samplingParams{BeamSearch: true, BeamSize: 1}},
+		{5, samplingParams{BeamSearch: true, BeamSize: 5}},
+		{50, samplingParams{BeamSearch: true, BeamSize: maxBeamSize}},
+	}
+	for _, tt := range tests {
+		if got := (DecodeOptions{BeamSize: tt.beam}).sampling(); got != tt.want {
+			t.Errorf("sampling(BeamSize=%d) = %+v, want %+v", tt.beam, got, tt.want)
+		}
+	}
+
+	var got DecodeOptions
+	speech := make([]float32, sampleRate)
+	transcribeSamples(fakeEngine{text: "Hello", gotOpts: &got}, config.Preset{BeamSize: 4}, "en", speech, nil, nil, nil)
+	if got.BeamSize != 4 {
+		t.Errorf("preset BeamSize was passed as %d, want 4", got.BeamSize)
+	}
+}
+
+func TestInferenceThreads(t *testing.T) {
+	tests := []struct {
+		requested, numCPU, want int
+	}{
+		{0, 4, 4},    // auto: all cores
+		{0, 32, 8},   // auto: capped at 8
+		{-3, 4, 4},   // negative treated as auto
+		{16, 32, 16}, // explicit, above the auto cap
+		{64, 12, 12}, // clamped to NumCPU
+		{1, 8, 1},
+		{0, 0, 1}, // bogus CPU count
+	}
+	for _, tt := range tests {
+		if got := inferenceThreads(tt.requested, tt.numCPU); got != tt.want {
+			t.Errorf("inferenceThreads(%d, %d) = %d, want %d", tt.requested, tt.numCPU, got, tt.want)
+		}
+	}
+}
+
+func TestEngineEvictions(t *testing.T) {
+	base := time.Unix(1000, 0)
+	lastUsed := map[string]time.Time{
+		"a": base.Add(3 * time.Second),
+		"b": base.Add(1 * time.Second),
+		"c": base.Add(2 * time.Second),
+		"d": base.Add(1 * time.Second), // ties with b
+	}
+	tests := []struct {
+		name string
+		busy map[string]bool
+		n    int
+		want []string
+	}{
+		{"oldest first", nil, 2, []string{"b", "d"}},
+		{"busy skipped", map[string]bool{"b": true}, 2, []string{"d", "c"}},
+		{"more than available", map[string]bool{"a": true, "c": true}, 5, []string{"b", "d"}},
+		{"none", nil, 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := engineEvictions(lastUsed, tt.busy, tt.n)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("engineEvictions(n=%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+// A downgraded engine must not stay cached under the preset, or the next
+// transcription would reuse the smaller model.
+func TestUnloadEngine(t *testing.T) {
+	downgraded, replaced := &WhisperEngine{}, &WhisperEngine{}
+	s := &PresetService{
+		engines:    map[string]*WhisperEngine{"p": downgraded},
+		engineUsed: map[string]time.Time{"p": time.Now()},
+	}
+	s.unloadEngine("p", downgraded)
+	if _, ok := s.engines["p"]; ok {
+		t.Error("downgraded engine still cached")
+	}
+	if _, ok := s.engineUsed["p"]; ok {
+		t.Error("downgraded engine still tracked for eviction")
+	}
+
+	s.engines["p"] = replaced
+	s.unloadEngine("p", downgraded)
+	if s.engines["p"] != replaced {
+		t.Error("unloadEngine removed an engine it didn't load")
+	}
+}
+
+func TestJoinSegmentTexts(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []string
+		preserve bool
+		want     string
+	}{
+		{"two segments", []string{" Hello there.", " General Kenobi."}, false, "Hello there. General Kenobi."},
+		{"empty segment skipped", []string{" One.", "", " Two."}, false, "One. Two."},
+		{"all empty", []string{"", " "}, true, ""},
+		{"leading space kept", []string{" One.", " Two."}, true, " One. Two."},
+		{"leading space from first non-empty", []string{"", " Two."}, true, " Two."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinSegmentTexts(tt.parts, tt.preserve); got != tt.want {
+				t.Errorf("joinSegmentTexts(%q, %v) = %q, want %q", tt.parts, tt.preserve, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewPostProcess(t *testing.T) {
+	s := &PresetService{
+		cfg: &config.AppConfig{Presets: []config.Preset{
+			{ID: "plain"},
+			{ID: "styled", AutoCapitalize: true, PreserveLeadingSpace: true},
+			{ID: "chat", PrependText: "> ", AppendText: `\n`},
+		}},
+		states: make(map[string]string),
+	}
+	tests := []struct {
+		name     string
+		presetID string
+		sample   string
+		want     string
+	}{
+		{"markers then spacing", "plain", "  [MUSIC] hello  world ", "hello  world"},
+		{"markers before hallucination filter", "plain", " [MUSIC] Thanks for watching!", ""},
+		{"leading space kept then capitalized", "styled", " hello there", " Hello there"},
+		{"only noise", "styled", "(laughter)", ""},
+		{"affixes last", "chat", " ship it", "> ship it\n"},
+		{"no affixes on empty result", "chat", "[BLANK_AUDIO]", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.PreviewPostProcess(tt.presetID, tt.sample)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PreviewPostProcess(%q, %q) = %q, want %q", tt.presetID, tt.sample, got, tt.want)
+			}
+		})
+	}
+	if _, err := s.PreviewPostProcess("missing", "text"); err == nil {
+		t.Error("PreviewPostProcess(missing) = nil error, want error")
+	}
+}
+
+func TestUnescapeAffix(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{" ", " "},
+		{`\n`, "\n"},
+		{`a\tb`, "a\tb"},
+		{`\\n`, `\n`},
+		{`\x`, `\x`},
+		{`end\`, `end\`},
+	}
+	for _, tt := range tests {
+		if got := unescapeAffix(tt.in); got != tt.want {
+			t.Errorf("unescapeAffix(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTranscribeBufferUnknownPreset(t *testing.T) {
+	s := &PresetService{cfg: &config.AppConfig{}, states: make(map[string]string)}
+	if _, err := s.TranscribeBuffer("missing", make([]float32, sampleRate)); err == nil {
+		t.Error("TranscribeBuffer with unknown preset = nil error")
+	}
+}
+
+func TestFindModelFile(t *testing.T) {
+	dir := t.TempDir()
+	// Another model on disk must not stand in for the requested one.
+	if err := os.WriteFile(filepath.Join(dir, "ggml-base.bin"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := findModelFile(dir, "base")
+	if err != nil || path != filepath.Join(dir, "ggml-base.bin") {
+		t.Errorf("findModelFile(base) = %q, %v", path, err)
+	}
+
+	_, err = findModelFile(dir, "large-v3")
+	if !errors.Is(err, ErrModelNotDownloaded) {
+		t.Fatalf("findModelFile(large-v3) err = %v, want ErrModelNotDownloaded", err)
+	}
+	var missing *ModelNotDownloadedError
+	if !errors.As(err, &missing) || missing.Model != "large-v3" {
+		t.Errorf("findModelFile(large-v3) err = %#v, want model large-v3", err)
+	}
+}
+
+func TestRetryLastTranscriptionGuards(t *testing.T) {
+	s := &PresetService{
+		cfg:     &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}},
+		states:  map[string]string{"a": "idle", "b": "idle"},
+		pending: map[string]int{},
+	}
+	if _, err := s.RetryLastTranscription("a"); err == nil {
+		t.Error("retry without a recording = nil error")
+	}
+
+	s.lastSamples = make([]float32, sampleRate)
+	s.states["b"] = "recording"
+	if _, err := s.RetryLastTranscription("a"); err == nil {
+		t.Error("retry while another preset records = nil error")
+	}
+	s.states["b"] = "idle"
+	s.pending["a"] = 1
+	if _, err := s.RetryLastTranscription("a"); err == nil {
+		t.Error("retry while the preset is transcribing = nil error")
+	}
+	if _, err := s.RetryLastTranscription("missing"); err == nil {
+		t.Error("retry with unknown preset = nil error")
+	}
+	if s.states["a"] != "idle" || s.states["b"] != "idle" || s.pending["a"] != 1 || len(s.jobs) != 0 {
+		t.Errorf("rejected retries changed states %v or queued %d jobs", s.states, len(s.jobs))
+	}
+}
+
+func TestTranscribeBufferSilent(t *testing.T) {
+	s := &PresetService{}
+	// Silence is rejected before any model is loaded (s has no engines).
+	res, err := s.transcribeBuffer(config.Preset{}, "en", make([]float32, sampleRate), nil, nil, nil)
+	if err != nil || res.Error != errNoAudio {
+		t.Errorf("silent recording = %+v, %v; want error %q", res, err, errNoAudio)
+	}
+	quiet := make([]float32, sampleRate)
+	quiet[100] = silentPeak / 2
+	if res, _ := s.transcribeBuffer(config.Preset{}, "en", quiet, nil, nil, nil); res.Error != errNoAudio {
+		t.Errorf("near-silent recording error = %q, want %q", res.Error, errNoAudio)
+	}
+
+	// The short-press guard comes first and is not an error.
+	if res, _ := s.transcribeBuffer(config.Preset{}, "en", make([]float32, minRecordSamples()-1), nil, nil, nil); res != (TranscriptionResult{TooShort: true}) {
+		t.Errorf("short recording = %+v, want TooShort", res)
+	}
+}
+
+func TestRetrySamples(t *testing.T) {
+	samples := make([]float32, 10*sampleRate)
+	if got := retrySamples(samples, 0); len(got) != len(samples) {
+		t.Errorf("unlimited: kept %d samples, want all", len(got))
+	}
+	if got := retrySamples(samples, 10*time.Second); len(got) != len(samples) {
+		t.Errorf("at the limit: kept %d samples, want all", len(got))
+	}
+	if got := retrySamples(samples, 5*time.Second); got != nil {
+		t.Errorf("over the limit: kept %d samples, want none", len(got))
+	}
+}
+
+func TestQueueRecording(t *testing.T) {
+	old := busyPollInterval
+	busyPollInterval = time.Millisecond
+	t.Cleanup(func() { busyPollInterval = old })
+
+	s := &PresetService{
+		cfg:     &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}},
+		states:  map[string]string{"a": "idle", "b": "idle"},
+		pending: map[string]int{"a": maxPendingTranscriptions},
+	}
+	if err := s.StartRecording("b"); !errors.Is(err, errBusy) {
+		t.Fatalf("StartRecording with the transcription queue full = %v, want errBusy", err)
+	}
+
+	// A cancelled queue entry never starts.
+	s.queueRecording("b")
+	if !s.unqueueRecording("b") || s.unqueueRecording("b") {
+		t.Error("unqueueRecording should report the queued preset exactly once")
+	}
+
+	// Once a transcription finishes the queued preset starts (here it fails
+	// on the missing audio device, which still clears the queue).
+	s.queueRecording("b")
+	time.Sleep(10 * busyPollInterval)
+	if got := fmt.Sprint(s.GetRecordingStates()); got != "[{a processing} {b queued}]" {
+		t.Errorf("states while queued = %s, want b queued", got)
+	}
+	s.mu.Lock()
+	if s.queuedID != "b" {
+		t.Errorf("queuedID = %q while transcribing, want b", s.queuedID)
+	}
+	s.pending["a"]--
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		queued := s.queuedID
+		s.mu.Unlock()
+		if queued == "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("queued recording did not start after transcription finished")
+		}
+		time.Sleep(busyPollInterval)
+	}
+}
+
+func TestSetPaused(t *testing.T) {
+	s := &PresetService{
+		cfg:    &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}},
+		states: map[string]string{"a": "idle", "b": "recording"},
+	}
+	var changes []bool
+	s.SetOnPauseChanged(func(paused bool) { changes = append(changes, paused) })
+
+	s.SetPaused(true)
+	s.SetPaused(true) // no change, no callback
+	if !s.ignoredWhilePaused("a") {
+		t.Error("idle preset should ignore hotkeys while paused")
+	}
+	if s.ignoredWhilePaused("b") {
+		t.Error("a recording in progress must still be able to stop while paused")
+	}
+
+	// The pause hotkey toggles the state back.
+	s.onHotkeyPress(pauseHotkeyID)
+	if s.IsPaused() || s.ignoredWhilePaused("a") {
+		t.Error("pause hotkey should resume hotkeys")
+	}
+	if fmt.Sprint(changes) != "[true false]" {
+		t.Errorf("pause callbacks = %v, want [true false]", changes)
+	}
+}
+
+func TestRepeatText(t *testing.T) {
+	s := &PresetService{states: map[string]string{"a": "idle"}}
+	if _, ok := s.repeatText(); ok {
+		t.Error("repeatText with no transcription yet = ok")
+	}
+	s.lastText = "hello"
+	if text, ok := s.repeatText(); !ok || text != "hello" {
+		t.Errorf("repeatText = %q, %v, want the last text", text, ok)
+	}
+	s.states["a"] = "recording"
+	if _, ok := s.repeatText(); ok {
+		t.Error("repeatText while recording = ok")
+	}
+}
+
+func TestModelLanguages(t *testing.T) {
+	all := []LanguageInfo{
+		{"auto", "Auto-detect"},
+		{"en", "English"},
+		{"de", "German"},
+		{"ru", "Russian"},
+		{"yue", "Cantonese"},
+	}
+
+	codes := func(langs []LanguageInfo) []string {
+		out := make([]string, len(langs))
+		for i, l := range langs {
+			out[i] = l.Code
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		eng  fakeEngine
+		want []string
+	}{
+		{"english only", fakeEngine{multilingual: false}, []string{"en"}},
+		{"subset", fakeEngine{multilingual: true, langs: []string{"ru", "en"}}, []string{"auto", "en", "ru"}},
+		{"no metadata", fakeEngine{multilingual: true}, []string{"auto", "en", "de", "ru", "yue"}},
+		{"unknown code ignored", fakeEngine{multilingual: true, langs: []string{"de", "xx"}}, []string{"auto", "de"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := codes(modelLanguages(tt.eng, all))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("modelLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckGPUDevice(t *testing.T) {
+	tests := []struct{ index, n, want int }{
+		{0, 2, 0},
+		{1, 2, 1},
+		{2, 2, 0}, // card removed
+		{-1, 2, 0},
+		{3, 0, 3}, // nothing detected: trust the setting
+	}
+	for _, tt := range tests {
+		if got := checkGPUDevice(tt.index, tt.n); got != tt.want {
+			t.Errorf("checkGPUDevice(%d, %d) = %d, want %d", tt.index, tt.n, got, tt.want)
+		}
+	}
+}
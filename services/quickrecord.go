@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// errNoQuickPreset is returned by ToggleQuickRecording when no preset is
+// marked primary or enabled.
+var errNoQuickPreset = errors.New("no primary or enabled preset to record with")
+
+// quickPresetID picks the preset the tray's quick record item uses: the first
+// one marked Primary, else the first enabled one; "" if there is neither.
+func quickPresetID(presets []config.Preset) string {
+	for _, p := range presets {
+		if p.Primary {
+			return p.ID
+		}
+	}
+	for _, p := range presets {
+		if p.Enabled {
+			return p.ID
+		}
+	}
+	return ""
+}
+
+// QuickRecordingState returns the recording state (as in GetRecordingStates)
+// of the quick record preset, or "" if there is none.
+func (s *PresetService) QuickRecordingState() string {
+	s.mu.Lock()
+	id := quickPresetID(s.cfg.Presets)
+	s.mu.Unlock()
+	if id == "" {
+		return ""
+	}
+	for _, st := range s.GetRecordingStates() {
+		if st.ID == id {
+			return st.State
+		}
+	}
+	return ""
+}
+
+// ToggleQuickRecording starts the quick record preset (see quickPresetID), or
+// stops and transcribes it if it is recording, like a toggle hotkey press. A
+// queued start is dropped instead.
+func (s *PresetService) ToggleQuickRecording() error {
+	s.mu.Lock()
+	id := quickPresetID(s.cfg.Presets)
+	s.mu.Unlock()
+	if id == "" {
+		return errNoQuickPreset
+	}
+
+	if s.QuickRecordingState() == "recording" {
+		result, err := s.StopRecording(id)
+		emitTranscriptionError(id, result)
+		return err
+	}
+	if s.unqueueRecording(id) {
+		log.Printf("Queued recording for preset %s cancelled", id)
+		return nil
+	}
+	return s.StartRecording(id)
+}
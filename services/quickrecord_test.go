@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestQuickPresetID(t *testing.T) {
+	tests := []struct {
+		name    string
+		presets []config.Preset
+		want    string
+	}{
+		{"none", nil, ""},
+		{"all disabled", []config.Preset{{ID: "a"}, {ID: "b"}}, ""},
+		{"first enabled", []config.Preset{{ID: "a"}, {ID: "b", Enabled: true}, {ID: "c", Enabled: true}}, "b"},
+		{"primary wins", []config.Preset{{ID: "a", Enabled: true}, {ID: "b", Primary: true}}, "b"},
+		{"first primary", []config.Preset{{ID: "a", Primary: true}, {ID: "b", Primary: true, Enabled: true}}, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quickPresetID(tt.presets); got != tt.want {
+				t.Errorf("quickPresetID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package services
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/emersion/go-autostart"
@@ -29,7 +33,9 @@ type LanguageInfo struct {
 	Name string `json:"name"`
 }
 
-// GlobalSettings holds non-preset settings.
+// GlobalSettings holds non-preset settings. GetGlobalSettings fills every
+// field; SaveGlobalSettings leaves the config value of a nil pointer field as
+// is, so callers that only know some of the settings can't reset the rest.
 type GlobalSettings struct {
 	MicrophoneID   string `json:"microphoneId"`
 	ModelsDir      string `json:"modelsDir"`
@@ -40,6 +46,32 @@ type GlobalSettings struct {
 	StartMinimized bool   `json:"startMinimized"`
 	Backend        string `json:"backend"`
 	OnboardingDone bool   `json:"onboardingDone"`
+
+	ModelBaseURL  *string `json:"modelBaseUrl,omitempty"`
+	MaxDownloads  *int    `json:"maxDownloads,omitempty"`
+	MaxPresets    *int    `json:"maxPresets,omitempty"`
+	LogDir        *string `json:"logDir,omitempty"`
+	OutputMode    *string `json:"outputMode,omitempty"`
+	SoundCues     *bool   `json:"soundCues,omitempty"`
+	Threads       *int    `json:"threads,omitempty"` // 0 = auto
+	LinuxPasteKey *string `json:"linuxPasteKey,omitempty"`
+	HotkeyBackend *string `json:"hotkeyBackend,omitempty"` // applies after restart
+	BusyBehavior  *string `json:"busyBehavior,omitempty"`  // "block" | "queue"
+
+	MaxRecordSeconds  *int  `json:"maxRecordSeconds,omitempty"`  // 0 = unlimited
+	MinRecordMs       *int  `json:"minRecordMs,omitempty"`       // shorter recordings are discarded
+	HistoryLimit      *int  `json:"historyLimit,omitempty"`      // 0 = history disabled
+	MaxLoadedEngines  *int  `json:"maxLoadedEngines,omitempty"`  // 0 = unlimited
+	GPUDeviceIndex    *int  `json:"gpuDeviceIndex,omitempty"`    // see ListGPUDevices
+	RestoreClipboard  *bool `json:"restoreClipboard,omitempty"`  // put the clipboard back after a paste
+	StripNoiseMarkers *bool `json:"stripNoiseMarkers,omitempty"` // false = strip only known markers, keep other [...]
+	WatchConfig       *bool `json:"watchConfig,omitempty"`       // apply external edits of config.json
+
+	HallucinationPhrases *[]string `json:"hallucinationPhrases,omitempty"` // empty = built-in lists
+	KeepShortOutput      *bool     `json:"keepShortOutput,omitempty"`      // keep "ok", "bye"...
+
+	PostCommand             *string `json:"postCommand,omitempty"` // run on each transcription, {text} = the text
+	PostCommandReplacesText *bool   `json:"postCommandReplacesText,omitempty"`
 }
 
 // onBackendChanged is called when the user changes the backend in Settings.
@@ -86,6 +118,32 @@ func (s *SettingsService) GetGlobalSettings() GlobalSettings {
 		StartMinimized: cfg.StartMinimized,
 		Backend:        backend,
 		OnboardingDone: cfg.OnboardingDone,
+
+		ModelBaseURL:  &cfg.ModelBaseURL,
+		MaxDownloads:  &cfg.MaxDownloads,
+		MaxPresets:    &cfg.MaxPresets,
+		LogDir:        &cfg.LogDir,
+		OutputMode:    &cfg.OutputMode,
+		SoundCues:     &cfg.SoundCues,
+		Threads:       &cfg.Threads,
+		LinuxPasteKey: &cfg.LinuxPasteKey,
+		HotkeyBackend: &cfg.HotkeyBackend,
+		BusyBehavior:  ptr(busyBehavior()),
+
+		MaxRecordSeconds:  ptr(int(recordLimit(cfg) / time.Second)),
+		MinRecordMs:       ptr(minRecordMs(cfg)),
+		HistoryLimit:      ptr(config.HistoryLimit(cfg)),
+		MaxLoadedEngines:  &cfg.MaxLoadedEngines,
+		GPUDeviceIndex:    &cfg.GPUDeviceIndex,
+		RestoreClipboard:  ptr(cfg.RestoreClipboard == nil || *cfg.RestoreClipboard),
+		StripNoiseMarkers: ptr(cfg.StripNoiseMarkers == nil || *cfg.StripNoiseMarkers),
+		WatchConfig:       ptr(watchConfigEnabled(cfg)),
+
+		HallucinationPhrases: &cfg.HallucinationPhrases,
+		KeepShortOutput:      &cfg.KeepShortOutput,
+
+		PostCommand:             &cfg.PostCommand,
+		PostCommandReplacesText: &cfg.PostCommandReplacesText,
 	}
 }
 
@@ -96,7 +154,9 @@ func (s *SettingsService) SaveGlobalSettings(gs GlobalSettings) error {
 		slog.Warn("failed to load config", "err", err)
 	}
 	autoStartChanged := cfg.AutoStart != gs.AutoStart
-	backendChanged := cfg.Backend != gs.Backend
+	// A different GPU also needs the loaded engines recreated.
+	backendChanged := cfg.Backend != gs.Backend ||
+		gs.GPUDeviceIndex != nil && cfg.GPUDeviceIndex != max(*gs.GPUDeviceIndex, 0)
 	cfg.MicrophoneID = gs.MicrophoneID
 	cfg.ModelsDir = gs.ModelsDir
 	cfg.Theme = gs.Theme
@@ -106,6 +166,58 @@ func (s *SettingsService) SaveGlobalSettings(gs GlobalSettings) error {
 	cfg.StartMinimized = gs.StartMinimized
 	cfg.Backend = gs.Backend
 	cfg.OnboardingDone = gs.OnboardingDone
+
+	if gs.ModelBaseURL != nil {
+		cfg.ModelBaseURL = strings.TrimSpace(*gs.ModelBaseURL)
+	}
+	setIfSent(&cfg.MaxDownloads, gs.MaxDownloads)
+	setIfSent(&cfg.MaxPresets, gs.MaxPresets)
+	if gs.LogDir != nil {
+		cfg.LogDir = strings.TrimSpace(*gs.LogDir)
+	}
+	setIfSent(&cfg.OutputMode, gs.OutputMode)
+	setIfSent(&cfg.SoundCues, gs.SoundCues)
+	if gs.Threads != nil {
+		cfg.Threads = max(*gs.Threads, 0)
+	}
+	setIfSent(&cfg.LinuxPasteKey, gs.LinuxPasteKey)
+	setIfSent(&cfg.HotkeyBackend, gs.HotkeyBackend)
+	setIfSent(&cfg.BusyBehavior, gs.BusyBehavior)
+
+	if gs.MaxRecordSeconds != nil {
+		cfg.MaxRecordSeconds = ptr(max(*gs.MaxRecordSeconds, 0))
+	}
+	if gs.MinRecordMs != nil {
+		cfg.MinRecordMs = max(*gs.MinRecordMs, 0)
+	}
+	if gs.HistoryLimit != nil {
+		cfg.HistoryLimit = ptr(max(*gs.HistoryLimit, 0))
+	}
+	if gs.MaxLoadedEngines != nil {
+		cfg.MaxLoadedEngines = max(*gs.MaxLoadedEngines, 0)
+	}
+	if gs.GPUDeviceIndex != nil {
+		cfg.GPUDeviceIndex = max(*gs.GPUDeviceIndex, 0)
+	}
+	if gs.RestoreClipboard != nil {
+		cfg.RestoreClipboard = ptr(*gs.RestoreClipboard)
+	}
+	if gs.StripNoiseMarkers != nil {
+		cfg.StripNoiseMarkers = ptr(*gs.StripNoiseMarkers)
+	}
+	if gs.WatchConfig != nil {
+		cfg.WatchConfig = ptr(*gs.WatchConfig)
+	}
+
+	if gs.HallucinationPhrases != nil {
+		cfg.HallucinationPhrases = cleanPhrases(*gs.HallucinationPhrases)
+	}
+	setIfSent(&cfg.KeepShortOutput, gs.KeepShortOutput)
+
+	if gs.PostCommand != nil {
+		cfg.PostCommand = strings.TrimSpace(*gs.PostCommand)
+	}
+	setIfSent(&cfg.PostCommandReplacesText, gs.PostCommandReplacesText)
 	if err := config.Save(cfg); err != nil {
 		return err
 	}
@@ -127,6 +239,17 @@ func (s *SettingsService) SaveGlobalSettings(gs GlobalSettings) error {
 	return nil
 }
 
+// ptr returns a pointer to a copy of v.
+func ptr[T any](v T) *T { return &v }
+
+// setIfSent copies a GlobalSettings field into the config unless it is nil
+// (not sent by the caller).
+func setIfSent[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
 // autostartApp returns the autostart.App descriptor for this application.
 func autostartApp() *autostart.App {
 	exe, _ := os.Executable()
@@ -138,6 +261,12 @@ func autostartApp() *autostart.App {
 	}
 }
 
+// ListGPUDevices returns the GPU devices of the loaded ggml backends for the
+// GPU picker; the index of the chosen one is saved as GPUDeviceIndex.
+func (s *SettingsService) ListGPUDevices() []GPUDevice {
+	return gpuDevices(ggmlGPUDevices())
+}
+
 // GetAllBackends returns all known compute backends with availability info.
 func (s *SettingsService) GetAllBackends() []BackendInfo {
 	return GetAllBackends()
@@ -150,6 +279,15 @@ func (s *SettingsService) InstallBackend(id string) (string, error) {
 	return installBackend(id)
 }
 
+// CancelBackendInstall stops a running InstallBackend of id: the runtime and
+// library downloads are aborted and their partial files deleted, and the
+// package-manager or installer child process is killed where the OS allows it
+// (not once it runs elevated). A final backend:install:progress event with
+// stage "cancelled" is emitted; false if id isn't being installed.
+func (s *SettingsService) CancelBackendInstall(id string) bool {
+	return cancelBackendInstall(id)
+}
+
 // RestartApp launches a new instance of the application and quits the current one.
 func (s *SettingsService) RestartApp() error {
 	exe, err := os.Executable()
@@ -208,6 +346,55 @@ func (s *SettingsService) GetMicrophones() ([]MicrophoneInfo, error) {
 	return result, nil
 }
 
+// Microphone test length: default and upper bound in seconds.
+const (
+	defaultMicTestSeconds = 2
+	maxMicTestSeconds     = 10
+)
+
+// TestMicrophone records from deviceID ("" = system default) for a few seconds
+// and returns the peak input level (0..1), so the UI can tell "we heard you"
+// from "silence detected". It uses its own capture device and leaves the
+// recording instance of PresetService alone. seconds <= 0 means 2s.
+func (s *SettingsService) TestMicrophone(deviceID string, seconds int) (float64, error) {
+	capture, err := NewAudioCapture()
+	if err != nil {
+		return 0, err
+	}
+	defer capture.Close()
+
+	capture.SetMicrophoneID(deviceID)
+	if err := capture.Start(); err != nil {
+		return 0, fmt.Errorf("start microphone: %w", err)
+	}
+	time.Sleep(micTestDuration(seconds))
+	samples := capture.Stop()
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("no audio received from the microphone")
+	}
+	return float64(peakLevel(samples)), nil
+}
+
+// micTestDuration resolves the requested test length: <= 0 → default, capped
+// at maxMicTestSeconds.
+func micTestDuration(seconds int) time.Duration {
+	if seconds <= 0 {
+		seconds = defaultMicTestSeconds
+	}
+	return time.Duration(min(seconds, maxMicTestSeconds)) * time.Second
+}
+
+// CheckPasteTools detects the Linux session type and paste tools again and
+// reports them, with a hint like "install wl-clipboard for Wayland" when
+// pasting can't work. Elsewhere it returns an empty status.
+func (s *SettingsService) CheckPasteTools() PasteToolsStatus {
+	if runtime.GOOS != "linux" {
+		return PasteToolsStatus{}
+	}
+	tc := refreshPasteTools()
+	return PasteToolsStatus{Session: tc.session, Clipboard: tc.clipboard, Keys: tc.keys, Hint: tc.hint()}
+}
+
 // SystemInfo provides diagnostic system information.
 type SystemInfo struct {
 	MicrophoneCount int           `json:"microphoneCount"`
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// The settings dialog and the onboarding wizard send only the settings they
+// show; saving them must not reset the others to their zero values.
+func TestSaveGlobalSettingsPartial(t *testing.T) {
+	useTempConfig(t)
+	if err := config.Save(&config.AppConfig{
+		Backend:              "auto",
+		LogDir:               "/var/log/morgottalk",
+		SoundCues:            true,
+		Threads:              4,
+		GPUDeviceIndex:       1,
+		BusyBehavior:         "queue",
+		MaxRecordSeconds:     ptr(60),
+		HistoryLimit:         ptr(20),
+		HallucinationPhrases: []string{"subscribe"},
+		PostCommand:          "notify-send {text}",
+		RestoreClipboard:     ptr(false),
+		StripNoiseMarkers:    ptr(false),
+		WatchConfig:          ptr(false),
+		Presets:              []config.Preset{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := &SettingsService{}
+
+	if err := s.SaveGlobalSettings(GlobalSettings{Theme: "dark", Backend: "auto"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Theme != "dark" {
+		t.Errorf("Theme = %q, want the sent value", cfg.Theme)
+	}
+	if cfg.MaxRecordSeconds == nil || *cfg.MaxRecordSeconds != 60 {
+		t.Errorf("MaxRecordSeconds = %v, want 60 kept", cfg.MaxRecordSeconds)
+	}
+	if config.HistoryLimit(cfg) != 20 {
+		t.Errorf("HistoryLimit = %d, want 20 kept", config.HistoryLimit(cfg))
+	}
+	if cfg.RestoreClipboard == nil || *cfg.RestoreClipboard {
+		t.Error("RestoreClipboard was reset, want false kept")
+	}
+	if cfg.StripNoiseMarkers == nil || *cfg.StripNoiseMarkers {
+		t.Error("StripNoiseMarkers was reset, want false kept")
+	}
+	if cfg.WatchConfig == nil || *cfg.WatchConfig {
+		t.Error("WatchConfig was reset, want false kept")
+	}
+	if !cfg.SoundCues || cfg.LogDir != "/var/log/morgottalk" || cfg.Threads != 4 || cfg.GPUDeviceIndex != 1 ||
+		cfg.BusyBehavior != "queue" || len(cfg.HallucinationPhrases) != 1 || cfg.PostCommand != "notify-send {text}" {
+		t.Errorf("settings not sent were reset: %+v", cfg)
+	}
+
+	// A full round trip saves what was changed, zero values included.
+	gs := s.GetGlobalSettings()
+	gs.MaxRecordSeconds = ptr(0)
+	gs.SoundCues = ptr(false)
+	gs.HistoryLimit = ptr(0)
+	if err := s.SaveGlobalSettings(gs); err != nil {
+		t.Fatal(err)
+	}
+	got := s.GetGlobalSettings()
+	if *got.MaxRecordSeconds != 0 {
+		t.Errorf("MaxRecordSeconds after round trip = %d, want 0 (unlimited)", *got.MaxRecordSeconds)
+	}
+	if *got.HistoryLimit != 0 {
+		t.Errorf("HistoryLimit after round trip = %d, want 0 (disabled)", *got.HistoryLimit)
+	}
+	if *got.SoundCues || *got.Threads != 4 {
+		t.Errorf("after round trip SoundCues = %v, Threads = %d; want false, 4", *got.SoundCues, *got.Threads)
+	}
+}
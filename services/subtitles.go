@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Segment is a transcribed span of audio with its timing.
+type Segment struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+	Words []Word        `json:"words,omitempty"`
+}
+
+// Word is a single word with timing from whisper's token-level timestamps.
+type Word struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// timedToken is one non-special whisper token with its timestamps.
+type timedToken struct {
+	Text       string
+	Start, End time.Duration
+}
+
+// tokensToWords merges subword tokens into words. Whisper's BPE tokens that start
+// a new word carry a leading space; continuation tokens don't.
+func tokensToWords(tokens []timedToken) []Word {
+	var words []Word
+	for _, tok := range tokens {
+		if strings.TrimSpace(tok.Text) == "" {
+			continue
+		}
+		if len(words) == 0 || strings.HasPrefix(tok.Text, " ") {
+			words = append(words, Word{Start: tok.Start, End: tok.End, Text: strings.TrimSpace(tok.Text)})
+			continue
+		}
+		last := &words[len(words)-1]
+		last.Text += tok.Text
+		last.End = tok.End
+	}
+	return words
+}
+
+// offsetSegments shifts segment and word timestamps by offset (in place).
+func offsetSegments(segs []Segment, offset time.Duration) []Segment {
+	for i := range segs {
+		segs[i].Start += offset
+		segs[i].End += offset
+		for j := range segs[i].Words {
+			segs[i].Words[j].Start += offset
+			segs[i].Words[j].End += offset
+		}
+	}
+	return segs
+}
+
+// segmentsText joins segment texts into plain text, as TranscribeLong would return.
+func segmentsText(segs []Segment) string {
+	parts := make([]string, 0, len(segs))
+	for _, seg := range segs {
+		parts = append(parts, seg.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatSRT renders segments as a SubRip subtitle file.
+func formatSRT(segs []Segment) string {
+	var b strings.Builder
+	for i, seg := range segs {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, srtTime(seg.Start), srtTime(seg.End), seg.Text)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// srtTime formats d as HH:MM:SS,mmm.
+func srtTime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
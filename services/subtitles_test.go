@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSRTTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "00:00:00,000"},
+		{1500 * time.Millisecond, "00:00:01,500"},
+		{61*time.Second + 20*time.Millisecond, "00:01:01,020"},
+		{2*time.Hour + 3*time.Minute + 4*time.Second + 5*time.Millisecond, "02:03:04,005"},
+		{-time.Second, "00:00:00,000"},
+	}
+	for _, tt := range tests {
+		if got := srtTime(tt.d); got != tt.want {
+			t.Errorf("srtTime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSRT(t *testing.T) {
+	segs := []Segment{
+		{Start: 0, End: 2 * time.Second, Text: "Hello there."},
+		{Start: 2500 * time.Millisecond, End: 4 * time.Second, Text: "General Kenobi."},
+	}
+	want := "1\n00:00:00,000 --> 00:00:02,000\nHello there.\n\n" +
+		"2\n00:00:02,500 --> 00:00:04,000\nGeneral Kenobi.\n"
+	if got := formatSRT(segs); got != want {
+		t.Errorf("formatSRT =\n%q\nwant\n%q", got, want)
+	}
+	if got := formatSRT(nil); got != "" {
+		t.Errorf("formatSRT(nil) = %q, want empty", got)
+	}
+}
+
+func TestOffsetSegments(t *testing.T) {
+	segs := []Segment{{
+		Start: time.Second, End: 2 * time.Second, Text: "hi",
+		Words: []Word{{Start: time.Second, End: 2 * time.Second, Text: "hi"}},
+	}}
+	got := offsetSegments(segs, 25*time.Second)
+	if got[0].Start != 26*time.Second || got[0].End != 27*time.Second {
+		t.Errorf("segment = %v..%v, want 26s..27s", got[0].Start, got[0].End)
+	}
+	if w := got[0].Words[0]; w.Start != 26*time.Second || w.End != 27*time.Second {
+		t.Errorf("word = %v..%v, want 26s..27s", w.Start, w.End)
+	}
+}
+
+func TestTokensToWords(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	tokens := []timedToken{
+		{Text: " Hel", Start: ms(0), End: ms(200)},
+		{Text: "lo", Start: ms(200), End: ms(400)},
+		{Text: " world", Start: ms(500), End: ms(900)},
+		{Text: ".", Start: ms(900), End: ms(950)},
+		{Text: " ", Start: ms(950), End: ms(960)},
+	}
+	words := tokensToWords(tokens)
+	if len(words) != 2 {
+		t.Fatalf("got %d words, want 2: %+v", len(words), words)
+	}
+	if words[0] != (Word{Start: ms(0), End: ms(400), Text: "Hello"}) {
+		t.Errorf("words[0] = %+v", words[0])
+	}
+	if words[1] != (Word{Start: ms(500), End: ms(950), Text: "world."}) {
+		t.Errorf("words[1] = %+v", words[1])
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+const (
+	// defaultTapSilence is how long a "tap" recording waits after the last
+	// speech before it stops by itself (preset TapSilenceMs overrides it).
+	defaultTapSilence = 1500 * time.Millisecond
+
+	// tapSpeechPeak is the input peak level (about -34 dBFS) counted as speech
+	// by the tap-mode silence detector; anything quieter is a pause.
+	tapSpeechPeak = 0.02
+)
+
+// silenceDetector ends a tap-mode recording: fed the input level callbacks,
+// it fires once when no speech has been heard for window after some was.
+// Before the first speech it never fires, so a slow start isn't cut off;
+// the recording limit still applies. It runs on the audio thread only.
+type silenceDetector struct {
+	window     time.Duration
+	heard      bool
+	lastSpeech time.Time
+	fired      bool
+}
+
+// update records one level reading taken at now and reports whether the
+// recording should stop. It returns true at most once.
+func (d *silenceDetector) update(level float32, now time.Time) bool {
+	if d.fired {
+		return false
+	}
+	if level >= tapSpeechPeak {
+		d.heard = true
+		d.lastSpeech = now
+		return false
+	}
+	if d.heard && now.Sub(d.lastSpeech) >= d.window {
+		d.fired = true
+		return true
+	}
+	return false
+}
+
+// tapSilenceWindow returns the pause after which a tap recording stops.
+func tapSilenceWindow(p config.Preset) time.Duration {
+	if p.TapSilenceMs > 0 {
+		return time.Duration(p.TapSilenceMs) * time.Millisecond
+	}
+	return defaultTapSilence
+}
+
+// tapRecordLimit returns the recording limit of a tap preset: its own
+// TapMaxSeconds, but never above the global limit (0 = unlimited).
+func tapRecordLimit(p config.Preset, global time.Duration) time.Duration {
+	if p.TapMaxSeconds <= 0 {
+		return global
+	}
+	limit := time.Duration(p.TapMaxSeconds) * time.Second
+	if global > 0 && global < limit {
+		return global
+	}
+	return limit
+}
+
+// tapAutoStop stops and transcribes a tap recording after a pause in speech.
+// A recording already stopped by a second press is left alone.
+func (s *PresetService) tapAutoStop(presetID string) {
+	log.Printf("Pause in speech, stopping tap recording for preset %s", presetID)
+	result, err := s.StopRecording(presetID)
+	if err != nil {
+		log.Printf("StopRecording failed: %v", err)
+	}
+	emitTranscriptionError(presetID, result)
+}
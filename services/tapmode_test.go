@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestSilenceDetector(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	d := &silenceDetector{window: time.Second}
+
+	steps := []struct {
+		ms    int
+		level float32
+		want  bool
+	}{
+		{0, 0.001, false},    // quiet before speaking
+		{3000, 0.001, false}, // a long wait before the first word never stops
+		{3050, 0.3, false},   // speech
+		{3500, 0.005, false}, // short pause
+		{3600, 0.2, false},   // speech again
+		{4500, 0.001, false}, // 900ms pause
+		{4600, 0.001, true},  // 1s pause: stop
+		{6000, 0.001, false}, // fires only once
+	}
+	for _, st := range steps {
+		if got := d.update(st.level, at(st.ms)); got != st.want {
+			t.Errorf("update(%v) at %dms = %v, want %v", st.level, st.ms, got, st.want)
+		}
+	}
+}
+
+func TestTapRecordLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		tapMax int
+		global time.Duration
+		want   time.Duration
+	}{
+		{"unset uses global", 0, 180 * time.Second, 180 * time.Second},
+		{"unset unlimited", 0, 0, 0},
+		{"below global", 30, 180 * time.Second, 30 * time.Second},
+		{"capped by global", 600, 180 * time.Second, 180 * time.Second},
+		{"global unlimited", 600, 0, 600 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := config.Preset{InputMode: "tap", TapMaxSeconds: tt.tapMax}
+			if got := tapRecordLimit(p, tt.global); got != tt.want {
+				t.Errorf("tapRecordLimit(%d, %v) = %v, want %v", tt.tapMax, tt.global, got, tt.want)
+			}
+		})
+	}
+	if got := tapSilenceWindow(config.Preset{}); got != defaultTapSilence {
+		t.Errorf("tapSilenceWindow default = %v, want %v", got, defaultTapSilence)
+	}
+	if got := tapSilenceWindow(config.Preset{TapSilenceMs: 800}); got != 800*time.Millisecond {
+		t.Errorf("tapSilenceWindow(800) = %v, want 800ms", got)
+	}
+}
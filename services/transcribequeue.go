@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+// maxPendingTranscriptions is how many recordings may wait for or be in
+// transcription at once; StartRecording returns errBusy beyond it, so a slow
+// backend can't pile up minutes of unprocessed audio.
+const maxPendingTranscriptions = 4
+
+// transcriptionJob is one stopped recording (or retry) waiting to be
+// transcribed and pasted.
+type transcriptionJob struct {
+	presetID string
+	run      func()
+	done     chan struct{} // closed once run has returned or the job was dropped
+}
+
+// errShutDown is returned by transcribeQueued for a job dropped by Shutdown.
+var errShutDown = errors.New("transcription cancelled: shutting down")
+
+// enqueueTranscription adds run to the transcription queue. A single worker
+// runs the jobs one at a time, oldest first, so texts are pasted in the order
+// the recordings were made while the next recording can already start.
+// presetID counts as "processing" until its job has run. The returned channel
+// is closed when the job is done, or dropped without running by Shutdown.
+func (s *PresetService) enqueueTranscription(presetID string, run func()) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := make(chan struct{})
+	if s.shutDown {
+		close(done)
+		return done
+	}
+	s.jobs = append(s.jobs, transcriptionJob{presetID: presetID, run: run, done: done})
+	s.pending[presetID]++
+	if len(s.jobs) > 1 {
+		log.Printf("Transcription for preset %s queued behind %d more", presetID, len(s.jobs)-1)
+	}
+	if !s.jobsRunning {
+		s.jobsRunning = true
+		go s.runTranscriptions()
+	}
+	return done
+}
+
+// runTranscriptions is the transcription worker: it drains the queue and
+// exits when it is empty; enqueueTranscription starts a new one as needed.
+func (s *PresetService) runTranscriptions() {
+	for {
+		s.mu.Lock()
+		if len(s.jobs) == 0 {
+			s.jobsRunning = false
+			s.mu.Unlock()
+			return
+		}
+		job := s.jobs[0]
+		s.jobs = s.jobs[1:]
+		s.mu.Unlock()
+
+		s.runTranscription(job)
+	}
+}
+
+// runTranscription runs one job and then takes it off the preset's pending
+// count, even if it panics.
+func (s *PresetService) runTranscription(job transcriptionJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("recovered panic in transcription for preset %s: %v", job.presetID, r)
+		}
+		s.mu.Lock()
+		s.jobDone(job)
+		s.mu.Unlock()
+	}()
+	job.run()
+}
+
+// jobDone takes job off its preset's pending count and closes its done
+// channel. Must be called with s.mu held.
+func (s *PresetService) jobDone(job transcriptionJob) {
+	if s.pending[job.presetID]--; s.pending[job.presetID] <= 0 {
+		delete(s.pending, job.presetID)
+	}
+	close(job.done)
+}
+
+// dropTranscriptions drops the queued jobs without running them, releasing
+// their transcribeQueued callers. Must be called with s.mu held.
+func (s *PresetService) dropTranscriptions() {
+	if len(s.jobs) > 0 {
+		log.Printf("Dropping %d queued transcription(s)", len(s.jobs))
+	}
+	for _, job := range s.jobs {
+		s.jobDone(job)
+	}
+	s.jobs = nil
+}
+
+// pendingTranscriptions returns the number of queued and running
+// transcriptions. Must be called with s.mu held.
+func (s *PresetService) pendingTranscriptions() int {
+	n := 0
+	for _, c := range s.pending {
+		n += c
+	}
+	return n
+}
+
+// transcribeQueued runs job in the transcription queue and waits for its
+// result; errShutDown if Shutdown dropped it.
+func (s *PresetService) transcribeQueued(presetID string, job func() (TranscriptionResult, error)) (TranscriptionResult, error) {
+	var (
+		res TranscriptionResult
+		err error
+		ran bool
+	)
+	<-s.enqueueTranscription(presetID, func() {
+		ran = true
+		res, err = job()
+	})
+	if !ran {
+		return TranscriptionResult{}, errShutDown
+	}
+	return res, err
+}
+
+// recordingIdlePoll is how often waitRecordingIdle checks the recording slot.
+const recordingIdlePoll = 50 * time.Millisecond
+
+// waitRecordingIdle blocks a finished job until no recording is in progress,
+// so its paste doesn't land in the middle of a newer dictation (repeatText
+// refuses for the same reason). It returns at once after Shutdown.
+func (s *PresetService) waitRecordingIdle() {
+	logged := false
+	for {
+		s.mu.Lock()
+		id, shutDown := s.recordingID, s.shutDown
+		s.mu.Unlock()
+		if id == "" || shutDown {
+			return
+		}
+		if !logged {
+			log.Printf("Transcription finished during recording %s; pasting once it stops", id)
+			logged = true
+		}
+		time.Sleep(recordingIdlePoll)
+	}
+}
+
+// showOverlayUnlessRecording shows the overlay for a queued job as it starts,
+// unless a newer recording shows it.
+func (s *PresetService) showOverlayUnlessRecording(state string, p config.Preset, lang string) {
+	s.mu.Lock()
+	recording := s.recordingID != ""
+	s.mu.Unlock()
+	if !recording {
+		showOverlay(state, p, lang)
+	}
+}
+
+// hideOverlayUnlessRecording hides the "processing" overlay, e.g. before a
+// paste, unless a newer recording shows the overlay now.
+func (s *PresetService) hideOverlayUnlessRecording() {
+	s.mu.Lock()
+	recording := s.recordingID != ""
+	s.mu.Unlock()
+	if !recording {
+		hideOverlay()
+	}
+}
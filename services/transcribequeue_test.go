@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestTranscriptionQueue(t *testing.T) {
+	s := &PresetService{
+		cfg:     &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}},
+		states:  map[string]string{"a": "idle", "b": "idle"},
+		pending: map[string]int{},
+	}
+
+	release := make(chan struct{})
+	var order []string
+	s.enqueueTranscription("a", func() { <-release; order = append(order, "a1") })
+	s.enqueueTranscription("b", func() { order = append(order, "b1") })
+	s.enqueueTranscription("a", func() { panic("boom") })
+	s.enqueueTranscription("a", func() { order = append(order, "a2") })
+
+	if got := fmt.Sprint(s.GetRecordingStates()); got != "[{a processing} {b processing}]" {
+		t.Errorf("states with jobs queued = %s, want both processing", got)
+	}
+	s.mu.Lock()
+	s.states["b"] = "recording" // the next recording doesn't wait for the queue
+	pending := s.pendingTranscriptions()
+	s.mu.Unlock()
+	if pending != 4 {
+		t.Errorf("pendingTranscriptions = %d, want 4", pending)
+	}
+	if got := fmt.Sprint(s.GetRecordingStates()); got != "[{a processing} {b recording}]" {
+		t.Errorf("states while recording = %s, want b recording", got)
+	}
+	s.mu.Lock()
+	s.states["b"] = "idle"
+	s.mu.Unlock()
+
+	// A job queued last runs last; transcribeQueued waits for it, and a
+	// panicking job doesn't stop the worker.
+	close(release)
+	res, err := s.transcribeQueued("b", func() (TranscriptionResult, error) {
+		order = append(order, "b2")
+		return TranscriptionResult{Text: "done"}, nil
+	})
+	if err != nil || res.Text != "done" {
+		t.Errorf("transcribeQueued = %+v, %v; want the job's result", res, err)
+	}
+	if got := fmt.Sprint(order); got != "[a1 b1 a2 b2]" {
+		t.Errorf("jobs ran in order %s, want [a1 b1 a2 b2]", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		pending, running := s.pendingTranscriptions(), s.jobsRunning
+		s.mu.Unlock()
+		if pending == 0 && !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queue not drained: %d pending, worker running %v", pending, running)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := fmt.Sprint(s.GetRecordingStates()); got != "[{a idle} {b idle}]" {
+		t.Errorf("states after the queue drained = %s, want idle", got)
+	}
+}
+
+func TestTranscriptionQueueShutdown(t *testing.T) {
+	s := &PresetService{
+		cfg:     &config.AppConfig{Presets: []config.Preset{{ID: "a", Name: "A"}}},
+		states:  map[string]string{"a": "idle"},
+		pending: map[string]int{},
+	}
+	release := make(chan struct{})
+	defer close(release)
+	s.enqueueTranscription("a", func() { <-release })
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := s.transcribeQueued("a", func() (TranscriptionResult, error) {
+			t.Error("dropped job ran")
+			return TranscriptionResult{}, nil
+		})
+		errc <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		queued, pending := len(s.jobs), s.pending["a"]
+		s.mu.Unlock()
+		if queued == 1 && pending == 2 { // one running, one queued
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Shutdown releases the caller of a queued job; a later one isn't queued.
+	s.Shutdown()
+	select {
+	case err := <-errc:
+		if err != errShutDown {
+			t.Errorf("transcribeQueued after Shutdown = %v, want errShutDown", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("transcribeQueued still blocked after Shutdown")
+	}
+	if _, err := s.transcribeQueued("a", func() (TranscriptionResult, error) { return TranscriptionResult{}, nil }); err != errShutDown {
+		t.Errorf("transcribeQueued once shut down = %v, want errShutDown", err)
+	}
+	s.mu.Lock()
+	pending := s.pending["a"]
+	s.mu.Unlock()
+	if pending != 1 {
+		t.Errorf("pending = %d, want 1 (the running job)", pending)
+	}
+}
+
+func TestWaitRecordingIdle(t *testing.T) {
+	s := &PresetService{recordingID: "b"}
+	done := make(chan struct{})
+	go func() {
+		s.waitRecordingIdle()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("waitRecordingIdle returned during a recording")
+	case <-time.After(3 * recordingIdlePoll):
+	}
+	s.mu.Lock()
+	s.recordingID = ""
+	s.mu.Unlock()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waitRecordingIdle still blocked once the recording stopped")
+	}
+}
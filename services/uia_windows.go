@@ -0,0 +1,319 @@
+//go:build windows
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// UI Automation text insertion via raw COM vtable calls (no cgo, no COM wrapper lib).
+// The focused control's ValuePattern is used to write the new value; TextPattern,
+// when available, tells us where the caret/selection is so the text is spliced in
+// at that point instead of appended.
+
+var (
+	ole32    = syscall.NewLazyDLL("ole32.dll")
+	oleaut32 = syscall.NewLazyDLL("oleaut32.dll")
+
+	pCoInitializeEx    = ole32.NewProc("CoInitializeEx")
+	pCoUninitialize    = ole32.NewProc("CoUninitialize")
+	pCoCreateInstance  = ole32.NewProc("CoCreateInstance")
+	pSysAllocStringLen = oleaut32.NewProc("SysAllocStringLen")
+	pSysFreeString     = oleaut32.NewProc("SysFreeString")
+	pSysStringLen      = oleaut32.NewProc("SysStringLen")
+)
+
+var (
+	clsidCUIAutomation = syscall.GUID{Data1: 0xFF48DBA4, Data2: 0x60EF, Data3: 0x4201,
+		Data4: [8]byte{0xAA, 0x87, 0x54, 0x10, 0x3E, 0xEF, 0x59, 0x4E}}
+	iidIUIAutomation = syscall.GUID{Data1: 0x30CBE57D, Data2: 0xD9D0, Data3: 0x452A,
+		Data4: [8]byte{0xAB, 0x13, 0x7A, 0xC5, 0xAC, 0x48, 0x25, 0xEE}}
+)
+
+const (
+	coinitApartmentThreaded = 0x2
+	clsctxInprocServer      = 0x1
+	rpcEChangedMode         = 0x80010106
+
+	uiaValuePatternID = 10002
+	uiaTextPatternID  = 10014
+
+	endpointStart = 0
+	endpointEnd   = 1
+	unitCharacter = 0
+)
+
+// Vtable indices (IUnknown occupies 0..2), from UIAutomationClient.h.
+const (
+	vtRelease = 2
+
+	vtAutomationGetFocusedElement = 8 // IUIAutomation
+
+	vtElementGetCurrentPattern = 16 // IUIAutomationElement
+
+	vtValueSetValue             = 3 // IUIAutomationValuePattern
+	vtValueGetCurrentValue      = 4
+	vtValueGetCurrentIsReadOnly = 5
+
+	vtTextGetSelection     = 5 // IUIAutomationTextPattern
+	vtTextGetDocumentRange = 7
+
+	vtRangeArrayGetLength  = 3 // IUIAutomationTextRangeArray
+	vtRangeArrayGetElement = 4
+
+	vtRangeGetText             = 12 // IUIAutomationTextRange
+	vtRangeMoveEndpointByUnit  = 14
+	vtRangeMoveEndpointByRange = 15
+	vtRangeSelect              = 16
+)
+
+var errUIANoTextPattern = errors.New("no TextPattern")
+
+func comCall(obj uintptr, method int, args ...uintptr) int32 {
+	vtbl := *(*uintptr)(unsafe.Pointer(obj))
+	fn := *(*uintptr)(unsafe.Pointer(vtbl + uintptr(method)*unsafe.Sizeof(uintptr(0))))
+	ret, _, _ := syscall.SyscallN(fn, append([]uintptr{obj}, args...)...)
+	return int32(ret)
+}
+
+func comRelease(obj uintptr) {
+	if obj != 0 {
+		comCall(obj, vtRelease)
+	}
+}
+
+// bstrTake copies a BSTR into a UTF-16 slice and frees it.
+func bstrTake(b uintptr) []uint16 {
+	if b == 0 {
+		return nil
+	}
+	n, _, _ := pSysStringLen.Call(b)
+	s := make([]uint16, n)
+	copy(s, unsafe.Slice((*uint16)(unsafe.Pointer(b)), n))
+	pSysFreeString.Call(b)
+	return s
+}
+
+func bstrAlloc(s []uint16) uintptr {
+	if len(s) == 0 {
+		b, _, _ := pSysAllocStringLen.Call(0, 0)
+		return b
+	}
+	b, _, _ := pSysAllocStringLen.Call(uintptr(unsafe.Pointer(&s[0])), uintptr(len(s)))
+	return b
+}
+
+// withUIAFocus runs fn with the focused UI Automation element on a COM-initialized,
+// OS-locked thread. The element is released after fn returns.
+func withUIAFocus(fn func(elem uintptr) error) error {
+	// COM objects are apartment-bound: keep every call on this OS thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hr, _, _ := pCoInitializeEx.Call(0, coinitApartmentThreaded)
+	if int32(hr) >= 0 {
+		defer pCoUninitialize.Call()
+	} else if hr != rpcEChangedMode {
+		return fmt.Errorf("CoInitializeEx: %#x", uint32(hr))
+	}
+
+	var uia uintptr
+	hr, _, _ = pCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(&clsidCUIAutomation)), 0, clsctxInprocServer,
+		uintptr(unsafe.Pointer(&iidIUIAutomation)), uintptr(unsafe.Pointer(&uia)))
+	if int32(hr) < 0 || uia == 0 {
+		return fmt.Errorf("CoCreateInstance(CUIAutomation): %#x", uint32(hr))
+	}
+	defer comRelease(uia)
+
+	var elem uintptr
+	if hr := comCall(uia, vtAutomationGetFocusedElement, uintptr(unsafe.Pointer(&elem))); hr < 0 || elem == 0 {
+		return fmt.Errorf("no focused element: %#x", uint32(hr))
+	}
+	defer comRelease(elem)
+
+	return fn(elem)
+}
+
+// winUIAInsert inserts text at the caret of the focused control using UI Automation.
+// Returns an error when the control does not expose a writable ValuePattern or the
+// caret position cannot be resolved — the caller then falls back to SendInput.
+func winUIAInsert(text string) error {
+	ins, err := syscall.UTF16FromString(text)
+	if err != nil {
+		return fmt.Errorf("UTF16 conversion: %w", err)
+	}
+	ins = ins[:len(ins)-1] // drop NUL terminator
+
+	return withUIAFocus(func(elem uintptr) error {
+		var vp uintptr
+		if hr := comCall(elem, vtElementGetCurrentPattern, uiaValuePatternID, uintptr(unsafe.Pointer(&vp))); hr < 0 || vp == 0 {
+			return fmt.Errorf("focused control has no ValuePattern")
+		}
+		defer comRelease(vp)
+
+		var readOnly int32
+		comCall(vp, vtValueGetCurrentIsReadOnly, uintptr(unsafe.Pointer(&readOnly)))
+		if readOnly != 0 {
+			return fmt.Errorf("focused control is read-only")
+		}
+
+		var b uintptr
+		if hr := comCall(vp, vtValueGetCurrentValue, uintptr(unsafe.Pointer(&b))); hr < 0 {
+			return fmt.Errorf("ValuePattern.CurrentValue: %#x", uint32(hr))
+		}
+		value := bstrTake(b)
+
+		// Without TextPattern there is no caret info — append to the end.
+		start, end := len(value), len(value)
+		if s, e, err := uiaSelection(elem, value); err == nil {
+			start, end = s, e
+		} else if !errors.Is(err, errUIANoTextPattern) {
+			return err
+		}
+
+		newValue := make([]uint16, 0, len(value)-(end-start)+len(ins))
+		newValue = append(newValue, value[:start]...)
+		newValue = append(newValue, ins...)
+		newValue = append(newValue, value[end:]...)
+
+		bs := bstrAlloc(newValue)
+		defer pSysFreeString.Call(bs)
+		if hr := comCall(vp, vtValueSetValue, bs); hr < 0 {
+			return fmt.Errorf("ValuePattern.SetValue: %#x", uint32(hr))
+		}
+
+		uiaPlaceCaret(elem, start+len(ins))
+		return nil
+	})
+}
+
+// winPrecedingText returns up to maxChars characters before the caret of the
+// focused control, read through its TextPattern selection.
+func winPrecedingText(maxChars int) (string, error) {
+	var text string
+	err := withUIAFocus(func(elem uintptr) error {
+		var tp uintptr
+		if hr := comCall(elem, vtElementGetCurrentPattern, uiaTextPatternID, uintptr(unsafe.Pointer(&tp))); hr < 0 || tp == 0 {
+			return errUIANoTextPattern
+		}
+		defer comRelease(tp)
+
+		sel, err := uiaFirstSelection(tp)
+		if err != nil {
+			return err
+		}
+		defer comRelease(sel)
+
+		// Collapse the selection to its start, then extend backwards by maxChars.
+		comCall(sel, vtRangeMoveEndpointByRange, endpointEnd, sel, endpointStart)
+		var moved int32
+		comCall(sel, vtRangeMoveEndpointByUnit, endpointStart, unitCharacter, uintptr(-maxChars), uintptr(unsafe.Pointer(&moved)))
+
+		var b uintptr
+		if hr := comCall(sel, vtRangeGetText, ^uintptr(0), uintptr(unsafe.Pointer(&b))); hr < 0 {
+			return fmt.Errorf("TextRange.GetText: %#x", uint32(hr))
+		}
+		text = syscall.UTF16ToString(bstrTake(b))
+		return nil
+	})
+	return text, err
+}
+
+// uiaFirstSelection returns the first selected range of a TextPattern (caller releases).
+func uiaFirstSelection(tp uintptr) (uintptr, error) {
+	var arr uintptr
+	if hr := comCall(tp, vtTextGetSelection, uintptr(unsafe.Pointer(&arr))); hr < 0 || arr == 0 {
+		return 0, fmt.Errorf("TextPattern.GetSelection: %#x", uint32(hr))
+	}
+	defer comRelease(arr)
+
+	var n int32
+	comCall(arr, vtRangeArrayGetLength, uintptr(unsafe.Pointer(&n)))
+	if n < 1 {
+		return 0, fmt.Errorf("no selection in focused control")
+	}
+	var sel uintptr
+	if hr := comCall(arr, vtRangeArrayGetElement, 0, uintptr(unsafe.Pointer(&sel))); hr < 0 || sel == 0 {
+		return 0, fmt.Errorf("TextRangeArray.GetElement: %#x", uint32(hr))
+	}
+	return sel, nil
+}
+
+// uiaSelection returns the selection of the focused control as UTF-16 offsets into value.
+// The text before the selection is read by shrinking the document range to end where
+// the selection starts; if that prefix doesn't match value (e.g. rich text with
+// embedded objects) the offsets can't be trusted and an error is returned.
+func uiaSelection(elem uintptr, value []uint16) (int, int, error) {
+	var tp uintptr
+	if hr := comCall(elem, vtElementGetCurrentPattern, uiaTextPatternID, uintptr(unsafe.Pointer(&tp))); hr < 0 || tp == 0 {
+		return 0, 0, errUIANoTextPattern
+	}
+	defer comRelease(tp)
+
+	sel, err := uiaFirstSelection(tp)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer comRelease(sel)
+
+	var doc uintptr
+	if hr := comCall(tp, vtTextGetDocumentRange, uintptr(unsafe.Pointer(&doc))); hr < 0 || doc == 0 {
+		return 0, 0, fmt.Errorf("TextPattern.DocumentRange: %#x", uint32(hr))
+	}
+	defer comRelease(doc)
+
+	if hr := comCall(doc, vtRangeMoveEndpointByRange, endpointEnd, sel, endpointStart); hr < 0 {
+		return 0, 0, fmt.Errorf("TextRange.MoveEndpointByRange: %#x", uint32(hr))
+	}
+
+	var b uintptr
+	comCall(doc, vtRangeGetText, ^uintptr(0), uintptr(unsafe.Pointer(&b))) // maxLength -1 = all
+	prefix := bstrTake(b)
+	b = 0
+	comCall(sel, vtRangeGetText, ^uintptr(0), uintptr(unsafe.Pointer(&b)))
+	selected := bstrTake(b)
+
+	start, end := len(prefix), len(prefix)+len(selected)
+	if end > len(value) || !utf16Equal(value[:start], prefix) || !utf16Equal(value[start:end], selected) {
+		return 0, 0, fmt.Errorf("caret position does not map onto control value")
+	}
+	return start, end, nil
+}
+
+// uiaPlaceCaret moves the caret to offset after SetValue (which usually resets it).
+// Best effort: failures are ignored since the text is already inserted.
+func uiaPlaceCaret(elem uintptr, offset int) {
+	var tp uintptr
+	if hr := comCall(elem, vtElementGetCurrentPattern, uiaTextPatternID, uintptr(unsafe.Pointer(&tp))); hr < 0 || tp == 0 {
+		return
+	}
+	defer comRelease(tp)
+
+	var doc uintptr
+	if hr := comCall(tp, vtTextGetDocumentRange, uintptr(unsafe.Pointer(&doc))); hr < 0 || doc == 0 {
+		return
+	}
+	defer comRelease(doc)
+
+	var moved int32
+	comCall(doc, vtRangeMoveEndpointByUnit, endpointStart, unitCharacter, uintptr(offset), uintptr(unsafe.Pointer(&moved)))
+	comCall(doc, vtRangeMoveEndpointByRange, endpointEnd, doc, endpointStart) // collapse to start
+	comCall(doc, vtRangeSelect)
+}
+
+func utf16Equal(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
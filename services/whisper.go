@@ -7,14 +7,19 @@ package services
 */
 import "C"
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
 	"unsafe"
 )
 
@@ -47,29 +52,78 @@ func loadBackendDLL(dllPath string) bool {
 	return reg != nil
 }
 
+// ggmlDevice is a GPU device of a loaded ggml backend.
+type ggmlDevice struct {
+	Index       int    // position among the GPU devices: whisper's gpu_device
+	Backend     string // ggml backend name, e.g. "CUDA", "Vulkan", "ROCm"
+	Name        string // device name, e.g. "CUDA0"
+	Description string // e.g. "NVIDIA GeForce RTX 3060"
+}
+
+// ggmlGPUDevices lists the GPU devices of the loaded ggml backends, in the
+// order whisper_init counts them for gpu_device.
+func ggmlGPUDevices() []ggmlDevice {
+	loadGGMLBackends()
+	var devs []ggmlDevice
+	for i := C.size_t(0); i < C.ggml_backend_dev_count(); i++ {
+		dev := C.ggml_backend_dev_get(i)
+		if C.ggml_backend_dev_type(dev) != C.GGML_BACKEND_DEVICE_TYPE_GPU {
+			continue
+		}
+		devs = append(devs, ggmlDevice{
+			Index:       len(devs),
+			Backend:     C.GoString(C.ggml_backend_reg_name(C.ggml_backend_dev_backend_reg(dev))),
+			Name:        C.GoString(C.ggml_backend_dev_name(dev)),
+			Description: C.GoString(C.ggml_backend_dev_description(dev)),
+		})
+	}
+	return devs
+}
+
 // WhisperEngine wraps a whisper.cpp model context.
 type WhisperEngine struct {
 	ctx *C.struct_whisper_context
 	mu  sync.Mutex
+
+	threads atomic.Int32 // requested inference threads, 0 = auto (see inferenceThreads)
+}
+
+// errModelInit is returned when whisper.cpp fails to create a context for a
+// file that passed checkGGMLFile. It logs the cause to stderr only; for a
+// valid model file that is almost always a failed buffer allocation (RAM or
+// VRAM).
+var errModelInit = errors.New("failed to load whisper model")
+
+// isOOMError reports whether a model load error looks like running out of
+// memory: whisper.cpp failed to init a file that is a GGML model.
+func isOOMError(err error) bool {
+	return errors.Is(err, errModelInit)
 }
 
 // NewWhisperEngine loads a GGML model file and returns an engine ready for transcription.
-// backend: "auto", "cpu", "cuda", "vulkan", "metal".
-func NewWhisperEngine(modelPath string, backend string) (*WhisperEngine, error) {
+// backend: "auto", "cpu", "cuda", "vulkan", "metal". gpuDevice picks the GPU
+// on multi-GPU machines (0 = first); it is ignored on the CPU.
+func NewWhisperEngine(modelPath string, backend string, gpuDevice int) (*WhisperEngine, error) {
+	// A missing or foreign file also makes whisper_init fail; report it as
+	// such rather than as errModelInit.
+	if err := checkGGMLFile(modelPath); err != nil {
+		return nil, err
+	}
 	loadGGMLBackends()
 
 	cPath := C.CString(modelPath)
 	defer C.free(unsafe.Pointer(cPath))
 
 	useGPU := backendUseGPU(backend)
-	log.Printf("NewWhisperEngine: use_gpu=%v, initializing model...", useGPU)
+	log.Printf("NewWhisperEngine: use_gpu=%v, gpu_device=%d, initializing model...", useGPU, gpuDevice)
 	params := C.whisper_context_default_params()
 	params.use_gpu = C.bool(useGPU)
+	params.gpu_device = C.int(gpuDevice)
 	// flash_attn disabled: padding calculation depends on GGML_USE_CUDA/METAL compile flags.
 	params.flash_attn = C.bool(false)
 	ctx := C.whisper_init_from_file_with_params(cPath, params)
 	if ctx == nil {
-		return nil, fmt.Errorf("failed to load whisper model: %s", modelPath)
+		return nil, fmt.Errorf("%w: %s", errModelInit, modelPath)
 	}
 
 	log.Println("NewWhisperEngine: model initialized successfully")
@@ -81,6 +135,28 @@ func (w *WhisperEngine) IsMultilingual() bool {
 	return C.whisper_is_multilingual(w.ctx) != 0
 }
 
+// SupportedLanguages returns the language codes the loaded model has tokens for.
+// Language tokens sit between <|startoftranscript|> and <|translate|> in the
+// vocabulary, so fine-tuned or older checkpoints with fewer language tokens
+// report only the languages they can actually handle.
+func (w *WhisperEngine) SupportedLanguages() []string {
+	if w.ctx == nil {
+		return nil
+	}
+	if C.whisper_is_multilingual(w.ctx) == 0 {
+		return []string{"en"}
+	}
+	n := int(C.whisper_token_translate(w.ctx)-C.whisper_token_sot(w.ctx)) - 1
+	if maxLangs := int(C.whisper_lang_max_id()) + 1; n > maxLangs {
+		n = maxLangs
+	}
+	langs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		langs = append(langs, C.GoString(C.whisper_lang_str(C.int(i))))
+	}
+	return langs
+}
+
 // WhisperLanguages returns all languages supported by whisper.cpp library.
 // First entry is always {"auto", "Auto-detect"}.
 func WhisperLanguages() []LanguageInfo {
@@ -102,12 +178,126 @@ func WhisperLanguages() []LanguageInfo {
 	return langs
 }
 
+// DecodeOptions are per-call decoding settings passed down to whisper_full.
+type DecodeOptions struct {
+	Translate    bool // translate to English
+	RobustDecode bool // apply the fallback triggers below to whisper's temperature fallback
+	BeamSize     int  // 0 = greedy sampling, >0 = beam search of that width
+
+	// Fallback triggers, used with RobustDecode; 0 = whisper's default.
+	EntropyThreshold float32 // retry if token entropy is below (repetitive output)
+	LogProbThreshold float32 // retry if average token log probability is below
+
+	// KeepUnknownMarkers strips only the noise markers whisper is known to
+	// emit and keeps other [...] text (stripNoiseMarkers setting off).
+	KeepUnknownMarkers bool
+
+	// UseContext decodes each chunk of a long recording with the text of the
+	// previous chunk as prompt. Set per chunk by forChunk, so context never
+	// carries over between recordings.
+	UseContext      bool
+	continueContext bool
+}
+
+// forChunk returns o for chunk n (0-based) of one TranscribeLong run: with
+// UseContext, chunks after the first continue the decoder context of the
+// chunk before.
+func (o DecodeOptions) forChunk(n int) DecodeOptions {
+	o.continueContext = o.UseContext && n > 0
+	return o
+}
+
+// noContext is whisper's no_context parameter for o: start without the
+// previous call's text as prompt. False only for a chunk after the first of a
+// UseContext run; whisper clears the context on every other call, so the
+// first chunk of a recording never sees the last one of another.
+func (o DecodeOptions) noContext() bool {
+	return !o.continueContext
+}
+
+// maxBeamSize bounds BeamSize; wider beams cost memory and time for little gain.
+const maxBeamSize = 8
+
+// samplingParams selects the whisper sampling strategy.
+type samplingParams struct {
+	BeamSearch bool
+	BeamSize   int // beam width when BeamSearch
+}
+
+// sampling returns the sampling strategy for o: greedy unless BeamSize > 0,
+// in which case beam search with the width clamped to maxBeamSize.
+func (o DecodeOptions) sampling() samplingParams {
+	if o.BeamSize <= 0 {
+		return samplingParams{}
+	}
+	return samplingParams{BeamSearch: true, BeamSize: min(o.BeamSize, maxBeamSize)}
+}
+
+// fallbackParams mirrors the whisper_full_params fields behind temperature
+// fallback: a failed segment is retried at Temperature+TemperatureInc, … up
+// to 1.0. A segment fails when its token entropy is below EntropyThold (the
+// decoder is looping on the same tokens) or its average log probability is
+// below LogprobThold (the decoder is guessing).
+type fallbackParams struct {
+	Temperature    float32
+	TemperatureInc float32 // step between retries; 0 disables fallback
+	EntropyThold   float32
+	LogprobThold   float32
+}
+
+// applyFallback sets o's fallback thresholds on fb, which holds whisper.cpp's
+// defaults. Without RobustDecode the defaults are kept; with it only the
+// thresholds the preset sets (non-zero) replace them, and raising either
+// makes retries more eager, at some cost in speed.
+func (o DecodeOptions) applyFallback(fb *fallbackParams) {
+	if !o.RobustDecode {
+		return
+	}
+	if o.EntropyThreshold > 0 {
+		fb.EntropyThold = o.EntropyThreshold
+	}
+	if o.LogProbThreshold != 0 {
+		fb.LogprobThold = o.LogProbThreshold
+	}
+}
+
+// setFallback applies opts' fallback settings to params.
+func setFallback(params *C.struct_whisper_full_params, opts DecodeOptions) {
+	fb := fallbackParams{
+		Temperature:    float32(params.temperature),
+		TemperatureInc: float32(params.temperature_inc),
+		EntropyThold:   float32(params.entropy_thold),
+		LogprobThold:   float32(params.logprob_thold),
+	}
+	opts.applyFallback(&fb)
+	params.temperature = C.float(fb.Temperature)
+	params.temperature_inc = C.float(fb.TemperatureInc)
+	params.entropy_thold = C.float(fb.EntropyThold)
+	params.logprob_thold = C.float(fb.LogprobThold)
+}
+
+// fullFallback returns the fallback fields whisper_full runs with for opts.
+func fullFallback(opts DecodeOptions) fallbackParams {
+	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	setFallback(&params, opts)
+	return fallbackParams{
+		Temperature:    float32(params.temperature),
+		TemperatureInc: float32(params.temperature_inc),
+		EntropyThold:   float32(params.entropy_thold),
+		LogprobThold:   float32(params.logprob_thold),
+	}
+}
+
 // Transcribe runs speech-to-text on float32 PCM samples (16 kHz, mono).
-// lang: language code ("en", "ru", "auto"), translate: translate to English.
-func (w *WhisperEngine) Transcribe(samples []float32, lang string, translate bool) (string, error) {
+// lang: language code ("en", "ru", "auto").
+func (w *WhisperEngine) Transcribe(samples []float32, lang string, opts DecodeOptions) (string, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	return w.transcribe(samples, lang, opts)
+}
 
+// transcribe is Transcribe with w.mu held.
+func (w *WhisperEngine) transcribe(samples []float32, lang string, opts DecodeOptions) (string, error) {
 	if w.ctx == nil {
 		return "", fmt.Errorf("whisper engine not initialized")
 	}
@@ -115,24 +305,134 @@ func (w *WhisperEngine) Transcribe(samples []float32, lang string, translate boo
 		return "", nil
 	}
 
-	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	if err := w.full(samples, lang, opts, false); err != nil {
+		return "", err
+	}
+
+	nSegments := int(C.whisper_full_n_segments(w.ctx))
+	var b strings.Builder
+	for i := 0; i < nSegments; i++ {
+		b.WriteString(C.GoString(C.whisper_full_get_segment_text(w.ctx, C.int(i))))
+	}
+
+	return b.String(), nil
+}
+
+// TranscribeSegments is Transcribe with timing: it returns whisper's segments with
+// start/end times relative to the start of samples, plus per-word timings built
+// from token-level timestamps. Noise markers are stripped; empty segments dropped.
+func (w *WhisperEngine) TranscribeSegments(samples []float32, lang string, opts DecodeOptions) ([]Segment, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.transcribeSegments(samples, lang, opts)
+}
+
+// transcribeSegments is TranscribeSegments with w.mu held.
+func (w *WhisperEngine) transcribeSegments(samples []float32, lang string, opts DecodeOptions) ([]Segment, error) {
+	if w.ctx == nil {
+		return nil, fmt.Errorf("whisper engine not initialized")
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	if err := w.full(samples, lang, opts, true); err != nil {
+		return nil, err
+	}
+
+	eot := C.whisper_token_eot(w.ctx)
+	nSegments := int(C.whisper_full_n_segments(w.ctx))
+	segments := make([]Segment, 0, nSegments)
+	for i := 0; i < nSegments; i++ {
+		text := cleanWhisperOutput(C.GoString(C.whisper_full_get_segment_text(w.ctx, C.int(i))), opts.noiseMarkers())
+		if text == "" {
+			continue
+		}
+		seg := Segment{
+			Start: whisperTime(C.whisper_full_get_segment_t0(w.ctx, C.int(i))),
+			End:   whisperTime(C.whisper_full_get_segment_t1(w.ctx, C.int(i))),
+			Text:  text,
+		}
+
+		nTokens := int(C.whisper_full_n_tokens(w.ctx, C.int(i)))
+		tokens := make([]timedToken, 0, nTokens)
+		for j := 0; j < nTokens; j++ {
+			data := C.whisper_full_get_token_data(w.ctx, C.int(i), C.int(j))
+			if data.id >= eot { // <|endoftext|>, timestamps and other special tokens
+				continue
+			}
+			tokens = append(tokens, timedToken{
+				Text:  C.GoString(C.whisper_full_get_token_text(w.ctx, C.int(i), C.int(j))),
+				Start: whisperTime(data.t0),
+				End:   whisperTime(data.t1),
+			})
+		}
+		seg.Words = tokensToWords(tokens)
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// DetectedLanguage returns the language code whisper used for the last
+// transcription ("auto" resolved; for chunked audio, the last chunk's).
+func (w *WhisperEngine) DetectedLanguage() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx == nil {
+		return ""
+	}
+	return C.GoString(C.whisper_lang_str(C.whisper_full_lang_id(w.ctx)))
+}
+
+// SetThreads sets the number of CPU threads used by later transcriptions.
+// 0 = auto. Safe to call while a transcription is running.
+func (w *WhisperEngine) SetThreads(n int) {
+	w.threads.Store(int32(max(n, 0)))
+}
+
+// inferenceThreads resolves the thread setting: 0 = auto (all cores, at most 8),
+// otherwise clamped to [1, numCPU]. More threads isn't always faster — past the
+// number of physical cores whisper usually slows down.
+func inferenceThreads(requested, numCPU int) int {
+	numCPU = max(numCPU, 1)
+	if requested <= 0 {
+		return min(numCPU, 8)
+	}
+	return min(requested, numCPU)
+}
+
+// whisperTime converts whisper's 10ms timestamp units to a Duration.
+func whisperTime(t C.int64_t) time.Duration {
+	return time.Duration(t) * 10 * time.Millisecond
+}
+
+// full runs whisper_full on samples. Must be called with w.mu held.
+func (w *WhisperEngine) full(samples []float32, lang string, opts DecodeOptions, tokenTimestamps bool) error {
+	// Beam search is noticeably more accurate on hard audio but each extra
+	// beam adds decoding work; greedy stays the default for dictation latency.
+	var params C.struct_whisper_full_params
+	if sp := opts.sampling(); sp.BeamSearch {
+		params = C.whisper_full_default_params(C.WHISPER_SAMPLING_BEAM_SEARCH)
+		params.beam_search.beam_size = C.int(sp.BeamSize)
+	} else {
+		params = C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	}
 	params.print_progress = C.bool(false)
 	params.print_special = C.bool(false)
 	params.print_realtime = C.bool(false)
 	params.print_timestamps = C.bool(false)
 	params.single_segment = C.bool(false)
-	params.no_context = C.bool(true)
+	params.no_context = C.bool(opts.noContext())
+	params.token_timestamps = C.bool(tokenTimestamps)
 
-	nThreads := runtime.NumCPU()
-	if nThreads > 8 {
-		nThreads = 8
-	}
-	params.n_threads = C.int(nThreads)
+	params.n_threads = C.int(inferenceThreads(int(w.threads.Load()), runtime.NumCPU()))
 
-	if translate {
+	if opts.Translate {
 		params.translate = C.bool(true)
 	}
 
+	setFallback(&params, opts)
+
 	if lang != "" && lang != "auto" {
 		cLang := C.CString(lang)
 		defer C.free(unsafe.Pointer(cLang))
@@ -145,70 +445,262 @@ func (w *WhisperEngine) Transcribe(samples []float32, lang string, translate boo
 
 	ret := C.whisper_full(w.ctx, params, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples)))
 	if ret != 0 {
-		return "", fmt.Errorf("whisper_full failed with code %d", int(ret))
+		return fmt.Errorf("whisper_full failed with code %d", int(ret))
 	}
+	return nil
+}
 
-	nSegments := int(C.whisper_full_n_segments(w.ctx))
-	var b strings.Builder
-	for i := 0; i < nSegments; i++ {
-		b.WriteString(C.GoString(C.whisper_full_get_segment_text(w.ctx, C.int(i))))
+// Long recordings are transcribed in chunkSeconds windows; consecutive windows
+// overlap by chunkOverlapSeconds so a word cut at one window's edge is heard
+// whole in the other. The words repeated at each seam are removed again.
+const (
+	chunkSeconds        = 25
+	chunkOverlapSeconds = 2
+	chunkSamples        = chunkSeconds * sampleRate
+	chunkOverlapSamples = chunkOverlapSeconds * sampleRate
+)
+
+// maxSeamWords bounds how many words dedupeSeam compares at a seam; 2s of
+// speech is rarely more than 6-7 words.
+const maxSeamWords = 8
+
+// chunkBounds splits n samples into [start, end) windows of chunkSamples that
+// overlap by chunkOverlapSamples. Audio up to one window is a single chunk.
+func chunkBounds(n int) [][2]int {
+	if n <= chunkSamples {
+		return [][2]int{{0, n}}
+	}
+	var bounds [][2]int
+	for start := 0; ; start += chunkSamples - chunkOverlapSamples {
+		end := min(start+chunkSamples, n)
+		bounds = append(bounds, [2]int{start, end})
+		if end == n {
+			return bounds
+		}
 	}
+}
 
-	return b.String(), nil
+// dedupeSeam drops the words at the start of next that repeat the end of prev,
+// as transcribed twice from the overlap of two chunks. The longest match of up
+// to maxSeamWords wins; case and punctuation are ignored ("world." = "World").
+func dedupeSeam(prev, next string) string {
+	pw, nw := strings.Fields(prev), strings.Fields(next)
+	for k := min(len(pw), len(nw), maxSeamWords); k > 0; k-- {
+		if seamWordsEqual(pw[len(pw)-k:], nw[:k]) {
+			return strings.Join(nw[k:], " ")
+		}
+	}
+	return next
+}
+
+func seamWordsEqual(a, b []string) bool {
+	for i := range a {
+		if seamWord(a[i]) != seamWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func seamWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
 }
 
-const chunkSeconds = 25
-const chunkSamples = chunkSeconds * 16000
+// seamWindow returns the range of segment start times chunk n of bounds
+// contributes: from the middle of the overlap with the previous chunk to the
+// middle of the overlap with the next one.
+func seamWindow(bounds [][2]int, n int) (from, to time.Duration) {
+	const halfOverlap = chunkOverlapSeconds * time.Second / 2
+	to = time.Duration(math.MaxInt64)
+	if n > 0 {
+		from = time.Duration(bounds[n][0])*time.Second/sampleRate + halfOverlap
+	}
+	if n < len(bounds)-1 {
+		to = time.Duration(bounds[n+1][0])*time.Second/sampleRate + halfOverlap
+	}
+	return from, to
+}
 
-// TranscribeLong splits long audio into chunks for reliable transcription.
+// TranscribeLong splits long audio into overlapping chunks for reliable
+// transcription (see chunkBounds, dedupeSeam).
 // onProgress is called after each chunk with (current, total) chunk indices (1-based).
-func (w *WhisperEngine) TranscribeLong(samples []float32, lang string, translate bool, onProgress func(current, total int)) (string, error) {
-	totalChunks := (len(samples) + chunkSamples - 1) / chunkSamples
-	if totalChunks <= 1 {
+// onPartial, if set, gets the text so far after each chunk of audio longer
+// than one chunk, for live display.
+// The leading space whisper emits before the first word is kept; callers apply
+// normalizeSpacing to decide whether it survives. Noise markers are dropped,
+// unless they are all there is: then they are returned as-is so callers can
+// tell a noise-only recording from silence (see isNoiseOnly).
+// The engine stays locked for all chunks, so with opts.UseContext no other
+// transcription can slip in between two chunks and replace their context.
+func (w *WhisperEngine) TranscribeLong(samples []float32, lang string, opts DecodeOptions, onProgress func(current, total int), onPartial func(current, total int, text string)) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := 0
+	return transcribeChunks(samples, func(chunk []float32) (string, error) {
+		o := opts.forChunk(n)
+		n++
+		return w.transcribe(chunk, lang, o)
+	}, opts.noiseMarkers(), onProgress, onPartial)
+}
+
+// transcribeChunks is the engine-independent part of TranscribeLong; markers
+// are the noise markers to drop.
+func transcribeChunks(samples []float32, transcribe func([]float32) (string, error), markers *regexp.Regexp, onProgress func(current, total int), onPartial func(current, total int, text string)) (string, error) {
+	bounds := chunkBounds(len(samples))
+	if len(bounds) == 1 {
 		if onProgress != nil {
 			onProgress(1, 1)
 		}
-		text, err := w.Transcribe(samples, lang, translate)
+		text, err := transcribe(samples)
 		if err != nil {
 			return "", err
 		}
-		return cleanWhisperOutput(text), nil
+		if cleaned := cleanWhisperOutput(text, markers); cleaned != "" {
+			return withLeadingSpace(text, cleaned), nil
+		}
+		return strings.TrimSpace(text), nil // only markers (or nothing) left
 	}
 
 	var parts []string
-	chunk := 0
-	for i := 0; i < len(samples); i += chunkSamples {
-		chunk++
-		end := i + chunkSamples
-		if end > len(samples) {
-			end = len(samples)
-		}
+	var noiseChunks []string // chunks that were only noise markers
+	firstRaw := ""           // raw text of the first non-empty chunk, for its leading space
+	prev := ""               // text of the preceding chunk, for seam dedupe
+	for n, b := range bounds {
 		if onProgress != nil {
-			onProgress(chunk, totalChunks)
+			onProgress(n+1, len(bounds))
 		}
-		text, err := w.Transcribe(samples[i:end], lang, translate)
+		text, err := transcribe(samples[b[0]:b[1]])
 		if err != nil {
+			prev = ""
 			continue
 		}
-		text = cleanWhisperOutput(text)
-		if text != "" {
-			parts = append(parts, text)
+		cleaned := cleanWhisperOutput(text, markers)
+		if cleaned != "" {
+			if len(parts) == 0 {
+				firstRaw = text
+			}
+			if deduped := dedupeSeam(prev, cleaned); deduped != "" {
+				parts = append(parts, deduped)
+			}
+		} else if raw := strings.TrimSpace(text); raw != "" {
+			noiseChunks = append(noiseChunks, raw)
+		}
+		prev = cleaned
+		if onPartial != nil {
+			onPartial(n+1, len(bounds), strings.Join(parts, " "))
 		}
 	}
-	return strings.Join(parts, " "), nil
+	if len(parts) == 0 {
+		return strings.Join(noiseChunks, " "), nil
+	}
+	return withLeadingSpace(firstRaw, strings.Join(parts, " ")), nil
+}
+
+// TranscribeSegmentsLong is TranscribeLong with timing: each chunk is transcribed
+// with TranscribeSegments and its timestamps shifted by the chunk's offset, so
+// segment times are relative to the start of the whole recording. In the
+// overlap between two chunks, segments starting before its midpoint are taken
+// from the earlier chunk and the rest from the later one. A failed chunk fails
+// the whole call: subtitles with a silent gap would look complete.
+func (w *WhisperEngine) TranscribeSegmentsLong(samples []float32, lang string, opts DecodeOptions, onProgress func(current, total int)) ([]Segment, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return transcribeSegmentChunks(samples, func(chunk []float32, n int) ([]Segment, error) {
+		return w.transcribeSegments(chunk, lang, opts.forChunk(n))
+	}, onProgress)
+}
+
+// transcribeSegmentChunks is the engine-independent part of
+// TranscribeSegmentsLong; transcribe gets each chunk and its 0-based index.
+func transcribeSegmentChunks(samples []float32, transcribe func(chunk []float32, n int) ([]Segment, error), onProgress func(current, total int)) ([]Segment, error) {
+	bounds := chunkBounds(len(samples))
+	if len(bounds) == 1 {
+		if onProgress != nil {
+			onProgress(1, 1)
+		}
+		return transcribe(samples, 0)
+	}
+
+	var segments []Segment
+	for n, b := range bounds {
+		if onProgress != nil {
+			onProgress(n+1, len(bounds))
+		}
+		segs, err := transcribe(samples[b[0]:b[1]], n)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", n+1, len(bounds), err)
+		}
+		offset := time.Duration(b[0]) * time.Second / sampleRate
+		from, to := seamWindow(bounds, n)
+		for _, seg := range offsetSegments(segs, offset) {
+			if seg.Start >= from && seg.Start < to {
+				segments = append(segments, seg)
+			}
+		}
+	}
+	return segments, nil
 }
 
 // Whisper outputs noise markers as [MUSIC], [музыка], [音楽], etc.
 // In a push-to-talk tool, bracketed markers are never real speech — strip them all.
 var whisperNoiseRe = regexp.MustCompile(`\[[^\[\]]+\]|\((?i:music|noise|silence|blank.?audio|laughter|applause)\)`)
 
-// cleanWhisperOutput removes whisper noise markers but keeps all real text.
-func cleanWhisperOutput(text string) string {
-	text = whisperNoiseRe.ReplaceAllString(text, "")
+// knownNoiseRe matches only the noise markers whisper is known to emit, in
+// square brackets or parentheses. It replaces whisperNoiseRe when the
+// stripNoiseMarkers setting is off, so dictated "[TODO]" or code survives.
+var knownNoiseRe = regexp.MustCompile(`[\[(]\s*(?i:music|noise|background noise|silence|blank.?audio|laughter|laughs|applause|coughing|coughs|sighs|inaudible|музыка|тишина|смех|аплодисменты|音楽)\s*[\])]`)
+
+// noiseMarkers returns the noise-marker pattern for o: every [...] (default)
+// or, with KeepUnknownMarkers, only known markers.
+func (o DecodeOptions) noiseMarkers() *regexp.Regexp {
+	if o.KeepUnknownMarkers {
+		return knownNoiseRe
+	}
+	return whisperNoiseRe
+}
+
+// cleanWhisperOutput removes whisper noise markers (see noiseMarkers) but
+// keeps all real text.
+func cleanWhisperOutput(text string, markers *regexp.Regexp) string {
+	text = stripNoiseMarkers(text, markers)
 	text = strings.TrimSpace(text)
 	return text
 }
 
+// whisperSilenceRe matches the markers whisper emits for silence rather than noise.
+var whisperSilenceRe = regexp.MustCompile(`(?i)blank.?audio|silence|тишина`)
+
+// isNoiseOnly reports whether text consists solely of noise markers, at least
+// one of which is actual noise ([MUSIC], [coughing]) rather than silence
+// ([BLANK_AUDIO]).
+func isNoiseOnly(text string, markers *regexp.Regexp) bool {
+	if cleanWhisperOutput(text, markers) != "" {
+		return false
+	}
+	for _, m := range markers.FindAllString(text, -1) {
+		if !whisperSilenceRe.MatchString(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripNoiseMarkers removes noise markers without trimming surrounding whitespace.
+func stripNoiseMarkers(text string, markers *regexp.Regexp) string {
+	return markers.ReplaceAllString(text, "")
+}
+
+// withLeadingSpace restores the single leading space of raw whisper output on
+// cleaned text (which cleanWhisperOutput trims).
+func withLeadingSpace(raw, cleaned string) string {
+	if cleaned != "" && strings.HasPrefix(raw, " ") {
+		return " " + cleaned
+	}
+	return cleaned
+}
+
 // Close frees the whisper context.
 func (w *WhisperEngine) Close() {
 	w.mu.Lock()
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UberMorgott/transcribation/internal/config"
+)
+
+func TestLoadErrorNotOOM(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ggml-tiny.bin")
+	if err := os.WriteFile(path, []byte("<html>not found</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{path, filepath.Join(dir, "missing.bin")} {
+		_, err := NewWhisperEngine(p, "cpu", 0)
+		if err == nil || isOOMError(err) {
+			t.Errorf("NewWhisperEngine(%s) error = %v, want a load error that is not OOM", filepath.Base(p), err)
+		}
+	}
+	if !isOOMError(fmt.Errorf("%w: model.bin", errModelInit)) {
+		t.Error("isOOMError(errModelInit) = false, want true")
+	}
+}
+
+func TestChunkBounds(t *testing.T) {
+	step := chunkSamples - chunkOverlapSamples
+	tests := []struct {
+		n    int
+		want [][2]int
+	}{
+		{sampleRate, [][2]int{{0, sampleRate}}},
+		{chunkSamples, [][2]int{{0, chunkSamples}}},
+		{chunkSamples + 1, [][2]int{{0, chunkSamples}, {step, chunkSamples + 1}}},
+		{60 * sampleRate, [][2]int{{0, chunkSamples}, {step, step + chunkSamples}, {2 * step, 60 * sampleRate}}},
+	}
+	for _, tt := range tests {
+		if got := chunkBounds(tt.n); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("chunkBounds(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeSeam(t *testing.T) {
+	tests := []struct {
+		prev, next, want string
+	}{
+		{"", "hello there", "hello there"},
+		{"we went to the", "to the market", "market"},
+		{"Hello, world.", "World, again", "again"},
+		{"one two three", "four five", "four five"},
+		{"say it again", "say it again", ""},
+	}
+	for _, tt := range tests {
+		if got := dedupeSeam(tt.prev, tt.next); got != tt.want {
+			t.Errorf("dedupeSeam(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+// Synthetic speech: every second of audio is one word, "w<second>". A chunk
+// "hears" the words of the seconds it covers, so overlapping chunks repeat the
+// seam words, which must come out exactly once.
+func TestTranscribeChunksSeams(t *testing.T) {
+	const seconds = 70
+	samples := make([]float32, seconds*sampleRate)
+	for i := range samples {
+		samples[i] = float32(i / sampleRate)
+	}
+	transcribe := func(chunk []float32) (string, error) {
+		var words []string
+		for i := 0; i < len(chunk); i += sampleRate {
+			words = append(words, fmt.Sprintf("w%d", int(chunk[i])))
+		}
+		return " " + strings.Join(words, " "), nil
+	}
+
+	calls := 0
+	var partials []string
+	got, err := transcribeChunks(samples, transcribe, whisperNoiseRe, func(current, total int) { calls++ }, func(current, total int, text string) {
+		if current != len(partials)+1 {
+			t.Errorf("partial for chunk %d/%d, want chunk %d", current, total, len(partials)+1)
+		}
+		partials = append(partials, text)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]string, seconds)
+	for i := range want {
+		want[i] = fmt.Sprintf("w%d", i)
+	}
+	if got != " "+strings.Join(want, " ") {
+		t.Errorf("transcribeChunks = %q,\nwant %q", got, " "+strings.Join(want, " "))
+	}
+	if n := len(chunkBounds(len(samples))); calls != n || len(partials) != n {
+		t.Fatalf("onProgress called %d times, onPartial %d times, want %d", calls, len(partials), n)
+	}
+	// Each partial extends the previous one; the last is the whole text.
+	for i := 1; i < len(partials); i++ {
+		if !strings.HasPrefix(partials[i], partials[i-1]+" ") {
+			t.Errorf("partial %d = %q does not extend %q", i+1, partials[i], partials[i-1])
+		}
+	}
+	if last := partials[len(partials)-1]; " "+last != got {
+		t.Errorf("last partial = %q, want the final text %q", last, got)
+	}
+}
+
+// The leading space whisper puts before the first word must survive chunking
+// so that PreserveLeadingSpace has something to preserve.
+func TestTranscribeChunksLeadingSpace(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples int
+		chunks  []string
+		want    string
+	}{
+		{"single chunk", sampleRate, []string{" Hello"}, " Hello"},
+		{"single chunk, no space", sampleRate, []string{"Hello"}, "Hello"},
+		{"single chunk, noise before", sampleRate, []string{" [MUSIC] Hello"}, " Hello"},
+		{"first chunk only noise", chunkSamples + sampleRate, []string{"[MUSIC]", " Hello"}, " Hello"},
+		{"two chunks", chunkSamples + sampleRate, []string{" Hello", " world"}, " Hello world"},
+	}
+	for _, tt := range tests {
+		n := 0
+		transcribe := func([]float32) (string, error) {
+			n++
+			return tt.chunks[n-1], nil
+		}
+		got, err := transcribeChunks(make([]float32, tt.samples), transcribe, whisperNoiseRe, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: transcribeChunks = %q, want %q", tt.name, got, tt.want)
+		}
+		if kept := normalizeSpacing(got, true); kept != tt.want {
+			t.Errorf("%s: normalizeSpacing(preserve) = %q, want %q", tt.name, kept, tt.want)
+		}
+		if trimmed := normalizeSpacing(got, false); trimmed != strings.TrimSpace(tt.want) {
+			t.Errorf("%s: normalizeSpacing = %q, want %q", tt.name, trimmed, strings.TrimSpace(tt.want))
+		}
+	}
+}
+
+func TestSeamWindow(t *testing.T) {
+	bounds := chunkBounds(60 * sampleRate)
+	// Every instant is covered by exactly one chunk's window.
+	for at := time.Duration(0); at < 60*time.Second; at += 250 * time.Millisecond {
+		owners := 0
+		for n := range bounds {
+			if from, to := seamWindow(bounds, n); at >= from && at < to {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Errorf("segment starting at %v belongs to %d chunks, want 1", at, owners)
+		}
+	}
+}
+
+func TestNoiseMarkerPolicy(t *testing.T) {
+	tests := []struct {
+		text     string
+		stripAll string // default: every [...] goes
+		known    string // stripNoiseMarkers off: only known markers go
+	}{
+		{"[MUSIC] Hello", " Hello", " Hello"},
+		{"Hello (laughter) there", "Hello  there", "Hello  there"},
+		{"[ Музыка ] Привет", " Привет", " Привет"},
+		{"[BLANK_AUDIO]", "", ""},
+		{"[TODO] fix this", " fix this", "[TODO] fix this"},
+		{"use arr[i] here", "use arr here", "use arr[i] here"},
+		{"(see above)", "(see above)", "(see above)"},
+	}
+	for _, tt := range tests {
+		if got := whisperNoiseRe.ReplaceAllString(tt.text, ""); got != tt.stripAll {
+			t.Errorf("strip all %q = %q, want %q", tt.text, got, tt.stripAll)
+		}
+		if got := knownNoiseRe.ReplaceAllString(tt.text, ""); got != tt.known {
+			t.Errorf("strip known %q = %q, want %q", tt.text, got, tt.known)
+		}
+	}
+
+	useTempConfig(t)
+
+	if got := stripNoiseMarkers("[TODO] fix", presetDecodeOptions(config.Preset{}).noiseMarkers()); got != " fix" {
+		t.Errorf("default stripNoiseMarkers = %q, want every bracket stripped", got)
+	}
+	off := false
+	if err := config.Save(&config.AppConfig{StripNoiseMarkers: &off, Presets: []config.Preset{}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, preset := range []config.Preset{{}, {LowLatency: true}} {
+		if got := stripNoiseMarkers("[TODO] fix [MUSIC]", presetDecodeOptions(preset).noiseMarkers()); got != "[TODO] fix " {
+			t.Errorf("stripNoiseMarkers with the setting off = %q, want [TODO] kept", got)
+		}
+	}
+}
+
+func TestTranscribeSegmentChunksError(t *testing.T) {
+	samples := make([]float32, 70*sampleRate)
+	n := len(chunkBounds(len(samples)))
+	var got []int
+	transcribe := func(chunk []float32, i int) ([]Segment, error) {
+		got = append(got, i)
+		if i == 1 {
+			return nil, errors.New("boom")
+		}
+		return []Segment{{Start: 0, End: time.Second, Text: "x"}}, nil
+	}
+	segs, err := transcribeSegmentChunks(samples, transcribe, nil)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("chunk 2/%d", n)) || segs != nil {
+		t.Errorf("failed chunk: segments %v, err %v; want a chunk 2/%d error", segs, err, n)
+	}
+	if fmt.Sprint(got) != "[0 1]" {
+		t.Errorf("chunks transcribed = %v, want to stop at the failed one", got)
+	}
+
+	segs, err = transcribeSegmentChunks(samples, func(chunk []float32, i int) ([]Segment, error) {
+		return []Segment{{Start: 5 * time.Second, End: 6 * time.Second, Text: "x"}}, nil // clear of the seams
+	}, nil)
+	if err != nil || len(segs) != n {
+		t.Errorf("all chunks ok: %d segments, %v; want %d", len(segs), err, n)
+	}
+}